@@ -0,0 +1,33 @@
+package browser
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+)
+
+// Open opens the URL in the user's browser. $BROWSER takes precedence over
+// the platform's default opener.
+func Open(ctx context.Context, url string) error {
+	name := os.Getenv("BROWSER")
+	if name == "" {
+		switch runtime.GOOS {
+		case "darwin":
+			name = "open"
+		case "windows":
+			name = "explorer"
+		default:
+			name = "xdg-open"
+		}
+	}
+
+	// #nosec G204
+	cmd := exec.CommandContext(ctx, name, url)
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("error running %q: %w", cmd.String(), err)
+	}
+
+	return nil
+}
@@ -0,0 +1,34 @@
+package cache
+
+import (
+	"github.com/MakeNowJust/heredoc/v2"
+	"github.com/spf13/cobra"
+
+	clearCmd "github.com/zkhvan/z/pkg/cmd/cache/clear"
+	"github.com/zkhvan/z/pkg/cmd/cache/internal"
+	listCmd "github.com/zkhvan/z/pkg/cmd/cache/list"
+	showCmd "github.com/zkhvan/z/pkg/cmd/cache/show"
+	statsCmd "github.com/zkhvan/z/pkg/cmd/cache/stats"
+	"github.com/zkhvan/z/pkg/cmdutil"
+)
+
+func NewCmdCache(f *cmdutil.Factory) *cobra.Command {
+	cacheOpts := &internal.CacheOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "cache",
+		Short: "Inspect and clear the cache",
+	}
+
+	cmd.PersistentFlags().StringVar(&cacheOpts.CacheDir, "cache-dir", "", heredoc.Doc(`
+		The cache directory. By default, the cache is saved in $Z_CACHE_DIR,
+		$XDG_CACHE_HOME/z or ~/.cache/z/
+	`))
+
+	cmd.AddCommand(listCmd.NewCmdList(f, cacheOpts))
+	cmd.AddCommand(showCmd.NewCmdShow(f, cacheOpts))
+	cmd.AddCommand(clearCmd.NewCmdClear(f, cacheOpts))
+	cmd.AddCommand(statsCmd.NewCmdStats(f, cacheOpts))
+
+	return cmd
+}
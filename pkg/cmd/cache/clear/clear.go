@@ -0,0 +1,63 @@
+package clear
+
+import (
+	"fmt"
+
+	"github.com/MakeNowJust/heredoc/v2"
+	"github.com/spf13/cobra"
+
+	"github.com/zkhvan/z/pkg/cmd/cache/internal"
+	"github.com/zkhvan/z/pkg/cmdutil"
+	"github.com/zkhvan/z/pkg/fcache"
+	"github.com/zkhvan/z/pkg/iolib"
+)
+
+type Options struct {
+	*internal.CacheOptions
+	io *iolib.IOStreams
+
+	Key string
+}
+
+func NewCmdClear(f *cmdutil.Factory, cacheOpts *internal.CacheOptions) *cobra.Command {
+	opts := &Options{
+		CacheOptions: cacheOpts,
+		io:           f.IOStreams,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "clear [<key>]",
+		Short: "Clear the cache",
+		Long: heredoc.Doc(`
+			Remove the cache entry of the key, or every entry of a namespace,
+			e.g. "projects.remote". The whole cache is cleared if no key is
+			given.
+
+			The cleared entries are rebuilt when they're needed again.
+		`),
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			if len(args) > 0 {
+				opts.Key = args[0]
+			}
+			return opts.Run()
+		},
+	}
+
+	return cmd
+}
+
+func (opts *Options) Run() error {
+	removed, err := fcache.Clear(opts.Dir(), opts.Key)
+	if err != nil {
+		return fmt.Errorf("error clearing the cache: %w", err)
+	}
+
+	if removed == 0 && opts.Key != "" {
+		return fmt.Errorf("no cache entry %q", opts.Key)
+	}
+
+	fmt.Fprintf(opts.io.ErrOut, "Removed %d cache entries\n", removed)
+
+	return nil
+}
@@ -0,0 +1,46 @@
+package internal
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/zkhvan/z/pkg/fcache"
+)
+
+type CacheOptions struct {
+	CacheDir string
+}
+
+// Dir returns the cache directory.
+func (o *CacheOptions) Dir() string {
+	return fcache.NormalizeCacheDir(o.CacheDir)
+}
+
+// FormatSize formats a size in bytes, e.g. "1.2 KiB".
+func FormatSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
+
+// FormatExpiry formats the expiry of an entry relative to now, e.g. "in 2h" or
+// "2h ago".
+func FormatExpiry(e fcache.Entry) string {
+	switch {
+	case e.Expiry.IsZero():
+		return "never"
+	case e.Expired():
+		return fmt.Sprintf("%s ago", time.Since(e.Expiry).Round(time.Second))
+	default:
+		return fmt.Sprintf("in %s", time.Until(e.Expiry).Round(time.Second))
+	}
+}
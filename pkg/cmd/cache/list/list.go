@@ -0,0 +1,56 @@
+package list
+
+import (
+	"fmt"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/zkhvan/z/pkg/cmd/cache/internal"
+	"github.com/zkhvan/z/pkg/cmdutil"
+	"github.com/zkhvan/z/pkg/fcache"
+	"github.com/zkhvan/z/pkg/iolib"
+)
+
+type Options struct {
+	*internal.CacheOptions
+	io *iolib.IOStreams
+}
+
+func NewCmdList(f *cmdutil.Factory, cacheOpts *internal.CacheOptions) *cobra.Command {
+	opts := &Options{
+		CacheOptions: cacheOpts,
+		io:           f.IOStreams,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the cache entries",
+		RunE: func(_ *cobra.Command, _ []string) error {
+			return opts.Run()
+		},
+	}
+
+	return cmd
+}
+
+func (opts *Options) Run() error {
+	entries, err := fcache.List(opts.Dir())
+	if err != nil {
+		return fmt.Errorf("error listing the cache: %w", err)
+	}
+
+	w := tabwriter.NewWriter(opts.io.Out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "KEY\tEXPIRES\tSIZE\tENTRIES")
+	for _, e := range entries {
+		count := "-"
+		if e.Count >= 0 {
+			count = strconv.Itoa(e.Count)
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", e.Key, internal.FormatExpiry(e), internal.FormatSize(e.Size), count)
+	}
+
+	return w.Flush()
+}
@@ -0,0 +1,83 @@
+package show
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/MakeNowJust/heredoc/v2"
+	"github.com/spf13/cobra"
+
+	"github.com/zkhvan/z/pkg/cmd/cache/internal"
+	"github.com/zkhvan/z/pkg/cmdutil"
+	"github.com/zkhvan/z/pkg/fcache"
+	"github.com/zkhvan/z/pkg/iolib"
+)
+
+type Options struct {
+	*internal.CacheOptions
+	io *iolib.IOStreams
+
+	Key  string
+	JSON bool
+}
+
+func NewCmdShow(f *cmdutil.Factory, cacheOpts *internal.CacheOptions) *cobra.Command {
+	opts := &Options{
+		CacheOptions: cacheOpts,
+		io:           f.IOStreams,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "show <key>",
+		Short: "Show a cache entry",
+		Long: heredoc.Doc(`
+			Show the details and the contents of a cache entry. The keys are
+			listed by "z cache list".
+
+			Use --json to only output the contents.
+		`),
+		Args: cobra.ExactArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			opts.Key = args[0]
+			return opts.Run()
+		},
+	}
+
+	cmd.Flags().BoolVar(&opts.JSON, "json", false, "Only output the contents, as JSON")
+
+	return cmd
+}
+
+func (opts *Options) Run() error {
+	entry, value, err := fcache.Read(opts.Dir(), opts.Key)
+	if errors.Is(err, fcache.ErrNotFound) {
+		return fmt.Errorf("no cache entry %q", opts.Key)
+	}
+	if err != nil {
+		return fmt.Errorf("error reading the cache: %w", err)
+	}
+
+	var out bytes.Buffer
+	if err := json.Indent(&out, bytes.TrimSpace(value), "", "  "); err != nil {
+		return fmt.Errorf("error decoding %q: %w", opts.Key, err)
+	}
+
+	if !opts.JSON {
+		fmt.Fprintf(opts.io.Out, "Key: %s\n", entry.Key)
+		fmt.Fprintf(opts.io.Out, "File: %s\n", entry.Path)
+		fmt.Fprintf(opts.io.Out, "Saved: %s\n", entry.ModTime.Local().Format(time.DateTime))
+		fmt.Fprintf(opts.io.Out, "Expires: %s\n", internal.FormatExpiry(entry))
+		fmt.Fprintf(opts.io.Out, "Size: %s\n", internal.FormatSize(entry.Size))
+		if entry.Count >= 0 {
+			fmt.Fprintf(opts.io.Out, "Entries: %d\n", entry.Count)
+		}
+		fmt.Fprintln(opts.io.Out)
+	}
+
+	fmt.Fprintln(opts.io.Out, out.String())
+
+	return nil
+}
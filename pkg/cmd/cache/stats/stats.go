@@ -0,0 +1,82 @@
+package stats
+
+import (
+	"fmt"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/zkhvan/z/pkg/cmd/cache/internal"
+	"github.com/zkhvan/z/pkg/cmdutil"
+	"github.com/zkhvan/z/pkg/fcache"
+	"github.com/zkhvan/z/pkg/iolib"
+)
+
+type Options struct {
+	*internal.CacheOptions
+	io *iolib.IOStreams
+}
+
+func NewCmdStats(f *cmdutil.Factory, cacheOpts *internal.CacheOptions) *cobra.Command {
+	opts := &Options{
+		CacheOptions: cacheOpts,
+		io:           f.IOStreams,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "stats",
+		Short: "Show statistics of the cache",
+		RunE: func(_ *cobra.Command, _ []string) error {
+			return opts.Run()
+		},
+	}
+
+	return cmd
+}
+
+type stats struct {
+	namespace string
+	entries   int
+	expired   int
+	size      int64
+}
+
+func (s *stats) add(e fcache.Entry) {
+	s.entries++
+	s.size += e.Size
+	if e.Expired() {
+		s.expired++
+	}
+}
+
+func (opts *Options) Run() error {
+	entries, err := fcache.List(opts.Dir())
+	if err != nil {
+		return fmt.Errorf("error listing the cache: %w", err)
+	}
+
+	total := stats{namespace: "total"}
+	var namespaces []*stats
+	byName := make(map[string]*stats)
+	for _, e := range entries {
+		ns, ok := byName[e.Namespace()]
+		if !ok {
+			ns = &stats{namespace: e.Namespace()}
+			byName[ns.namespace] = ns
+			namespaces = append(namespaces, ns)
+		}
+
+		ns.add(e)
+		total.add(e)
+	}
+
+	fmt.Fprintf(opts.io.Out, "Directory: %s\n\n", opts.Dir())
+
+	w := tabwriter.NewWriter(opts.io.Out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAMESPACE\tENTRIES\tEXPIRED\tSIZE")
+	for _, s := range append(namespaces, &total) {
+		fmt.Fprintf(w, "%s\t%d\t%d\t%s\n", s.namespace, s.entries, s.expired, internal.FormatSize(s.size))
+	}
+
+	return w.Flush()
+}
@@ -0,0 +1,58 @@
+package add
+
+import (
+	"github.com/MakeNowJust/heredoc/v2"
+	"github.com/spf13/cobra"
+
+	"github.com/zkhvan/z/pkg/cmd/config/internal"
+	"github.com/zkhvan/z/pkg/cmdutil"
+	"github.com/zkhvan/z/pkg/config"
+)
+
+type Options struct {
+	ConfigDir string
+
+	Key    string
+	Values []string
+	Force  bool
+}
+
+func NewCmdAdd(_ *cmdutil.Factory) *cobra.Command {
+	opts := &Options{}
+
+	cmd := &cobra.Command{
+		Use:   "add <key> <value>...",
+		Short: "Add values to a config list",
+		Long: heredoc.Doc(`
+			Add values to the list of a config key, which is created if missing.
+			Values already in the list are skipped.
+
+			For example:
+
+			  z config add projects.remote_patterns "my-org/*" "cli/cli -> ./oss/*"
+		`),
+		Args: cobra.MinimumNArgs(2),
+		RunE: func(_ *cobra.Command, args []string) error {
+			opts.Key, opts.Values = args[0], args[1:]
+			return opts.Run()
+		},
+	}
+
+	cmd.Flags().BoolVar(&opts.Force, "force", false, "Rewrite a TOML file even though its comments are lost")
+
+	return cmd
+}
+
+func (opts *Options) Run() error {
+	f, err := config.OpenFile(opts.ConfigDir)
+	if err != nil {
+		return err
+	}
+	f.SetForce(opts.Force)
+
+	if err := f.Add(opts.Key, opts.Values...); err != nil {
+		return err
+	}
+
+	return internal.Save(f)
+}
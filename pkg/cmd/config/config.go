@@ -3,7 +3,17 @@ package config
 import (
 	"github.com/spf13/cobra"
 
-	getCmd "github.com/zkhvan/z/pkg/cmd/config/list"
+	addCmd "github.com/zkhvan/z/pkg/cmd/config/add"
+	editCmd "github.com/zkhvan/z/pkg/cmd/config/edit"
+	getCmd "github.com/zkhvan/z/pkg/cmd/config/get"
+	initCmd "github.com/zkhvan/z/pkg/cmd/config/init"
+	listCmd "github.com/zkhvan/z/pkg/cmd/config/list"
+	migrateCmd "github.com/zkhvan/z/pkg/cmd/config/migrate"
+	removeCmd "github.com/zkhvan/z/pkg/cmd/config/remove"
+	schemaCmd "github.com/zkhvan/z/pkg/cmd/config/schema"
+	setCmd "github.com/zkhvan/z/pkg/cmd/config/set"
+	unsetCmd "github.com/zkhvan/z/pkg/cmd/config/unset"
+	validateCmd "github.com/zkhvan/z/pkg/cmd/config/validate"
 	"github.com/zkhvan/z/pkg/cmdutil"
 )
 
@@ -13,7 +23,17 @@ func NewCmdConfig(f *cmdutil.Factory) *cobra.Command {
 		Short: "Manage config",
 	}
 
-	cmd.AddCommand(getCmd.NewCmdList(f))
+	cmd.AddCommand(initCmd.NewCmdInit(f))
+	cmd.AddCommand(listCmd.NewCmdList(f))
+	cmd.AddCommand(getCmd.NewCmdGet(f))
+	cmd.AddCommand(setCmd.NewCmdSet(f))
+	cmd.AddCommand(unsetCmd.NewCmdUnset(f))
+	cmd.AddCommand(addCmd.NewCmdAdd(f))
+	cmd.AddCommand(removeCmd.NewCmdRemove(f))
+	cmd.AddCommand(editCmd.NewCmdEdit(f))
+	cmd.AddCommand(validateCmd.NewCmdValidate(f))
+	cmd.AddCommand(schemaCmd.NewCmdSchema(f))
+	cmd.AddCommand(migrateCmd.NewCmdMigrate(f))
 
 	return cmd
 }
@@ -0,0 +1,143 @@
+package edit
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/MakeNowJust/heredoc/v2"
+	"github.com/spf13/cobra"
+
+	"github.com/zkhvan/z/pkg/cmd/config/internal"
+	"github.com/zkhvan/z/pkg/cmdutil"
+	"github.com/zkhvan/z/pkg/config"
+	"github.com/zkhvan/z/pkg/iolib"
+	"github.com/zkhvan/z/pkg/prompt"
+)
+
+type Options struct {
+	io *iolib.IOStreams
+
+	ConfigDir string
+}
+
+func NewCmdEdit(f *cmdutil.Factory) *cobra.Command {
+	opts := &Options{
+		io: f.IOStreams,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "edit",
+		Short: "Edit the config file",
+		Long: heredoc.Doc(`
+			Open the config file in $VISUAL or $EDITOR, defaulting to vi.
+
+			The config file is only saved if the result is valid. Otherwise, you
+			can edit it again, or the rejected file is kept aside.
+		`),
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return opts.Run(cmd.Context())
+		},
+	}
+
+	return cmd
+}
+
+func (opts *Options) Run(ctx context.Context) error {
+	f, err := config.OpenFile(opts.ConfigDir)
+	if err != nil {
+		return err
+	}
+
+	original, err := f.Bytes()
+	if err != nil {
+		return err
+	}
+
+	// The file is edited in a copy, so an invalid result never replaces it.
+	tmp, err := os.CreateTemp("", "z-config-*"+filepath.Ext(f.Path()))
+	if err != nil {
+		return err
+	}
+	tmp.Close()
+
+	keep := false
+	defer func() {
+		if !keep {
+			os.Remove(tmp.Name())
+		}
+	}()
+
+	if err := os.WriteFile(tmp.Name(), original, 0o600); err != nil {
+		return err
+	}
+
+	for {
+		if err := opts.runEditor(ctx, tmp.Name()); err != nil {
+			return err
+		}
+
+		edited, err := os.ReadFile(tmp.Name())
+		if err != nil {
+			return err
+		}
+
+		if bytes.Equal(edited, original) {
+			fmt.Fprintln(opts.io.ErrOut, "No changes")
+			return nil
+		}
+
+		err = f.SetBytes(edited)
+		if err == nil {
+			err = internal.Validate(f)
+		}
+		if err == nil {
+			break
+		}
+
+		fmt.Fprintf(opts.io.ErrOut, "error: %v\n", err)
+
+		again := false
+		if opts.io.CanPrompt() {
+			again, err = prompt.New(opts.io).Confirm("Edit again?", true)
+			if err != nil && !errors.Is(err, prompt.ErrNoInput) {
+				return err
+			}
+		}
+
+		if !again {
+			keep = true
+			return fmt.Errorf("the config wasn't saved, the edited file is kept at %s", tmp.Name())
+		}
+	}
+
+	return internal.Save(f)
+}
+
+func (opts *Options) runEditor(ctx context.Context, path string) error {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+
+	// The editor can have arguments, e.g. "code --wait".
+	args := append(strings.Fields(editor), path)
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("error running command %q: %w", editor, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,57 @@
+package get
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"go.yaml.in/yaml/v3"
+
+	"github.com/zkhvan/z/pkg/cmdutil"
+	"github.com/zkhvan/z/pkg/iolib"
+)
+
+type Options struct {
+	io     *iolib.IOStreams
+	config cmdutil.Config
+
+	Key string
+}
+
+func NewCmdGet(f *cmdutil.Factory) *cobra.Command {
+	opts := &Options{
+		io:     f.IOStreams,
+		config: f.Config,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "get <key>",
+		Short: "Print the value of a config key",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			opts.Key = args[0]
+			return opts.Run()
+		},
+	}
+
+	return cmd
+}
+
+func (opts *Options) Run() error {
+	value := opts.config.Get(opts.Key)
+
+	switch value.(type) {
+	case nil:
+		return fmt.Errorf("key %q is not set", opts.Key)
+	case map[string]any, []any:
+		enc := yaml.NewEncoder(opts.io.Out)
+		enc.SetIndent(2)
+		if err := enc.Encode(value); err != nil {
+			return fmt.Errorf("error encoding %q: %w", opts.Key, err)
+		}
+		return enc.Close()
+	default:
+		fmt.Fprintln(opts.io.Out, value)
+	}
+
+	return nil
+}
@@ -0,0 +1,238 @@
+package initcmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/MakeNowJust/heredoc/v2"
+	"github.com/spf13/cobra"
+
+	"github.com/zkhvan/z/pkg/cmd/config/internal"
+	"github.com/zkhvan/z/pkg/cmdutil"
+	"github.com/zkhvan/z/pkg/config"
+	"github.com/zkhvan/z/pkg/iolib"
+	"github.com/zkhvan/z/pkg/oslib"
+	"github.com/zkhvan/z/pkg/project"
+	"github.com/zkhvan/z/pkg/prompt"
+)
+
+// defaultDirs are the directories scanned by default, the first existing one
+// is used.
+var defaultDirs = []string{"~/Projects", "~/src", "~/code"}
+
+type Options struct {
+	io     *iolib.IOStreams
+	config cmdutil.Config
+
+	ConfigDir string
+
+	Dir   string
+	Yes   bool
+	Force bool
+}
+
+func NewCmdInit(f *cmdutil.Factory) *cobra.Command {
+	opts := &Options{
+		io:     f.IOStreams,
+		config: f.Config,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "init [<dir>]",
+		Short: "Create the config file from the existing checkouts",
+		Long: heredoc.Doc(`
+			Create the config file from the repositories checked out in a
+			directory, the first existing one of ~/Projects, ~/src and ~/code by
+			default.
+
+			The directory is scanned for Git repositories, and their "origin"
+			remotes are read to infer the root, the maximum depth and the remote
+			patterns which reproduce the current layout. Repositories whose
+			directory doesn't match their remote are reported and left out.
+
+			The inferred config is previewed and confirmed before the config file
+			is written. An existing config file is only replaced with --force, and
+			kept with a .bak extension.
+		`),
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) > 0 {
+				opts.Dir = args[0]
+			}
+			return opts.Run(cmd.Context())
+		},
+	}
+
+	cmd.Flags().BoolVarP(&opts.Yes, "yes", "y", false, "Write the config file without prompting")
+	cmd.Flags().BoolVar(&opts.Force, "force", false, "Replace the existing config file")
+
+	return cmd
+}
+
+func (opts *Options) Run(ctx context.Context) error {
+	interactive := !opts.Yes
+	if interactive && !opts.io.CanPrompt() {
+		return errors.New("confirm the config with --yes")
+	}
+
+	f, err := config.OpenFile(opts.ConfigDir)
+	if err != nil {
+		return err
+	}
+
+	exists, err := fileExists(f.Path())
+	if err != nil {
+		return err
+	}
+	if exists && !opts.Force {
+		return fmt.Errorf("config file already exists: %s (replace it with --force)", f.Path())
+	}
+
+	dir, err := opts.dir(interactive)
+	if err != nil {
+		return err
+	}
+
+	// The root is kept as given if it starts with "~" or a variable, so the
+	// config can be shared between machines.
+	if !strings.HasPrefix(dir, "~") && !strings.HasPrefix(dir, "$") {
+		if dir, err = filepath.Abs(dir); err != nil {
+			return err
+		}
+	}
+
+	service, err := project.NewService(opts.config)
+	if err != nil {
+		return err
+	}
+
+	layout, err := service.InferLayout(ctx, oslib.Expand(dir))
+	if err != nil {
+		return err
+	}
+
+	for _, s := range layout.Skipped {
+		fmt.Fprintf(opts.io.ErrOut, "Skipped %s: %s\n", s.Dir, s.Reason)
+	}
+	if layout.Repos == 0 {
+		return fmt.Errorf("no repositories with a matching remote found in %s", dir)
+	}
+	fmt.Fprintf(opts.io.ErrOut, "Found %d repositories in %s\n\n", layout.Repos, dir)
+
+	b := render(dir, layout)
+	fmt.Fprint(opts.io.Out, string(b))
+
+	path := filepath.Join(filepath.Dir(f.Path()), "config.yaml")
+	if interactive {
+		fmt.Fprintln(opts.io.ErrOut)
+		ok, err := prompt.New(opts.io).Confirm(fmt.Sprintf("Write %s?", path), true)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return nil
+		}
+	}
+
+	out, err := config.OpenPath(path)
+	if err != nil {
+		return err
+	}
+	if err := out.SetBytes(b); err != nil {
+		return err
+	}
+	if err := internal.Validate(out); err != nil {
+		return err
+	}
+
+	if exists {
+		backup := f.Path() + ".bak"
+		if err := os.Rename(f.Path(), backup); err != nil {
+			return fmt.Errorf("error backing up %q: %w", f.Path(), err)
+		}
+		fmt.Fprintf(opts.io.ErrOut, "The previous config is kept at %s\n", backup)
+	}
+
+	if err := out.Save(); err != nil {
+		return fmt.Errorf("error saving %q: %w", path, err)
+	}
+
+	fmt.Fprintf(opts.io.ErrOut, "Wrote %s\n", path)
+	return nil
+}
+
+// dir returns the directory to scan, asking for it unless it's given or
+// running non-interactively.
+func (opts *Options) dir(interactive bool) (string, error) {
+	if opts.Dir != "" {
+		return opts.Dir, nil
+	}
+
+	def := ""
+	for _, d := range defaultDirs {
+		if info, err := os.Stat(oslib.Expand(d)); err == nil && info.IsDir() {
+			def = d
+			break
+		}
+	}
+
+	if !interactive {
+		if def == "" {
+			return "", fmt.Errorf("none of %s exists, pass the directory to scan", strings.Join(defaultDirs, ", "))
+		}
+		return def, nil
+	}
+
+	dir, err := prompt.New(opts.io).Input("Directory to scan", def)
+	if err != nil {
+		return "", err
+	}
+	if dir == "" {
+		return "", errors.New("a directory is required")
+	}
+
+	return dir, nil
+}
+
+// render returns the commented config file of the layout.
+func render(dir string, layout project.Layout) []byte {
+	var buf bytes.Buffer
+
+	fmt.Fprintf(&buf, "# Created by \"z config init\" from the repositories in %s.\n", dir)
+	fmt.Fprintf(&buf, "projects:\n")
+	fmt.Fprintf(&buf, "  # The directory the projects are checked out in.\n")
+	fmt.Fprintf(&buf, "  root: %q\n", dir)
+	fmt.Fprintf(&buf, "  # The depth of the deepest repository under the root.\n")
+	fmt.Fprintf(&buf, "  max_depth: %d\n", layout.MaxDepth)
+
+	fmt.Fprintf(&buf, "  # The remote repositories, mapped into the directories they're checked out\n")
+	fmt.Fprintf(&buf, "  # in. The format is \"[host/]owner/repo -> ./alternate-path\", see README.\n")
+	for _, host := range layout.UnknownHosts {
+		fmt.Fprintf(&buf, "  #\n")
+		fmt.Fprintf(&buf, "  # %s is assumed to be a GitHub Enterprise Server, add it to the\n", host)
+		fmt.Fprintf(&buf, "  # providers otherwise.\n")
+	}
+	fmt.Fprintf(&buf, "  remote_patterns:\n")
+	for _, pattern := range layout.RemotePatterns {
+		fmt.Fprintf(&buf, "    - %q\n", pattern)
+	}
+
+	return buf.Bytes()
+}
+
+func fileExists(path string) (bool, error) {
+	_, err := os.Stat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
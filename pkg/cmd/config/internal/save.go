@@ -0,0 +1,61 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/zkhvan/z/pkg/config"
+	"github.com/zkhvan/z/pkg/config/sections"
+	"github.com/zkhvan/z/pkg/project"
+)
+
+// Schema returns the schema of the config file.
+func Schema() *config.Schema {
+	return config.NewSchema(sections.All()...)
+}
+
+// Validate returns an error if the config file is invalid.
+func Validate(f *config.File) error {
+	if problems := f.Validate(Schema()); len(problems) > 0 {
+		msgs := make([]string, 0, len(problems))
+		for _, p := range problems {
+			msgs = append(msgs, p.String())
+		}
+		return errors.New("invalid config:\n  " + strings.Join(msgs, "\n  "))
+	}
+
+	cfg, err := f.Config()
+	if err != nil {
+		return fmt.Errorf("invalid config: %w", err)
+	}
+
+	if _, err := project.NewConfig(cfg); err != nil {
+		return fmt.Errorf("invalid config: %w", err)
+	}
+
+	return nil
+}
+
+// Save saves the config file, unless it's invalid.
+func Save(f *config.File) error {
+	if err := Validate(f); err != nil {
+		return err
+	}
+
+	if err := f.Save(); err != nil {
+		return SaveError(f, err)
+	}
+
+	return nil
+}
+
+// SaveError returns the error of saving the config file, suggesting --force
+// if its comments would be lost.
+func SaveError(f *config.File, err error) error {
+	if errors.Is(err, config.ErrCommentsLost) {
+		return fmt.Errorf("%w, pass --force to rewrite it anyway", err)
+	}
+
+	return fmt.Errorf("error saving %q: %w", f.Path(), err)
+}
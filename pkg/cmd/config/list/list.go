@@ -2,16 +2,22 @@ package list
 
 import (
 	"fmt"
+	"slices"
+	"text/tabwriter"
 
+	"github.com/MakeNowJust/heredoc/v2"
 	"github.com/spf13/cobra"
 
 	"github.com/zkhvan/z/pkg/cmdutil"
+	"github.com/zkhvan/z/pkg/config"
 	"github.com/zkhvan/z/pkg/iolib"
 )
 
 type Options struct {
 	io     *iolib.IOStreams
 	config cmdutil.Config
+
+	ShowOrigin bool
 }
 
 func NewCmdList(f *cmdutil.Factory) *cobra.Command {
@@ -23,16 +29,49 @@ func NewCmdList(f *cmdutil.Factory) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "list",
 		Short: "List the current config values",
+		Long: heredoc.Doc(`
+			List the current config values, merged from the following layers,
+			each overriding the previous ones:
+
+			- the defaults
+			- the system-wide config file, /etc/z/config.yaml
+			- the user config file, see "z config edit"
+			- the project config file, the first .z.yaml found from the working
+			  directory up to the root
+			- the profile, selected by --profile, $Z_PROFILE or the working
+			  directory
+			- the environment variables prefixed with Z_, e.g. Z_PROJECTS_ROOT
+
+			Each config file is preceded by the files listed in its include key.
+		`),
 		RunE: func(_ *cobra.Command, _ []string) error {
 			return opts.Run()
 		},
 	}
 
+	cmd.Flags().BoolVar(&opts.ShowOrigin, "show-origin", false, "Show the layer supplying each value")
+
 	return cmd
 }
 
 func (o *Options) Run() error {
-	list := o.config.List()
-	fmt.Fprintln(o.io.Out, list)
-	return nil
+	if !o.ShowOrigin {
+		list := o.config.List()
+		fmt.Fprintln(o.io.Out, list)
+		return nil
+	}
+
+	origins := config.Origins(o.config)
+	keys := make([]string, 0, len(origins))
+	for key := range origins {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	w := tabwriter.NewWriter(o.io.Out, 0, 4, 2, ' ', 0)
+	for _, key := range keys {
+		fmt.Fprintf(w, "%s\t%s -> %v\n", origins[key], key, o.config.Get(key))
+	}
+
+	return w.Flush()
 }
@@ -0,0 +1,145 @@
+package migrate
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/MakeNowJust/heredoc/v2"
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/spf13/cobra"
+
+	"github.com/zkhvan/z/pkg/cmd/config/internal"
+	"github.com/zkhvan/z/pkg/cmdutil"
+	"github.com/zkhvan/z/pkg/config"
+	"github.com/zkhvan/z/pkg/fcache"
+	"github.com/zkhvan/z/pkg/iolib"
+	"github.com/zkhvan/z/pkg/prompt"
+)
+
+type Options struct {
+	io *iolib.IOStreams
+
+	ConfigDir string
+
+	Path   string
+	Yes    bool
+	DryRun bool
+	Force  bool
+}
+
+func NewCmdMigrate(f *cmdutil.Factory) *cobra.Command {
+	opts := &Options{
+		io: f.IOStreams,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "migrate [<file>]",
+		Short: "Update the deprecated keys of a config file",
+		Long: heredoc.Doc(`
+			Update the deprecated keys of a config file, the user config file by
+			default, e.g. "remotePatterns" is renamed to "remote_patterns" and
+			"cli/cli -> ./oss/" is rewritten to "cli/cli -> ./oss/*".
+
+			The changes are previewed as a diff and confirmed before the file is
+			rewritten. The original file is kept with a .bak extension.
+
+			Until then, the deprecated keys keep working and a warning is printed
+			the first time the config is loaded after the file changed.
+		`),
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			if len(args) > 0 {
+				opts.Path = args[0]
+			}
+			return opts.Run()
+		},
+	}
+
+	cmd.Flags().BoolVarP(&opts.Yes, "yes", "y", false, "Rewrite the file without confirmation")
+	cmd.Flags().BoolVar(&opts.DryRun, "dry-run", false, "Only preview the changes")
+	cmd.Flags().BoolVar(&opts.Force, "force", false, "Rewrite a TOML file even though its comments are lost")
+
+	return cmd
+}
+
+func (opts *Options) Run() error {
+	f, err := opts.open()
+	if err != nil {
+		return err
+	}
+
+	original, err := f.Bytes()
+	if err != nil {
+		return err
+	}
+
+	changes := f.Migrate(internal.Schema())
+	if len(changes) == 0 {
+		fmt.Fprintf(opts.io.ErrOut, "%s is up to date\n", f.Path())
+		return nil
+	}
+
+	migrated, err := f.Bytes()
+	if err != nil {
+		return fmt.Errorf("error encoding %q: %w", f.Path(), err)
+	}
+
+	for _, c := range changes {
+		fmt.Fprintf(opts.io.ErrOut, "%s:%d: %s: %s\n", f.Path(), c.Line, c.Key, c.Message)
+	}
+
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(string(original)),
+		B:        difflib.SplitLines(string(migrated)),
+		FromFile: f.Path(),
+		ToFile:   f.Path(),
+		Context:  3,
+	})
+	if err != nil {
+		return fmt.Errorf("error comparing %q: %w", f.Path(), err)
+	}
+	fmt.Fprint(opts.io.Out, diff)
+
+	if opts.DryRun {
+		return nil
+	}
+
+	f.SetForce(opts.Force)
+	if err := f.CheckComments(); err != nil {
+		return internal.SaveError(f, err)
+	}
+
+	if !opts.Yes {
+		if !opts.io.CanPrompt() {
+			return errors.New("confirm the changes with --yes")
+		}
+
+		ok, err := prompt.New(opts.io).Confirm(fmt.Sprintf("Rewrite %s?", f.Path()), true)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return nil
+		}
+	}
+
+	backup := f.Path() + ".bak"
+	if err := fcache.WriteFile(backup, original, 0o644); err != nil {
+		return fmt.Errorf("error backing up %q: %w", f.Path(), err)
+	}
+
+	if err := f.Save(); err != nil {
+		return internal.SaveError(f, err)
+	}
+
+	fmt.Fprintf(opts.io.ErrOut, "Migrated %s, the original is kept at %s\n", f.Path(), backup)
+	return nil
+}
+
+func (opts *Options) open() (*config.File, error) {
+	if opts.Path != "" {
+		return config.OpenPath(opts.Path)
+	}
+
+	return config.OpenFile(opts.ConfigDir)
+}
@@ -0,0 +1,49 @@
+package remove
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/zkhvan/z/pkg/cmd/config/internal"
+	"github.com/zkhvan/z/pkg/cmdutil"
+	"github.com/zkhvan/z/pkg/config"
+)
+
+type Options struct {
+	ConfigDir string
+
+	Key    string
+	Values []string
+	Force  bool
+}
+
+func NewCmdRemove(_ *cmdutil.Factory) *cobra.Command {
+	opts := &Options{}
+
+	cmd := &cobra.Command{
+		Use:   "remove <key> <value>...",
+		Short: "Remove values from a config list",
+		Args:  cobra.MinimumNArgs(2),
+		RunE: func(_ *cobra.Command, args []string) error {
+			opts.Key, opts.Values = args[0], args[1:]
+			return opts.Run()
+		},
+	}
+
+	cmd.Flags().BoolVar(&opts.Force, "force", false, "Rewrite a TOML file even though its comments are lost")
+
+	return cmd
+}
+
+func (opts *Options) Run() error {
+	f, err := config.OpenFile(opts.ConfigDir)
+	if err != nil {
+		return err
+	}
+	f.SetForce(opts.Force)
+
+	if err := f.Remove(opts.Key, opts.Values...); err != nil {
+		return err
+	}
+
+	return internal.Save(f)
+}
@@ -0,0 +1,51 @@
+package schema
+
+import (
+	"encoding/json"
+
+	"github.com/MakeNowJust/heredoc/v2"
+	"github.com/spf13/cobra"
+
+	"github.com/zkhvan/z/pkg/cmd/config/internal"
+	"github.com/zkhvan/z/pkg/cmdutil"
+	"github.com/zkhvan/z/pkg/iolib"
+)
+
+type Options struct {
+	io *iolib.IOStreams
+}
+
+func NewCmdSchema(f *cmdutil.Factory) *cobra.Command {
+	opts := &Options{
+		io: f.IOStreams,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "schema",
+		Short: "Print the JSON Schema of the config file",
+		Long: heredoc.Doc(`
+			Print the JSON Schema of the config file, for the completion and
+			validation of editors.
+
+			For example, with the YAML language server:
+
+			  $ z config schema > ~/.config/z/schema.json
+
+			and at the top of the config file:
+
+			  # yaml-language-server: $schema=./schema.json
+		`),
+		Args: cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			return opts.Run()
+		},
+	}
+
+	return cmd
+}
+
+func (opts *Options) Run() error {
+	enc := json.NewEncoder(opts.io.Out)
+	enc.SetIndent("", "  ")
+	return enc.Encode(internal.Schema())
+}
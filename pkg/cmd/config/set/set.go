@@ -0,0 +1,58 @@
+package set
+
+import (
+	"github.com/MakeNowJust/heredoc/v2"
+	"github.com/spf13/cobra"
+
+	"github.com/zkhvan/z/pkg/cmd/config/internal"
+	"github.com/zkhvan/z/pkg/cmdutil"
+	"github.com/zkhvan/z/pkg/config"
+)
+
+type Options struct {
+	ConfigDir string
+
+	Key   string
+	Value string
+	Force bool
+}
+
+func NewCmdSet(_ *cmdutil.Factory) *cobra.Command {
+	opts := &Options{}
+
+	cmd := &cobra.Command{
+		Use:   "set <key> <value>",
+		Short: "Set the value of a config key",
+		Long: heredoc.Doc(`
+			Set the value of a config key, e.g. "projects.ttl". The value is
+			parsed as YAML, so "3600" is a number and "[a/*, b/*]" a list.
+
+			The ordering of the config file is kept, and its comments in YAML.
+			A TOML file with comments isn't rewritten unless --force is given,
+			since its comments are lost.
+		`),
+		Args: cobra.ExactArgs(2),
+		RunE: func(_ *cobra.Command, args []string) error {
+			opts.Key, opts.Value = args[0], args[1]
+			return opts.Run()
+		},
+	}
+
+	cmd.Flags().BoolVar(&opts.Force, "force", false, "Rewrite a TOML file even though its comments are lost")
+
+	return cmd
+}
+
+func (opts *Options) Run() error {
+	f, err := config.OpenFile(opts.ConfigDir)
+	if err != nil {
+		return err
+	}
+	f.SetForce(opts.Force)
+
+	if err := f.Set(opts.Key, opts.Value); err != nil {
+		return err
+	}
+
+	return internal.Save(f)
+}
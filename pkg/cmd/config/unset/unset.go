@@ -0,0 +1,48 @@
+package unset
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/zkhvan/z/pkg/cmd/config/internal"
+	"github.com/zkhvan/z/pkg/cmdutil"
+	"github.com/zkhvan/z/pkg/config"
+)
+
+type Options struct {
+	ConfigDir string
+
+	Key   string
+	Force bool
+}
+
+func NewCmdUnset(_ *cmdutil.Factory) *cobra.Command {
+	opts := &Options{}
+
+	cmd := &cobra.Command{
+		Use:   "unset <key>",
+		Short: "Remove a config key",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			opts.Key = args[0]
+			return opts.Run()
+		},
+	}
+
+	cmd.Flags().BoolVar(&opts.Force, "force", false, "Rewrite a TOML file even though its comments are lost")
+
+	return cmd
+}
+
+func (opts *Options) Run() error {
+	f, err := config.OpenFile(opts.ConfigDir)
+	if err != nil {
+		return err
+	}
+	f.SetForce(opts.Force)
+
+	if err := f.Unset(opts.Key); err != nil {
+		return err
+	}
+
+	return internal.Save(f)
+}
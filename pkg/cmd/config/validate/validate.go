@@ -0,0 +1,66 @@
+package validate
+
+import (
+	"fmt"
+
+	"github.com/MakeNowJust/heredoc/v2"
+	"github.com/spf13/cobra"
+
+	"github.com/zkhvan/z/pkg/cmd/config/internal"
+	"github.com/zkhvan/z/pkg/cmdutil"
+	"github.com/zkhvan/z/pkg/config"
+	"github.com/zkhvan/z/pkg/iolib"
+)
+
+type Options struct {
+	io *iolib.IOStreams
+
+	ConfigDir string
+}
+
+func NewCmdValidate(f *cmdutil.Factory) *cobra.Command {
+	opts := &Options{
+		io: f.IOStreams,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "validate",
+		Short: "Validate the config file",
+		Long: heredoc.Doc(`
+			Validate the config file and report its problems with their line
+			numbers: unknown keys, values of the wrong type and invalid remote
+			patterns.
+		`),
+		Args: cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			return opts.Run()
+		},
+	}
+
+	return cmd
+}
+
+func (opts *Options) Run() error {
+	f, err := config.OpenFile(opts.ConfigDir)
+	if err != nil {
+		return err
+	}
+
+	problems := f.Validate(internal.Schema())
+	for _, p := range problems {
+		fmt.Fprintf(opts.io.Out, "%s:%d:%d: %s: %s\n", f.Path(), p.Line, p.Column, p.Key, p.Message)
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("found %d problem(s) in %s", len(problems), f.Path())
+	}
+
+	// The schema doesn't know about the semantics of the values, e.g. a
+	// remote pattern of an unknown host.
+	if err := internal.Validate(f); err != nil {
+		return fmt.Errorf("%s: %w", f.Path(), err)
+	}
+
+	fmt.Fprintf(opts.io.Out, "%s is valid\n", f.Path())
+	return nil
+}
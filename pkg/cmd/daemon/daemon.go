@@ -0,0 +1,46 @@
+package daemon
+
+import (
+	"github.com/MakeNowJust/heredoc/v2"
+	"github.com/spf13/cobra"
+
+	"github.com/zkhvan/z/pkg/cmd/daemon/internal"
+	runCmd "github.com/zkhvan/z/pkg/cmd/daemon/run"
+	statusCmd "github.com/zkhvan/z/pkg/cmd/daemon/status"
+	stopCmd "github.com/zkhvan/z/pkg/cmd/daemon/stop"
+	"github.com/zkhvan/z/pkg/cmdutil"
+)
+
+func NewCmdDaemon(f *cmdutil.Factory) *cobra.Command {
+	daemonOpts := &internal.DaemonOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "daemon",
+		Short: "Manage the project daemon",
+		Long: heredoc.Doc(`
+			The daemon keeps the local projects indexed by watching the projects
+			root, and refreshes the remote projects once their cache expires.
+
+			"z project list" and "z project select" use the daemon when it's
+			running, and scan the projects root themselves otherwise.
+
+			The daemon answers JSON-RPC 2.0 requests on a Unix socket in the cache
+			directory, one JSON message per line, for editors and other tools:
+
+			- list: {"local": true, "remote": true} returns the projects
+			- status: returns the status of the daemon
+			- resolve: {"input": "owner/repo"} returns the project of the input
+		`),
+	}
+
+	cmd.PersistentFlags().StringVar(&daemonOpts.CacheDir, "cache-dir", "", heredoc.Doc(`
+		The cache directory, where the socket of the daemon is. By default,
+		the cache is saved in $Z_CACHE_DIR, $XDG_CACHE_HOME/z or ~/.cache/z/
+	`))
+
+	cmd.AddCommand(runCmd.NewCmdRun(f, daemonOpts))
+	cmd.AddCommand(statusCmd.NewCmdStatus(f, daemonOpts))
+	cmd.AddCommand(stopCmd.NewCmdStop(f, daemonOpts))
+
+	return cmd
+}
@@ -0,0 +1,28 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/zkhvan/z/pkg/jsonrpc"
+	"github.com/zkhvan/z/pkg/project"
+)
+
+type DaemonOptions struct {
+	CacheDir string
+}
+
+// Socket returns the path of the socket of the daemon.
+func (o *DaemonOptions) Socket() string {
+	return project.DaemonSocket(o.CacheDir)
+}
+
+// Dial connects to the daemon.
+func (o *DaemonOptions) Dial(ctx context.Context) (*jsonrpc.Client, error) {
+	client, err := project.DialDaemon(ctx, o.Socket())
+	if err != nil {
+		return nil, fmt.Errorf("the daemon isn't running: %w", err)
+	}
+
+	return client, nil
+}
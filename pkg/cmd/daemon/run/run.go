@@ -0,0 +1,70 @@
+package run
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/MakeNowJust/heredoc/v2"
+	"github.com/spf13/cobra"
+
+	"github.com/zkhvan/z/pkg/cmd/daemon/internal"
+	"github.com/zkhvan/z/pkg/cmdutil"
+	"github.com/zkhvan/z/pkg/iolib"
+	"github.com/zkhvan/z/pkg/project"
+)
+
+type Options struct {
+	*internal.DaemonOptions
+	io     *iolib.IOStreams
+	config cmdutil.Config
+
+	Offline bool
+}
+
+func NewCmdRun(f *cmdutil.Factory, daemonOpts *internal.DaemonOptions) *cobra.Command {
+	opts := &Options{
+		DaemonOptions: daemonOpts,
+		io:            f.IOStreams,
+		config:        f.Config,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "run",
+		Short: "Run the daemon in the foreground",
+		Long: heredoc.Doc(`
+			Run the daemon in the foreground, until it's interrupted or stopped
+			with "z daemon stop". Run it from a service manager, e.g. launchd or
+			systemd, to keep it running.
+
+			Restart the daemon after changing the config file.
+		`),
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return opts.Run(cmd.Context())
+		},
+	}
+
+	cmd.Flags().BoolVar(&opts.Offline, "offline", false, "Never call the remote providers")
+
+	return cmd
+}
+
+func (opts *Options) Run(ctx context.Context) error {
+	service, err := project.NewService(
+		opts.config,
+		project.WithCacheDir(opts.CacheDir),
+		project.WithOffline(opts.Offline),
+		project.WithWarnings(opts.io.ErrOut),
+	)
+	if err != nil {
+		return err
+	}
+
+	if client, err := project.DialDaemon(ctx, opts.Socket()); err == nil {
+		client.Close()
+		return project.ErrDaemonRunning
+	}
+
+	fmt.Fprintf(opts.io.ErrOut, "Listening on %s\n", opts.Socket())
+
+	return project.NewDaemon(service).Run(ctx)
+}
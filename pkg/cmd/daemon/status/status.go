@@ -0,0 +1,91 @@
+package status
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/zkhvan/z/pkg/cmd/daemon/internal"
+	"github.com/zkhvan/z/pkg/cmdutil"
+	"github.com/zkhvan/z/pkg/iolib"
+	"github.com/zkhvan/z/pkg/project"
+)
+
+type Options struct {
+	*internal.DaemonOptions
+	io *iolib.IOStreams
+
+	JSON bool
+}
+
+func NewCmdStatus(f *cmdutil.Factory, daemonOpts *internal.DaemonOptions) *cobra.Command {
+	opts := &Options{
+		DaemonOptions: daemonOpts,
+		io:            f.IOStreams,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "status",
+		Short: "Show the status of the daemon",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return opts.Run(cmd.Context())
+		},
+	}
+
+	cmd.Flags().BoolVar(&opts.JSON, "json", false, "Output the status as JSON")
+
+	return cmd
+}
+
+func (opts *Options) Run(ctx context.Context) error {
+	client, err := opts.Dial(ctx)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	var status project.DaemonStatus
+	if err := client.Call(ctx, "status", nil, &status); err != nil {
+		return fmt.Errorf("error getting the status of the daemon: %w", err)
+	}
+
+	if opts.JSON {
+		enc := json.NewEncoder(opts.io.Out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(status)
+	}
+
+	fmt.Fprintf(opts.io.Out, "PID: %d\n", status.PID)
+	fmt.Fprintf(opts.io.Out, "Socket: %s\n", opts.Socket())
+	fmt.Fprintf(opts.io.Out, "Running for: %s\n", time.Since(status.StartedAt).Round(time.Second))
+	fmt.Fprintf(opts.io.Out, "Root: %s\n", status.Root)
+	if status.Profile != "" {
+		fmt.Fprintf(opts.io.Out, "Profile: %s\n", status.Profile)
+	}
+	fmt.Fprintf(
+		opts.io.Out,
+		"Local projects: %d (indexed %s ago, %d watched directories)\n",
+		status.LocalProjects,
+		time.Since(status.IndexedAt).Round(time.Second),
+		status.Watches,
+	)
+
+	switch {
+	case status.Refresh.FinishedAt.IsZero():
+		fmt.Fprintln(opts.io.Out, "Last refresh: never")
+	case len(status.Refresh.Errors) > 0:
+		fmt.Fprintf(
+			opts.io.Out,
+			"Last refresh: %s ago, %d pattern(s) failed\n",
+			time.Since(status.Refresh.FinishedAt).Round(time.Second),
+			len(status.Refresh.Errors),
+		)
+	default:
+		fmt.Fprintf(opts.io.Out, "Last refresh: %s ago\n", time.Since(status.Refresh.FinishedAt).Round(time.Second))
+	}
+
+	return nil
+}
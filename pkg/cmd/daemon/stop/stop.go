@@ -0,0 +1,50 @@
+package stop
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/zkhvan/z/pkg/cmd/daemon/internal"
+	"github.com/zkhvan/z/pkg/cmdutil"
+	"github.com/zkhvan/z/pkg/iolib"
+)
+
+type Options struct {
+	*internal.DaemonOptions
+	io *iolib.IOStreams
+}
+
+func NewCmdStop(f *cmdutil.Factory, daemonOpts *internal.DaemonOptions) *cobra.Command {
+	opts := &Options{
+		DaemonOptions: daemonOpts,
+		io:            f.IOStreams,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "stop",
+		Short: "Stop the daemon",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return opts.Run(cmd.Context())
+		},
+	}
+
+	return cmd
+}
+
+func (opts *Options) Run(ctx context.Context) error {
+	client, err := opts.Dial(ctx)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if err := client.Call(ctx, "shutdown", nil, nil); err != nil {
+		return fmt.Errorf("error stopping the daemon: %w", err)
+	}
+
+	fmt.Fprintln(opts.io.ErrOut, "Stopped the daemon")
+
+	return nil
+}
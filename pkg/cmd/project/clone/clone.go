@@ -18,7 +18,8 @@ type Options struct {
 	io     *iolib.IOStreams
 	config cmdutil.Config
 
-	ID string
+	ID         string
+	OnConflict string
 }
 
 func NewCmdClone(f *cmdutil.Factory, projectOpts *internal.ProjectOptions) *cobra.Command {
@@ -29,10 +30,19 @@ func NewCmdClone(f *cmdutil.Factory, projectOpts *internal.ProjectOptions) *cobr
 	}
 
 	cmd := &cobra.Command{
-		Use:   "clone <remote-id>",
+		Use:   "clone <project>",
 		Short: "Clone a project",
 		Long: heredoc.Doc(`
 			Clone a project to the default path.
+
+			The project can be given as owner/repo, host/owner/repo, an https
+			or ssh URL, a path inside the projects root, or an alias from the
+			config file.
+
+			If the destination already contains a clone of the same remote,
+			nothing is cloned. If it contains something else, you'll be asked
+			whether to rename it, move it aside or clone somewhere else. Use
+			--on-conflict to choose non-interactively.
 		`),
 		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -43,6 +53,8 @@ func NewCmdClone(f *cmdutil.Factory, projectOpts *internal.ProjectOptions) *cobr
 		},
 	}
 
+	internal.AddOnConflictFlag(cmd, &opts.OnConflict)
+
 	return cmd
 }
 
@@ -65,7 +77,12 @@ func (opts *Options) Run(ctx context.Context) error {
 		return err
 	}
 
-	output, err := service.CloneProject(ctx, proj)
+	cloneOpts, err := internal.CloneOptions(opts.io, opts.OnConflict)
+	if err != nil {
+		return err
+	}
+
+	_, output, err := service.CloneProject(ctx, proj, cloneOpts)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,39 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+
+	"github.com/zkhvan/z/pkg/cmdutil"
+	"github.com/zkhvan/z/pkg/config"
+)
+
+// BackgroundRefresh returns a function starting "z project refresh" in the
+// background, detached from the current process so it outlives it. It uses
+// the same profile as the current process.
+func BackgroundRefresh(opts *ProjectOptions, cfg cmdutil.Config) func() error {
+	return func() error {
+		executable, err := os.Executable()
+		if err != nil {
+			return fmt.Errorf("error finding the executable: %w", err)
+		}
+
+		args := []string{"project", "refresh", "--background"}
+		if opts.CacheDir != "" {
+			args = append(args, "--cache-dir", opts.CacheDir)
+		}
+		if profile := config.ActiveProfile(cfg); profile != "" {
+			args = append(args, "--profile", profile)
+		}
+
+		cmd := exec.Command(executable, args...)
+		detach(cmd)
+
+		if err := cmd.Start(); err != nil {
+			return fmt.Errorf("error running command %q: %w", cmd.String(), err)
+		}
+
+		return cmd.Process.Release()
+	}
+}
@@ -0,0 +1,7 @@
+//go:build !unix
+
+package internal
+
+import "os/exec"
+
+func detach(_ *exec.Cmd) {}
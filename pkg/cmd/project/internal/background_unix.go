@@ -0,0 +1,14 @@
+//go:build unix
+
+package internal
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// detach starts the command in a new session, so it isn't killed along with
+// the terminal or the fzf picker.
+func detach(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+}
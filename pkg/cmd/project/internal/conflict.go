@@ -0,0 +1,85 @@
+package internal
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/zkhvan/z/pkg/iolib"
+	"github.com/zkhvan/z/pkg/oslib"
+	"github.com/zkhvan/z/pkg/project"
+	"github.com/zkhvan/z/pkg/prompt"
+)
+
+// AddOnConflictFlag registers the --on-conflict flag.
+func AddOnConflictFlag(cmd *cobra.Command, p *string) {
+	cmd.Flags().StringVar(
+		p,
+		"on-conflict",
+		string(project.ConflictPolicyAsk),
+		"What to do when the clone destination exists: ask, fail, rename, move-aside or elsewhere",
+	)
+}
+
+// CloneOptions builds the clone options for the given --on-conflict value.
+//
+// When the policy is "ask" and the user can't be prompted, the conflict is
+// returned as an error instead.
+func CloneOptions(io *iolib.IOStreams, onConflict string) (*project.CloneOptions, error) {
+	policy, err := project.ParseConflictPolicy(onConflict)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := &project.CloneOptions{OnConflict: policy}
+	if policy == project.ConflictPolicyAsk && io.CanPrompt() {
+		opts.Prompt = conflictPrompt(prompt.New(io))
+	}
+
+	return opts, nil
+}
+
+func conflictPrompt(p *prompt.Prompter) func(project.Conflict) (project.ConflictResolution, error) {
+	return func(c project.Conflict) (project.ConflictResolution, error) {
+		var resolution project.ConflictResolution
+
+		choices := []struct {
+			policy project.ConflictPolicy
+			label  string
+		}{
+			{project.ConflictPolicyRename, "Rename the existing directory"},
+			{project.ConflictPolicyMoveAside, "Move the existing directory aside"},
+			{project.ConflictPolicyElsewhere, "Clone somewhere else"},
+			{project.ConflictPolicyFail, "Abort"},
+		}
+
+		labels := make([]string, 0, len(choices))
+		for _, choice := range choices {
+			labels = append(labels, choice.label)
+		}
+
+		message := fmt.Sprintf("%s already exists (%s). What do you want to do?", c.Project.AbsolutePath, c.Reason)
+		index, err := p.Select(message, labels, len(choices)-1)
+		if err != nil {
+			return resolution, err
+		}
+		resolution.Policy = choices[index].policy
+
+		switch resolution.Policy {
+		case project.ConflictPolicyRename:
+			resolution.Path, err = p.Input(
+				"New path for the existing directory",
+				project.SuggestConflictPath(c.Project, resolution.Policy),
+			)
+		case project.ConflictPolicyElsewhere:
+			resolution.Path, err = p.Input(
+				"Path to clone into",
+				project.SuggestConflictPath(c.Project, resolution.Policy),
+			)
+		}
+
+		resolution.Path = oslib.Expand(resolution.Path)
+
+		return resolution, err
+	}
+}
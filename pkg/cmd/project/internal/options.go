@@ -2,4 +2,5 @@ package internal
 
 type ProjectOptions struct {
 	CacheDir string
+	Offline  bool
 }
@@ -0,0 +1,37 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/zkhvan/z/pkg/cmdutil"
+	"github.com/zkhvan/z/pkg/config"
+)
+
+// PreviewCommand returns the shell command running "z project preview", to
+// which the fuzzy finder appends the project. It uses the same profile as the
+// current process.
+func PreviewCommand(opts *ProjectOptions, cfg cmdutil.Config) (string, error) {
+	executable, err := os.Executable()
+	if err != nil {
+		return "", fmt.Errorf("error finding the executable: %w", err)
+	}
+
+	args := []string{executable, "project", "preview"}
+	if opts.CacheDir != "" {
+		args = append(args, "--cache-dir", opts.CacheDir)
+	}
+	if opts.Offline {
+		args = append(args, "--offline")
+	}
+	if profile := config.ActiveProfile(cfg); profile != "" {
+		args = append(args, "--profile", profile)
+	}
+
+	for i, arg := range args {
+		args[i] = cmdutil.ShellQuote(arg)
+	}
+
+	return strings.Join(args, " "), nil
+}
@@ -20,6 +20,7 @@ type Options struct {
 
 	FullPath     bool
 	RefreshCache bool
+	Rescan       bool
 	Remote       bool
 	Local        bool
 }
@@ -38,7 +39,8 @@ func NewCmdList(f *cmdutil.Factory, projectOpts *internal.ProjectOptions) *cobra
 			List the projects defined in the config file.
 
 			Local projects are found by searching for '.git' directories.
-			Remote projects are found by searching for repositories on GitHub.
+			Remote projects are found by searching for repositories on GitHub,
+			GitLab or any other configured provider.
 		`),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if err := opts.Complete(cmd, args); err != nil {
@@ -50,6 +52,7 @@ func NewCmdList(f *cmdutil.Factory, projectOpts *internal.ProjectOptions) *cobra
 
 	cmd.Flags().BoolVar(&opts.FullPath, "full-path", false, "Output the full path")
 	cmd.Flags().BoolVar(&opts.RefreshCache, "refresh-cache", false, "Refresh the cache")
+	cmd.Flags().BoolVar(&opts.Rescan, "rescan", false, "Walk the whole projects root instead of using the local index")
 	cmd.Flags().BoolVar(&opts.Remote, "remote", true, "List remote projects")
 	cmd.Flags().BoolVar(&opts.Local, "local", true, "List local projects")
 
@@ -79,7 +82,12 @@ func (opts *Options) Run(ctx context.Context) error {
 	service, err := project.NewService(
 		opts.config,
 		project.WithRefreshCache(opts.RefreshCache),
+		project.WithRescan(opts.Rescan),
+		project.WithDaemon(project.DaemonSocket(opts.CacheDir)),
 		project.WithCacheDir(opts.CacheDir),
+		project.WithOffline(opts.Offline),
+		project.WithBackgroundRefresh(internal.BackgroundRefresh(opts.ProjectOptions, opts.config)),
+		project.WithWarnings(opts.io.ErrOut),
 	)
 	if err != nil {
 		return err
@@ -0,0 +1,207 @@
+package preview
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/MakeNowJust/heredoc/v2"
+	"github.com/spf13/cobra"
+
+	"github.com/zkhvan/z/pkg/cmd/project/internal"
+	"github.com/zkhvan/z/pkg/cmdutil"
+	"github.com/zkhvan/z/pkg/git"
+	"github.com/zkhvan/z/pkg/iolib"
+	"github.com/zkhvan/z/pkg/project"
+)
+
+const (
+	// commits is the number of recent commits shown.
+	commits = 5
+	// readmeLines is the number of lines of the README shown.
+	readmeLines = 20
+)
+
+// readmeNames are the names of the README looked for, in order.
+var readmeNames = []string{"README.md", "README", "README.markdown", "README.rst", "README.txt", "readme.md"}
+
+type Options struct {
+	*internal.ProjectOptions
+	io     *iolib.IOStreams
+	config cmdutil.Config
+
+	ID string
+}
+
+func NewCmdPreview(f *cmdutil.Factory, projectOpts *internal.ProjectOptions) *cobra.Command {
+	opts := &Options{
+		ProjectOptions: projectOpts,
+		io:             f.IOStreams,
+		config:         f.Config,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "preview <id>",
+		Short: "Preview a project",
+		Long: heredoc.Doc(`
+			Print the details of a project, shown in the preview window of
+			"z project select".
+
+			Local projects show their branch, status, recent commits and the
+			beginning of their README. Remote projects show the description,
+			language, stars and last push of the repository, which are cached.
+		`),
+		Hidden: true,
+		Args:   cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.ID = args[0]
+			return opts.Run(cmd.Context())
+		},
+	}
+
+	return cmd
+}
+
+func (opts *Options) Run(ctx context.Context) error {
+	service, err := project.NewService(
+		opts.config,
+		project.WithCacheDir(opts.CacheDir),
+		project.WithOffline(opts.Offline),
+	)
+	if err != nil {
+		return err
+	}
+
+	// The checked out projects are previewed by their path, which can be
+	// outside the root, e.g. when cloned elsewhere on a conflict.
+	if filepath.IsAbs(opts.ID) && isDir(opts.ID) {
+		previewLocal(ctx, opts.io.Out, project.Project{AbsolutePath: opts.ID})
+		return nil
+	}
+
+	p, err := service.Resolve(ctx, opts.ID)
+	if err != nil {
+		return err
+	}
+
+	if isDir(p.AbsolutePath) {
+		previewLocal(ctx, opts.io.Out, p)
+		return nil
+	}
+
+	return previewRemote(ctx, opts.io.Out, service, p)
+}
+
+// previewLocal prints the state of the repository and its README. Errors are
+// left out, e.g. for a directory which isn't a repository.
+func previewLocal(ctx context.Context, w io.Writer, p project.Project) {
+	fmt.Fprintln(w, p.AbsolutePath)
+
+	client := git.NewClient()
+	if status, err := client.Status(ctx, p.AbsolutePath); err == nil {
+		fmt.Fprintf(w, "Branch: %s\n", branch(status))
+
+		if status.Changes == 0 {
+			fmt.Fprintln(w, "Status: clean")
+		} else {
+			fmt.Fprintf(w, "Status: %d changed %s\n", status.Changes, plural(status.Changes, "file"))
+		}
+	}
+
+	if log, err := client.Log(ctx, p.AbsolutePath, commits); err == nil && len(log) > 0 {
+		fmt.Fprintln(w, "\nRecent commits:")
+		for _, line := range log {
+			fmt.Fprintf(w, "  %s\n", line)
+		}
+	}
+
+	for _, name := range readmeNames {
+		if head, err := readHead(filepath.Join(p.AbsolutePath, name), readmeLines); err == nil {
+			fmt.Fprintf(w, "\n%s:\n%s", name, head)
+			break
+		}
+	}
+}
+
+// previewRemote prints the details of the remote repository.
+func previewRemote(ctx context.Context, w io.Writer, service *project.Service, p project.Project) error {
+	fmt.Fprintf(w, "%s/%s\n", p.HostName(), p.RemoteID)
+
+	details, err := service.RepoDetails(ctx, p)
+	if err != nil {
+		return err
+	}
+
+	if details.Description != "" {
+		fmt.Fprintf(w, "%s\n\n", details.Description)
+	}
+	if details.Language != "" {
+		fmt.Fprintf(w, "Language: %s\n", details.Language)
+	}
+	fmt.Fprintf(w, "Stars: %d\n", details.Stars)
+	if !details.PushedAt.IsZero() {
+		fmt.Fprintf(w, "Last push: %s\n", details.PushedAt.Local().Format("2006-01-02 15:04"))
+	}
+
+	fmt.Fprintf(w, "\nNot cloned, it's cloned into %s when selected.\n", p.AbsolutePath)
+	return nil
+}
+
+// branch describes the branch and how it compares to its upstream.
+func branch(s git.Status) string {
+	name := s.Branch
+	if name == "" {
+		name = "(detached)"
+	}
+
+	if s.Upstream == "" {
+		return name
+	}
+
+	var ab []string
+	if s.Ahead > 0 {
+		ab = append(ab, fmt.Sprintf("ahead %d", s.Ahead))
+	}
+	if s.Behind > 0 {
+		ab = append(ab, fmt.Sprintf("behind %d", s.Behind))
+	}
+	if len(ab) == 0 {
+		return fmt.Sprintf("%s (%s)", name, s.Upstream)
+	}
+
+	return fmt.Sprintf("%s (%s, %s)", name, s.Upstream, strings.Join(ab, ", "))
+}
+
+// readHead returns the first n lines of the file.
+func readHead(path string, n int) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	var b strings.Builder
+	scanner := bufio.NewScanner(f)
+	for i := 0; i < n && scanner.Scan(); i++ {
+		b.WriteString(scanner.Text())
+		b.WriteString("\n")
+	}
+
+	return b.String(), scanner.Err()
+}
+
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
+func plural(n int, word string) string {
+	if n == 1 {
+		return word
+	}
+	return word + "s"
+}
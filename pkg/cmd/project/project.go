@@ -7,6 +7,7 @@ import (
 	cloneCmd "github.com/zkhvan/z/pkg/cmd/project/clone"
 	"github.com/zkhvan/z/pkg/cmd/project/internal"
 	listCmd "github.com/zkhvan/z/pkg/cmd/project/list"
+	previewCmd "github.com/zkhvan/z/pkg/cmd/project/preview"
 	refreshCmd "github.com/zkhvan/z/pkg/cmd/project/refresh"
 	selectCmd "github.com/zkhvan/z/pkg/cmd/project/select"
 	"github.com/zkhvan/z/pkg/cmdutil"
@@ -22,13 +23,20 @@ func NewCmdProject(f *cmdutil.Factory) *cobra.Command {
 
 	cmd.PersistentFlags().StringVar(&projectOpts.CacheDir, "cache-dir", "", heredoc.Doc(`
 		The directory to cache the list of projects. By default, the cache
-		will be saved in $XDG_CACHE_DIR/z or ~/.cache/z/
+		will be saved in $Z_CACHE_DIR, $XDG_CACHE_HOME/z or ~/.cache/z/
+	`))
+
+	cmd.PersistentFlags().BoolVar(&projectOpts.Offline, "offline", false, heredoc.Doc(`
+		Never call the remote providers, and serve the remote projects from
+		the cache even if expired. Can also be set with projects.offline in
+		the config file.
 	`))
 
 	cmd.AddCommand(listCmd.NewCmdList(f, projectOpts))
 	cmd.AddCommand(refreshCmd.NewCmdRefresh(f, projectOpts))
 	cmd.AddCommand(cloneCmd.NewCmdClone(f, projectOpts))
 	cmd.AddCommand(selectCmd.NewCmdSelect(f, projectOpts))
+	cmd.AddCommand(previewCmd.NewCmdPreview(f, projectOpts))
 
 	return cmd
 }
@@ -2,12 +2,18 @@ package refresh
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
 
 	"github.com/MakeNowJust/heredoc/v2"
 	"github.com/spf13/cobra"
 
 	"github.com/zkhvan/z/pkg/cmd/project/internal"
 	"github.com/zkhvan/z/pkg/cmdutil"
+	"github.com/zkhvan/z/pkg/fcache"
 	"github.com/zkhvan/z/pkg/iolib"
 	"github.com/zkhvan/z/pkg/project"
 )
@@ -16,6 +22,10 @@ type Options struct {
 	*internal.ProjectOptions
 	io     *iolib.IOStreams
 	config cmdutil.Config
+
+	Patterns   []string
+	Status     bool
+	Background bool
 }
 
 func NewCmdRefresh(f *cmdutil.Factory, projectOpts *internal.ProjectOptions) *cobra.Command {
@@ -26,36 +36,97 @@ func NewCmdRefresh(f *cmdutil.Factory, projectOpts *internal.ProjectOptions) *co
 	}
 
 	cmd := &cobra.Command{
-		Use:   "refresh",
+		Use:   "refresh [<pattern|owner>...]",
 		Short: "Refresh the project cache",
 		Long: heredoc.Doc(`
 			Refresh the cache of remote projects defined in the config file.
 
-			This command will force a refresh of the remote projects cache.
+			This command will force a refresh of the remote projects cache. Each
+			remote pattern is cached separately, pass patterns (e.g. "my-org/*")
+			or owners (e.g. "my-org") to only refresh those.
+
+			Once the cache of a pattern expires, "z project list" and "z project select"
+			return the expired projects right away and run this command in the
+			background. Only one refresh runs at a time.
+
+			Use --status to show when the last refresh happened and whether it
+			failed.
 		`),
-		RunE: func(cmd *cobra.Command, _ []string) error {
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.Patterns = args
 			return opts.Run(cmd.Context())
 		},
 	}
 
+	cmd.Flags().BoolVar(&opts.Status, "status", false, "Show the status of the last refresh")
+	cmd.Flags().BoolVar(
+		&opts.Background, "background", false,
+		"Only refresh the expired patterns, and exit quietly if a refresh is already running",
+	)
+	_ = cmd.Flags().MarkHidden("background")
+
 	return cmd
 }
 
 func (opts *Options) Run(ctx context.Context) error {
 	service, err := project.NewService(
 		opts.config,
-		project.WithRefreshCache(true),
 		project.WithCacheDir(opts.CacheDir),
+		project.WithOffline(opts.Offline),
+		project.WithWarnings(opts.io.ErrOut),
 	)
 	if err != nil {
 		return err
 	}
 
-	_, err = service.ListProjects(ctx, &project.ListOptions{
-		Remote: true,
+	if opts.Status {
+		return opts.printStatus(service)
+	}
+
+	err = service.Refresh(ctx, &project.RefreshOptions{
+		Patterns:    opts.Patterns,
+		ExpiredOnly: opts.Background,
 	})
-	if err != nil {
+	if errors.Is(err, project.ErrRefreshRunning) && opts.Background {
+		return nil
+	}
+
+	return err
+}
+
+func (opts *Options) printStatus(service *project.Service) error {
+	status, err := service.RefreshStatus()
+	switch {
+	case errors.Is(err, fcache.ErrNotFound):
+		fmt.Fprintln(opts.io.Out, "Last refresh: never")
+	case err != nil:
 		return err
+	default:
+		fmt.Fprintf(
+			opts.io.Out,
+			"Last refresh: %s (%s ago, took %s)\n",
+			status.FinishedAt.Local().Format(time.DateTime),
+			time.Since(status.FinishedAt).Round(time.Second),
+			status.FinishedAt.Sub(status.StartedAt).Round(time.Millisecond),
+		)
+
+		if len(status.Errors) == 0 {
+			fmt.Fprintln(opts.io.Out, "Status: ok")
+		} else {
+			fmt.Fprintf(opts.io.Out, "Status: %d pattern(s) failed\n", len(status.Errors))
+			for _, pattern := range slices.Sorted(maps.Keys(status.Errors)) {
+				e := status.Errors[pattern]
+				fmt.Fprintf(
+					opts.io.Out,
+					"  %s: %s (%s ago)\n",
+					pattern, e.Error, time.Since(e.FailedAt).Round(time.Second),
+				)
+			}
+		}
+	}
+
+	if status.Running {
+		fmt.Fprintln(opts.io.Out, "A refresh is running")
 	}
 
 	return nil
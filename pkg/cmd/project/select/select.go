@@ -4,7 +4,6 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"os"
 
 	"github.com/MakeNowJust/heredoc/v2"
 	"github.com/atotto/clipboard"
@@ -25,9 +24,11 @@ type Options struct {
 	config cmdutil.Config
 
 	RefreshCache bool
+	Rescan       bool
 	Remote       bool
 	Local        bool
 	Tmux         bool
+	OnConflict   string
 }
 
 func NewCmdSelect(f *cmdutil.Factory, projectOpts *internal.ProjectOptions) *cobra.Command {
@@ -54,9 +55,11 @@ func NewCmdSelect(f *cmdutil.Factory, projectOpts *internal.ProjectOptions) *cob
 	}
 
 	cmd.Flags().BoolVar(&opts.RefreshCache, "refresh-cache", false, "Refresh the cache")
+	cmd.Flags().BoolVar(&opts.Rescan, "rescan", false, "Walk the whole projects root instead of using the local index")
 	cmd.Flags().BoolVar(&opts.Remote, "remote", true, "List remote projects")
 	cmd.Flags().BoolVar(&opts.Local, "local", true, "List local projects")
 	cmd.Flags().BoolVar(&opts.Tmux, "tmux", false, "Open in tmux")
+	internal.AddOnConflictFlag(cmd, &opts.OnConflict)
 
 	return cmd
 }
@@ -83,12 +86,22 @@ func (opts *Options) Run(ctx context.Context) error {
 	service, err := project.NewService(
 		opts.config,
 		project.WithRefreshCache(opts.RefreshCache),
+		project.WithRescan(opts.Rescan),
+		project.WithDaemon(project.DaemonSocket(opts.CacheDir)),
 		project.WithCacheDir(opts.CacheDir),
+		project.WithOffline(opts.Offline),
+		project.WithBackgroundRefresh(internal.BackgroundRefresh(opts.ProjectOptions, opts.config)),
+		project.WithWarnings(opts.io.ErrOut),
 	)
 	if err != nil {
 		return err
 	}
 
+	cloneOpts, err := internal.CloneOptions(opts.io, opts.OnConflict)
+	if err != nil {
+		return err
+	}
+
 	results, err := service.ListProjects(ctx, &project.ListOptions{
 		Local:  opts.Local,
 		Remote: opts.Remote,
@@ -109,23 +122,32 @@ func (opts *Options) Run(ctx context.Context) error {
 		fzf.WithBinding("alt-enter", func(p project.Project) error {
 			shouldCD = false
 
-			opts := &gh.RepoViewOptions{Web: true}
-
 			switch p.Source {
 			case project.SourceTypeRemote, project.SourceTypeSynced:
-				opts.RepositoryID = p.RemoteID
+				return service.OpenInBrowser(ctx, p)
 			case project.SourceTypeLocal:
-				opts.WorkingDirectory = p.AbsolutePath
+				_, err := gh.NewClient().RepoView(ctx, &gh.RepoViewOptions{
+					WorkingDirectory: p.AbsolutePath,
+					Web:              true,
+				})
+				return err
 			default:
 				return fmt.Errorf("unsupported project source: %s", p.Source)
 			}
-
-			_, err = gh.NewClient().RepoView(ctx, opts)
-			return err
 		}),
 	}
 
-	header := "CTRL-Y: Yank | ALT-ENTER: View in browser"
+	previewCommand, err := internal.PreviewCommand(opts.ProjectOptions, opts.config)
+	if err != nil {
+		return err
+	}
+	fzfOpts = append(fzfOpts, fzf.WithPreview(fzf.Preview[project.Project]{
+		Command:  previewCommand,
+		Argument: previewArgument,
+		Window:   service.PreviewWindow(),
+	}))
+
+	header := "CTRL-Y: Yank | ALT-ENTER: View in browser | CTRL-/: Toggle preview"
 	if opts.Tmux {
 		header = fmt.Sprintf("ENTER: Change session | %s", header)
 	} else {
@@ -145,8 +167,11 @@ func (opts *Options) Run(ctx context.Context) error {
 		return err
 	}
 
-	if _, err := os.Lstat(proj.AbsolutePath); os.IsNotExist(err) {
-		output, err := service.CloneProject(ctx, proj)
+	// Remote projects weren't found locally, but the directory might still
+	// exist, which is handled by CloneProject.
+	if proj.Source == project.SourceTypeRemote {
+		var output string
+		proj, output, err = service.CloneProject(ctx, proj, cloneOpts)
 		if err != nil {
 			return err
 		}
@@ -167,5 +192,17 @@ func (opts *Options) Run(ctx context.Context) error {
 }
 
 func projectByPath(p project.Project, _ int) string {
+	if p.Stale {
+		return fmt.Sprintf("%s %s (stale)", p.Source, p.LocalID)
+	}
 	return fmt.Sprintf("%s %s", p.Source, p.LocalID)
 }
+
+// previewArgument identifies the project for "z project preview", by its path
+// if it's checked out, and by its URL otherwise.
+func previewArgument(p project.Project) string {
+	if p.Source == project.SourceTypeRemote {
+		return p.URL()
+	}
+	return p.AbsolutePath
+}
@@ -7,13 +7,16 @@ import (
 
 	"github.com/spf13/cobra"
 
+	cacheCmd "github.com/zkhvan/z/pkg/cmd/cache"
 	configCmd "github.com/zkhvan/z/pkg/cmd/config"
+	daemonCmd "github.com/zkhvan/z/pkg/cmd/daemon"
 	"github.com/zkhvan/z/pkg/cmd/plugin"
 	projectCmd "github.com/zkhvan/z/pkg/cmd/project"
 	shellCmd "github.com/zkhvan/z/pkg/cmd/shell"
 	tmuxCmd "github.com/zkhvan/z/pkg/cmd/tmux"
 	versionCmd "github.com/zkhvan/z/pkg/cmd/version"
 	"github.com/zkhvan/z/pkg/cmdutil"
+	"github.com/zkhvan/z/pkg/config"
 )
 
 func NewCmdRoot(f *cmdutil.Factory, version, date string) (*cobra.Command, error) {
@@ -31,11 +34,18 @@ func NewCmdRoot(f *cmdutil.Factory, version, date string) (*cobra.Command, error
 				plugin.SetupPluginCompletion(cmd, args)
 			}
 
+			if profile, _ := cmd.Flags().GetString("profile"); profile != "" {
+				if err := config.UseProfile(f.Config, profile); err != nil {
+					return err
+				}
+			}
+
 			return nil
 		},
 	}
 
 	cmd.PersistentFlags().Bool("help", false, "Show help for command")
+	cmd.PersistentFlags().String("profile", "", "Use the config profile (default $Z_PROFILE)")
 
 	cmd.SilenceErrors = true
 	cmd.SilenceUsage = true
@@ -48,6 +58,8 @@ func NewCmdRoot(f *cmdutil.Factory, version, date string) (*cobra.Command, error
 	cmd.AddCommand(tmuxCmd.NewCmdTmux(f))
 	cmd.AddCommand(projectCmd.NewCmdProject(f))
 	cmd.AddCommand(configCmd.NewCmdConfig(f))
+	cmd.AddCommand(cacheCmd.NewCmdCache(f))
+	cmd.AddCommand(daemonCmd.NewCmdDaemon(f))
 	cmd.AddCommand(shellCmd.NewCmdShell(f))
 
 	if f.PluginHandler == nil {
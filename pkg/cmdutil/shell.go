@@ -0,0 +1,8 @@
+package cmdutil
+
+import "strings"
+
+// ShellQuote quotes the argument for a POSIX shell.
+func ShellQuote(arg string) string {
+	return "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+}
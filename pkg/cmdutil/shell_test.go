@@ -0,0 +1,26 @@
+package cmdutil_test
+
+import (
+	"testing"
+
+	"github.com/zkhvan/z/pkg/assert"
+	"github.com/zkhvan/z/pkg/cmdutil"
+)
+
+func TestShellQuote(t *testing.T) {
+	tests := map[string]struct {
+		arg      string
+		expected string
+	}{
+		"plain":        {arg: "/srv/git", expected: `'/srv/git'`},
+		"spaces":       {arg: "my repos", expected: `'my repos'`},
+		"single quote": {arg: "it's", expected: `'it'\''s'`},
+		"empty":        {arg: "", expected: `''`},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.EqualString(t, cmdutil.ShellQuote(tc.arg), tc.expected)
+		})
+	}
+}
@@ -3,24 +3,36 @@ package config
 import (
 	"errors"
 	"fmt"
-	"os"
-	"path/filepath"
-	"runtime"
 	"time"
 
-	"github.com/knadh/koanf/parsers/yaml"
-	"github.com/knadh/koanf/providers/file"
 	"github.com/knadh/koanf/v2"
 
 	"github.com/zkhvan/z/pkg/cmdutil"
+	"github.com/zkhvan/z/pkg/dirs"
 )
 
 var ErrNotFound = errors.New("key not found")
 
+// ErrCommentsLost is returned when rewriting a config file would drop its
+// comments, e.g. of a TOML file.
+var ErrCommentsLost = errors.New("rewriting the file drops its comments")
+
 var _ cmdutil.Config = (*provider)(nil)
 
 type provider struct {
 	k *koanf.Koanf
+
+	// origins are the layers supplying the keys, see Origins.
+	origins map[string]string
+
+	// layers are merged again into k when the profile changes, followed by
+	// the profile and env.
+	layers  []layer
+	env     layer
+	workDir string
+
+	// profile is the name of the profile in use, if any.
+	profile string
 }
 
 // List implements cmdutil.Config.
@@ -123,68 +135,44 @@ func New() (cmdutil.Config, error) {
 }
 
 func NewWithDir(dir string) (cmdutil.Config, error) {
-	k := koanf.New(".")
-
-	if dir == "" {
-		configDir, err := configDir()
-		if err != nil {
-			return nil, err
-		}
-		dir = configDir
-	}
+	return Load(Options{Dir: dir})
+}
 
-	path := filepath.Join(dir, "config.yaml")
+// Parse parses the content of a YAML config file.
+func Parse(b []byte) (cmdutil.Config, error) {
+	return parse(b, yamlFormat.parser)
+}
 
-	if _, err := os.Stat(path); os.IsNotExist(err) {
-		return &provider{k: k}, nil
-	}
+func parse(b []byte, parser koanf.Parser) (cmdutil.Config, error) {
+	k := koanf.New(".")
 
-	if err := k.Load(file.Provider(path), yaml.Parser()); err != nil {
+	if err := k.Load(bytesProvider(b), parser); err != nil {
 		return nil, err
 	}
 
 	return &provider{k: k}, nil
 }
 
-func IsNotFound(err error) bool {
-	return errors.Is(err, ErrNotFound)
+// bytesProvider provides the content of a config file to koanf.
+type bytesProvider []byte
+
+func (b bytesProvider) ReadBytes() ([]byte, error) {
+	return b, nil
 }
 
-// userConfigDir returns the default root directory to use for user-specific
-// configuration data. It mimics os.UserConfigDir(), but overrides the
-// defaults for darwin to respect XDG specifications.
-func userConfigDir() (string, error) {
-	var dir string
-
-	switch runtime.GOOS {
-	case "darwin":
-		dir = os.Getenv("XDG_CONFIG_HOME")
-		if dir == "" {
-			dir = os.Getenv("HOME")
-			if dir == "" {
-				return "", errors.New("neither $XDG_CONFIG_HOME nor $HOME are defined")
-			}
-			dir += "/Library/Application Support"
-		} else if !filepath.IsAbs(dir) {
-			return "", errors.New("path in $XDG_CONFIG_HOME is relative")
-		}
-
-	default:
-		var err error
-		dir, err = os.UserConfigDir()
-		if err != nil {
-			return dir, err
-		}
-	}
+func (b bytesProvider) Read() (map[string]any, error) {
+	return nil, errors.New("bytes provider does not support Read()")
+}
 
-	return dir, nil
+func IsNotFound(err error) bool {
+	return errors.Is(err, ErrNotFound)
 }
 
 func configDir() (string, error) {
-	baseDir, err := userConfigDir()
+	dir, err := dirs.Config()
 	if err != nil {
 		return "", fmt.Errorf("error detecting user configuration directory: %w", err)
 	}
 
-	return filepath.Join(baseDir, "z"), nil
+	return dir, nil
 }
@@ -0,0 +1,215 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/MakeNowJust/heredoc/v2"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/zkhvan/z/pkg/assert"
+	"github.com/zkhvan/z/pkg/config"
+)
+
+type testDefaults struct {
+	Root     string   `json:"root"`
+	TTL      int64    `json:"ttl"`
+	Patterns []string `json:"remote_patterns"`
+
+	Aliases map[string]string `json:"aliases"`
+}
+
+func TestLoad(t *testing.T) {
+	tests := map[string]struct {
+		system  string
+		user    string
+		project string
+		files   map[string]string
+		env     map[string]string
+		profile string
+
+		// activeProfile is the profile in use.
+		activeProfile string
+		expected      map[string]string
+		values        map[string]any
+		err           string
+	}{
+		"defaults": {
+			expected: map[string]string{
+				"projects.root": "default",
+				"projects.ttl":  "default",
+			},
+			values: map[string]any{
+				"projects.root": "~/Projects",
+				"projects.ttl":  float64(900),
+			},
+		},
+		"files override each other": {
+			system:  "projects:\n  root: /srv\n  ttl: 60\n",
+			user:    "projects:\n  root: ~/src\n",
+			project: "projects:\n  remote_patterns: [a/*]\n",
+			expected: map[string]string{
+				"projects.root":            "file:$TD/user/config.yaml",
+				"projects.ttl":             "file:$TD/system/config.yaml",
+				"projects.remote_patterns": "file:$TD/work/.z.yaml",
+			},
+			values: map[string]any{
+				"projects.root":            "~/src",
+				"projects.ttl":             60,
+				"projects.remote_patterns": []any{"a/*"},
+			},
+		},
+		"environment variables": {
+			user: "projects:\n  root: ~/src\n",
+			env: map[string]string{
+				"Z_PROJECTS_ROOT":            "/code",
+				"Z_PROJECTS_TTL":             "30",
+				"Z_PROJECTS_REMOTE_PATTERNS": "a/*, b/*",
+				"Z_PROJECTS_ALIASES_DOTS":    "me/dots",
+				"Z_PROJECTS_UNKNOWN":         "ignored",
+			},
+			expected: map[string]string{
+				"projects.root":            "env:Z_PROJECTS_ROOT",
+				"projects.ttl":             "env:Z_PROJECTS_TTL",
+				"projects.remote_patterns": "env:Z_PROJECTS_REMOTE_PATTERNS",
+				"projects.aliases.dots":    "env:Z_PROJECTS_ALIASES_DOTS",
+			},
+			values: map[string]any{
+				"projects.root":            "/code",
+				"projects.ttl":             int64(30),
+				"projects.remote_patterns": []any{"a/*", "b/*"},
+				"projects.aliases.dots":    "me/dots",
+			},
+		},
+		"includes": {
+			user: "include: [team.yaml, missing.yaml]\nprojects:\n  root: ~/src\n",
+			files: map[string]string{
+				"user/team.yaml": "projects:\n  root: /team\n  ttl: 60\n",
+			},
+			expected: map[string]string{
+				"projects.root": "file:$TD/user/config.yaml",
+				"projects.ttl":  "file:$TD/user/team.yaml",
+			},
+			values: map[string]any{
+				"projects.root": "~/src",
+				"projects.ttl":  60,
+				"include":       nil,
+			},
+		},
+		"include cycle": {
+			user: "include: [config.yaml]\n",
+			err:  "include cycle",
+		},
+		"profile by directory": {
+			user: heredoc.Doc(`
+				projects:
+				  root: ~/src
+				profiles:
+				  work:
+				    directories: [$TD/work]
+				    projects:
+				      root: ~/work
+				  home:
+				    directories: [$TD]
+				    projects:
+				      ttl: 60
+			`),
+			activeProfile: "work",
+			expected: map[string]string{
+				"projects.root": "file:$TD/user/config.yaml (profile work)",
+				"projects.ttl":  "default",
+			},
+			values: map[string]any{
+				"projects.root": "~/work",
+				"profiles":      nil,
+			},
+		},
+		"profile by name": {
+			user:          "profiles:\n  work:\n    projects:\n      root: ~/work\n",
+			profile:       "work",
+			activeProfile: "work",
+			values: map[string]any{
+				"projects.root": "~/work",
+			},
+		},
+		"profile by environment variable": {
+			user:          "profiles:\n  work:\n    projects:\n      root: ~/work\n",
+			env:           map[string]string{"Z_PROFILE": "work"},
+			activeProfile: "work",
+			values: map[string]any{
+				"projects.root": "~/work",
+			},
+		},
+		"unknown profile": {
+			profile: "work",
+			err:     `profile "work" not found`,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			td := t.TempDir()
+			dirs := map[string]string{
+				"system":  filepath.Join(td, "system"),
+				"user":    filepath.Join(td, "user"),
+				"project": filepath.Join(td, "work"),
+			}
+			files := map[string]string{
+				filepath.Join(dirs["system"], "config.yaml"): tc.system,
+				filepath.Join(dirs["user"], "config.yaml"):   tc.user,
+				filepath.Join(dirs["project"], ".z.yaml"):    tc.project,
+			}
+			for path, content := range tc.files {
+				files[filepath.Join(td, path)] = content
+			}
+			for path, content := range files {
+				assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+				if content != "" {
+					content = strings.ReplaceAll(content, "$TD", td)
+					assert.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+				}
+			}
+
+			workDir := filepath.Join(dirs["project"], "a", "b")
+			assert.NoError(t, os.MkdirAll(workDir, 0o755))
+
+			for key, value := range tc.env {
+				t.Setenv(key, value)
+			}
+
+			cfg, err := config.Load(config.Options{
+				Dir:       dirs["user"],
+				SystemDir: dirs["system"],
+				WorkDir:   workDir,
+				Profile:   tc.profile,
+				Sections: []config.Section{{
+					Key:   "projects",
+					Value: testDefaults{Root: "~/Projects", TTL: 900},
+				}},
+			})
+			if tc.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.err) {
+					t.Fatalf("expected error %q, got %v", tc.err, err)
+				}
+				return
+			}
+			assert.NoError(t, err)
+
+			assert.EqualString(t, config.ActiveProfile(cfg), tc.activeProfile)
+
+			origins := config.Origins(cfg)
+			for key, origin := range tc.expected {
+				origin = strings.ReplaceAll(origin, "$TD", filepath.ToSlash(td))
+				assert.EqualString(t, origins[key], filepath.FromSlash(origin))
+			}
+
+			for key, value := range tc.values {
+				if diff := cmp.Diff(value, cfg.Get(key)); diff != "" {
+					t.Errorf("Get(%q) mismatch (-want +got):\n%s", key, diff)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// EnvPrefix is the prefix of the environment variables setting config keys,
+// e.g. Z_PROJECTS_ROOT sets "projects.root".
+const EnvPrefix = "Z_"
+
+// envKeys maps environment variables to config keys, using the schema since
+// the keys contain underscores, e.g. Z_PROJECTS_MAX_DEPTH is
+// "projects.max_depth" and not "projects.max.depth".
+type envKeys struct {
+	// keys are the settable keys by variable, e.g. "Z_PROJECTS_ROOT".
+	keys map[string]envKey
+	// maps are the keys of maps by variable prefix, e.g. "Z_PROJECTS_ALIASES_",
+	// whose variables set the lowercased entries, e.g. "projects.aliases.z".
+	maps map[string]envKey
+}
+
+type envKey struct {
+	key    string
+	schema *Schema
+}
+
+func newEnvKeys(schema *Schema) envKeys {
+	e := envKeys{
+		keys: make(map[string]envKey),
+		maps: make(map[string]envKey),
+	}
+	e.add("", schema)
+
+	return e
+}
+
+func (e envKeys) add(key string, s *Schema) {
+	name := EnvPrefix + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+
+	switch {
+	case s.Values != nil:
+		if s.Values.scalar() {
+			e.maps[name+"_"] = envKey{key: key, schema: s.Values}
+		}
+	case s.Type == "object":
+		for property, child := range s.Properties {
+			e.add(joinKey(key, property), child)
+		}
+	case s.scalar(), s.Type == "array" && s.Items.scalar():
+		e.keys[name] = envKey{key: key, schema: s}
+	}
+}
+
+// lookup returns the config key of the variable and its parsed value. It
+// returns an empty key if the variable doesn't set a key.
+func (e envKeys) lookup(name, value string) (string, any, error) {
+	k, ok := e.keys[name]
+	if !ok {
+		for prefix, m := range e.maps {
+			if entry, found := strings.CutPrefix(name, prefix); found && entry != "" {
+				k, ok = envKey{key: m.key + "." + strings.ToLower(entry), schema: m.schema}, true
+				break
+			}
+		}
+	}
+	if !ok {
+		return "", nil, nil
+	}
+
+	v, err := k.schema.parse(value)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid value of %s: %w", name, err)
+	}
+
+	return k.key, v, nil
+}
+
+func (s *Schema) scalar() bool {
+	switch s.Type {
+	case "string", "integer", "number", "boolean":
+		return true
+	default:
+		return false
+	}
+}
+
+// parse parses the value of an environment variable. The items of lists are
+// separated by commas.
+func (s *Schema) parse(value string) (any, error) {
+	switch s.Type {
+	case "integer":
+		return strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+	case "number":
+		return strconv.ParseFloat(strings.TrimSpace(value), 64)
+	case "boolean":
+		return strconv.ParseBool(strings.TrimSpace(value))
+	case "array":
+		var items []any
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item == "" {
+				continue
+			}
+			v, err := s.Items.parse(item)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, v)
+		}
+		return items, nil
+	default:
+		return value, nil
+	}
+}
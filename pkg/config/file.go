@@ -0,0 +1,351 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+
+	"github.com/zkhvan/z/pkg/cmdutil"
+	"github.com/zkhvan/z/pkg/fcache"
+)
+
+// fileBase is the base name of the config file, e.g. "config.yaml".
+const fileBase = "config"
+
+// File is the config file, edited as a YAML document so its comments and
+// ordering are kept. TOML and JSON files are converted, keeping the ordering
+// but not the comments of TOML files, which aren't rewritten unless forced.
+// Keys are paths separated by dots, e.g. "projects.remote_patterns".
+type File struct {
+	path   string
+	format format
+	doc    *yaml.Node
+
+	// raw is the content of the file, written as is by Save unless the
+	// document was edited.
+	raw    []byte
+	edited bool
+
+	// comments is set if the file has comments which are lost when it's
+	// rewritten, and force if they may be.
+	comments bool
+	force    bool
+}
+
+// OpenFile opens the config file of the directory, or of the default config
+// directory if empty, in any format. A missing file is opened empty as
+// config.yaml, and created on Save.
+func OpenFile(dir string) (*File, error) {
+	if dir == "" {
+		configDir, err := configDir()
+		if err != nil {
+			return nil, err
+		}
+		dir = configDir
+	}
+
+	path, ok, err := findFile(dir, fileBase)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		path = filepath.Join(dir, fileBase+".yaml")
+	}
+
+	return OpenPath(path)
+}
+
+// OpenPath opens the config file of the path, whose format is detected by
+// its extension. A missing file is opened empty, and created on Save.
+func OpenPath(path string) (*File, error) {
+	f := &File{path: path, format: formatOf(path)}
+
+	b, err := os.ReadFile(f.path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+
+	if err := f.SetBytes(b); err != nil {
+		return nil, err
+	}
+
+	return f, nil
+}
+
+// Path returns the path of the config file.
+func (f *File) Path() string {
+	return f.path
+}
+
+// SetBytes replaces the content of the config file, which must be valid in
+// the format of the file.
+func (f *File) SetBytes(b []byte) error {
+	doc, err := f.format.decode(b)
+	if err != nil {
+		return fmt.Errorf("error parsing %q: %w", f.path, err)
+	}
+
+	if doc.Kind == 0 {
+		doc = &yaml.Node{Kind: yaml.DocumentNode}
+	}
+	if len(doc.Content) == 0 {
+		doc.Content = []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}
+	}
+	if doc.Content[0].Kind != yaml.MappingNode {
+		return fmt.Errorf("error parsing %q: the config must be a mapping", f.path)
+	}
+
+	f.doc = doc
+	f.raw = b
+	f.edited = false
+	f.comments = f.format.hasComments != nil && f.format.hasComments(b)
+	return nil
+}
+
+// SetForce sets whether Save may rewrite a file whose comments are lost.
+func (f *File) SetForce(force bool) {
+	f.force = force
+}
+
+// CheckComments returns ErrCommentsLost if saving the file would drop its
+// comments, unless forced.
+func (f *File) CheckComments() error {
+	if f.edited && f.comments && !f.force {
+		return fmt.Errorf("%w: %q", ErrCommentsLost, f.path)
+	}
+
+	return nil
+}
+
+// Config returns the config of the file.
+func (f *File) Config() (cmdutil.Config, error) {
+	b, err := f.Bytes()
+	if err != nil {
+		return nil, err
+	}
+
+	return parse(b, f.format.parser)
+}
+
+// Validate returns the problems of the config file reported by the schema.
+func (f *File) Validate(s *Schema) []Problem {
+	return s.validateDoc(f.doc)
+}
+
+// Set sets the key to the value, parsed as YAML, e.g. "3600" is a number and
+// "[a, b]" a list.
+func (f *File) Set(key, value string) error {
+	node, err := parseValue(value)
+	if err != nil {
+		return fmt.Errorf("error parsing the value of %q: %w", key, err)
+	}
+
+	parent, name, err := f.parent(key, true)
+	if err != nil {
+		return err
+	}
+	f.edited = true
+
+	if i := indexOf(parent, name); i >= 0 {
+		// Keep the comments of the replaced value.
+		old := parent.Content[i+1]
+		node.LineComment = old.LineComment
+		node.HeadComment = old.HeadComment
+		node.FootComment = old.FootComment
+		parent.Content[i+1] = node
+		return nil
+	}
+
+	parent.Content = append(parent.Content, scalar(name), node)
+	return nil
+}
+
+// Unset removes the key. It returns ErrNotFound if the key isn't set.
+func (f *File) Unset(key string) error {
+	parent, name, err := f.parent(key, false)
+	if err != nil {
+		return err
+	}
+
+	i := indexOf(parent, name)
+	if i < 0 {
+		return fmt.Errorf("%w: %q", ErrNotFound, key)
+	}
+
+	parent.Content = slices.Delete(parent.Content, i, i+2)
+	f.edited = true
+	return nil
+}
+
+// Add appends the values to the list of the key, which is created if
+// missing. The values are strings, and values already in the list are
+// skipped.
+func (f *File) Add(key string, values ...string) error {
+	list, err := f.list(key, true)
+	if err != nil {
+		return err
+	}
+	f.edited = true
+
+	for _, v := range values {
+		if !slices.ContainsFunc(list.Content, func(n *yaml.Node) bool { return n.Value == v }) {
+			list.Content = append(list.Content, scalar(v))
+		}
+	}
+
+	return nil
+}
+
+// Remove removes the values from the list of the key. It returns ErrNotFound,
+// leaving the list as is, if a value isn't in the list.
+func (f *File) Remove(key string, values ...string) error {
+	list, err := f.list(key, false)
+	if err != nil {
+		return err
+	}
+
+	content := slices.Clone(list.Content)
+	for _, v := range values {
+		i := slices.IndexFunc(content, func(n *yaml.Node) bool { return n.Value == v })
+		if i < 0 {
+			return fmt.Errorf("%w: %q in %q", ErrNotFound, v, key)
+		}
+		content = slices.Delete(content, i, i+1)
+	}
+
+	list.Content = content
+	f.edited = true
+	return nil
+}
+
+// Bytes returns the content of the config file.
+func (f *File) Bytes() ([]byte, error) {
+	if !f.edited {
+		return f.raw, nil
+	}
+
+	return f.format.encode(f.doc)
+}
+
+// Save writes the config file. It returns ErrCommentsLost if the comments
+// of the file would be dropped, unless forced.
+func (f *File) Save() error {
+	if err := f.CheckComments(); err != nil {
+		return err
+	}
+
+	b, err := f.Bytes()
+	if err != nil {
+		return fmt.Errorf("error encoding %q: %w", f.path, err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(f.path), 0o755); err != nil {
+		return fmt.Errorf("error creating the config directory: %w", err)
+	}
+
+	return fcache.WriteFile(f.path, b, 0o644)
+}
+
+// parent returns the mapping holding the last segment of the key, and that
+// segment. The missing mappings are created if create is set.
+func (f *File) parent(key string, create bool) (*yaml.Node, string, error) {
+	segments := strings.Split(key, ".")
+	if slices.Contains(segments, "") {
+		return nil, "", fmt.Errorf("invalid key %q", key)
+	}
+
+	node := f.doc.Content[0]
+	for i, segment := range segments[:len(segments)-1] {
+		j := indexOf(node, segment)
+		if j < 0 {
+			if !create {
+				return nil, "", fmt.Errorf("%w: %q", ErrNotFound, key)
+			}
+			child := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+			node.Content = append(node.Content, scalar(segment), child)
+			node = child
+			continue
+		}
+
+		node = node.Content[j+1]
+		if node.Kind != yaml.MappingNode {
+			return nil, "", fmt.Errorf("%q isn't a mapping", strings.Join(segments[:i+1], "."))
+		}
+	}
+
+	return node, segments[len(segments)-1], nil
+}
+
+// list returns the list of the key. A missing list is created if create is
+// set.
+func (f *File) list(key string, create bool) (*yaml.Node, error) {
+	parent, name, err := f.parent(key, create)
+	if err != nil {
+		return nil, err
+	}
+
+	i := indexOf(parent, name)
+	if i < 0 {
+		if !create {
+			return nil, fmt.Errorf("%w: %q", ErrNotFound, key)
+		}
+		list := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		parent.Content = append(parent.Content, scalar(name), list)
+		return list, nil
+	}
+
+	list := parent.Content[i+1]
+	if list.Kind != yaml.SequenceNode {
+		return nil, fmt.Errorf("%q isn't a list", key)
+	}
+
+	return list, nil
+}
+
+// indexOf returns the index of the key of the mapping, or -1.
+func indexOf(mapping *yaml.Node, key string) int {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func scalar(value string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+}
+
+func parseValue(value string) (*yaml.Node, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(value), &doc); err != nil {
+		return nil, err
+	}
+
+	if len(doc.Content) == 0 {
+		return scalar(value), nil
+	}
+
+	node := doc.Content[0]
+	// Lists and mappings are written in block style, like the rest of the
+	// file.
+	resetStyle(node)
+
+	return node, nil
+}
+
+func resetStyle(node *yaml.Node) {
+	if node.Kind != yaml.ScalarNode {
+		node.Style = 0
+	}
+	for _, child := range node.Content {
+		resetStyle(child)
+	}
+}
@@ -0,0 +1,386 @@
+package config_test
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/MakeNowJust/heredoc/v2"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/zkhvan/z/pkg/assert"
+	"github.com/zkhvan/z/pkg/config"
+)
+
+func TestFile(t *testing.T) {
+	original := heredoc.Doc(`
+		# My config
+		projects:
+		  # Where the projects are
+		  root: ~/Projects
+		  remote_patterns:
+		    - my-org/* # work
+		    - cli/cli
+	`)
+
+	tests := map[string]struct {
+		edit     func(f *config.File) error
+		expected string
+		err      error
+	}{
+		"set keeps comments": {
+			edit: func(f *config.File) error {
+				return f.Set("projects.root", "~/src")
+			},
+			expected: heredoc.Doc(`
+				# My config
+				projects:
+				  # Where the projects are
+				  root: ~/src
+				  remote_patterns:
+				    - my-org/* # work
+				    - cli/cli
+			`),
+		},
+		"set new nested key": {
+			edit: func(f *config.File) error {
+				return f.Set("tmux.popup.width", "80")
+			},
+			expected: original + heredoc.Doc(`
+				tmux:
+				  popup:
+				    width: 80
+			`),
+		},
+		"set mapping": {
+			edit: func(f *config.File) error {
+				return f.Set("projects.aliases", "{gh: cli/cli}")
+			},
+			expected: original + "  aliases:\n    gh: cli/cli\n",
+		},
+		"unset": {
+			edit: func(f *config.File) error {
+				return f.Unset("projects.remote_patterns")
+			},
+			expected: heredoc.Doc(`
+				# My config
+				projects:
+				  # Where the projects are
+				  root: ~/Projects
+			`),
+		},
+		"unset missing key": {
+			edit: func(f *config.File) error {
+				return f.Unset("projects.ttl")
+			},
+			err: config.ErrNotFound,
+		},
+		"add skips existing values": {
+			edit: func(f *config.File) error {
+				return f.Add("projects.remote_patterns", "cli/cli", "other/* -> ./other/")
+			},
+			expected: heredoc.Doc(`
+				# My config
+				projects:
+				  # Where the projects are
+				  root: ~/Projects
+				  remote_patterns:
+				    - my-org/* # work
+				    - cli/cli
+				    - other/* -> ./other/
+			`),
+		},
+		"remove": {
+			edit: func(f *config.File) error {
+				return f.Remove("projects.remote_patterns", "my-org/*")
+			},
+			expected: heredoc.Doc(`
+				# My config
+				projects:
+				  # Where the projects are
+				  root: ~/Projects
+				  remote_patterns:
+				    - cli/cli
+			`),
+		},
+		"remove missing value": {
+			edit: func(f *config.File) error {
+				return f.Remove("projects.remote_patterns", "nope/*")
+			},
+			err: config.ErrNotFound,
+		},
+		"remove present and missing values": {
+			edit: func(f *config.File) error {
+				return f.Remove("projects.remote_patterns", "my-org/*", "nope/*")
+			},
+			err: config.ErrNotFound,
+		},
+		"add to a scalar": {
+			edit: func(f *config.File) error {
+				return f.Add("projects.root", "x")
+			},
+			err: errors.New(`"projects.root" isn't a list`),
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, "config.yaml")
+			assert.NoError(t, os.WriteFile(path, []byte(original), 0o600))
+
+			f, err := config.OpenFile(dir)
+			assert.NoError(t, err)
+
+			err = tc.edit(f)
+			if tc.err != nil {
+				if err == nil || (!errors.Is(err, tc.err) && err.Error() != tc.err.Error()) {
+					t.Fatalf("expected error %v, got %v", tc.err, err)
+				}
+
+				// A failed edit leaves the file as is.
+				b, err := f.Bytes()
+				assert.NoError(t, err)
+				assert.EqualString(t, string(b), original)
+				return
+			}
+			assert.NoError(t, err)
+			assert.NoError(t, f.Save())
+
+			b, err := os.ReadFile(path)
+			assert.NoError(t, err)
+			assert.EqualString(t, tc.expected, string(b))
+
+			// The saved file is read back by the config.
+			_, err = config.NewWithDir(dir)
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestFile_Formats(t *testing.T) {
+	tests := map[string]struct {
+		name     string
+		original string
+		expected string
+	}{
+		"toml": {
+			name: "config.toml",
+			original: heredoc.Doc(`
+				[projects]
+				root = "~/Projects"
+				remote_patterns = ["my-org/*"]
+
+				[projects.aliases]
+				z = "zkhvan/z"
+			`),
+			expected: heredoc.Doc(`
+				[projects]
+				root = "~/Projects"
+				remote_patterns = ["my-org/*", "cli/cli"]
+				ttl = 60
+
+				[projects.aliases]
+				z = "zkhvan/z"
+			`),
+		},
+		"json": {
+			name:     "config.json",
+			original: `{"projects": {"root": "~/Projects", "remote_patterns": ["my-org/*"]}}`,
+			expected: heredoc.Doc(`
+				{
+				  "projects": {
+				    "root": "~/Projects",
+				    "remote_patterns": [
+				      "my-org/*",
+				      "cli/cli"
+				    ],
+				    "ttl": 60
+				  }
+				}
+			`),
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, tc.name)
+			assert.NoError(t, os.WriteFile(path, []byte(tc.original), 0o600))
+
+			f, err := config.OpenFile(dir)
+			assert.NoError(t, err)
+			assert.EqualString(t, f.Path(), path)
+
+			assert.NoError(t, f.Add("projects.remote_patterns", "cli/cli"))
+			assert.NoError(t, f.Set("projects.ttl", "60"))
+			assert.NoError(t, f.Save())
+
+			b, err := os.ReadFile(path)
+			assert.NoError(t, err)
+			assert.EqualString(t, string(b), tc.expected)
+
+			cfg, err := config.NewWithDir(dir)
+			assert.NoError(t, err)
+			assert.EqualString(t, cfg.String("projects.root"), "~/Projects")
+		})
+	}
+}
+
+func TestFile_SeveralFormats(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"config.yaml", "config.toml"} {
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, name), nil, 0o600))
+	}
+
+	_, err := config.OpenFile(dir)
+	if err == nil {
+		t.Fatal("expected an error for several config files")
+	}
+
+	_, err = config.NewWithDir(dir)
+	if err == nil {
+		t.Fatal("expected an error for several config files")
+	}
+}
+
+func TestFile_ValidateTOML(t *testing.T) {
+	dir := t.TempDir()
+	content := "[projects]\nroot = \"~/Projects\"\nremotePatterns = [\"my-org/*\"]\n"
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "config.toml"), []byte(content), 0o600))
+
+	f, err := config.OpenFile(dir)
+	assert.NoError(t, err)
+
+	problems := f.Validate(config.NewSchema(config.Section{Key: "projects", Value: testDefaults{}}))
+	expected := []config.Problem{{
+		Line:    3,
+		Column:  1,
+		Key:     "projects.remotePatterns",
+		Message: `unknown key "remotePatterns", did you mean "remote_patterns"?`,
+	}}
+	if diff := cmp.Diff(expected, problems); diff != "" {
+		t.Errorf("Validate() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestFile_TOMLComments(t *testing.T) {
+	tests := map[string]struct {
+		content  string
+		force    bool
+		expected error
+	}{
+		"without comments": {
+			content: "[projects]\nroot = \"~/Projects\"\n",
+		},
+		"with comments": {
+			content:  "[projects]\n# The root.\nroot = \"~/Projects\"\n",
+			expected: config.ErrCommentsLost,
+		},
+		"with a trailing comment": {
+			content:  "[projects]\nroot = \"~/Projects\" # The root.\n",
+			expected: config.ErrCommentsLost,
+		},
+		"with comments in an array": {
+			content:  "[projects]\nremote_patterns = [\n  # Work.\n  \"my-org/*\",\n]\n",
+			expected: config.ErrCommentsLost,
+		},
+		"forced": {
+			content: "[projects]\n# The root.\nroot = \"~/Projects\"\n",
+			force:   true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, "config.toml")
+			assert.NoError(t, os.WriteFile(path, []byte(tc.content), 0o600))
+
+			f, err := config.OpenFile(dir)
+			assert.NoError(t, err)
+			f.SetForce(tc.force)
+
+			// Saving an unchanged file keeps it as is.
+			assert.NoError(t, f.Save())
+
+			assert.NoError(t, f.Set("projects.ttl", "60"))
+			err = f.Save()
+			if !errors.Is(err, tc.expected) || (tc.expected == nil && err != nil) {
+				t.Fatalf("Save() error = %v, want %v", err, tc.expected)
+			}
+
+			b, err := os.ReadFile(path)
+			assert.NoError(t, err)
+			if tc.expected != nil {
+				assert.EqualString(t, string(b), tc.content)
+			}
+		})
+	}
+}
+
+func TestFile_TOMLLayout(t *testing.T) {
+	dir := t.TempDir()
+	content := heredoc.Doc(`
+		name = "z"
+		projects.root = "~/Projects"
+
+		[[profiles]]
+		name = "work"
+		ttl = 60
+
+		[[profiles]]
+		name = "home"
+		remotePatterns = ["zkhvan/*"]
+
+		[nested]
+		inline = { b = 1, a = 2.5 }
+	`)
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "config.toml"), []byte(content), 0o600))
+
+	f, err := config.OpenFile(dir)
+	assert.NoError(t, err)
+
+	type inline struct {
+		A float64 `json:"a"`
+	}
+	problems := f.Validate(config.NewSchema(config.Section{Key: "nested", Value: struct {
+		Inline inline `json:"inline"`
+	}{}}))
+	var keys []string
+	for _, p := range problems {
+		if strings.HasPrefix(p.Key, "nested.") {
+			keys = append(keys, fmt.Sprintf("%d:%s", p.Line, p.Key))
+		}
+	}
+	if diff := cmp.Diff([]string{"13:nested.inline.b"}, keys); diff != "" {
+		t.Errorf("Validate() mismatch (-want +got):\n%s", diff)
+	}
+
+	assert.NoError(t, f.Set("name", "zz"))
+	assert.NoError(t, f.Save())
+
+	b, err := os.ReadFile(filepath.Join(dir, "config.toml"))
+	assert.NoError(t, err)
+	assert.EqualString(t, string(b), heredoc.Doc(`
+		name = "zz"
+
+		[projects]
+		root = "~/Projects"
+
+		[[profiles]]
+		name = "work"
+		ttl = 60
+
+		[[profiles]]
+		name = "home"
+		remotePatterns = ["zkhvan/*"]
+
+		[nested.inline]
+		b = 1
+		a = 2.5
+	`))
+}
@@ -0,0 +1,195 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	koanfjson "github.com/knadh/koanf/parsers/json"
+	koanftoml "github.com/knadh/koanf/parsers/toml/v2"
+	koanfyaml "github.com/knadh/koanf/parsers/yaml"
+	"github.com/knadh/koanf/v2"
+	"go.yaml.in/yaml/v3"
+)
+
+// format is the format of a config file, detected by its extension. Config
+// files are edited as YAML documents, converted from and to their format.
+type format struct {
+	name   string
+	exts   []string
+	parser koanf.Parser
+
+	// decode returns the document of the content of a file.
+	decode func(b []byte) (*yaml.Node, error)
+	// encode returns the content of a file of the document.
+	encode func(doc *yaml.Node) ([]byte, error)
+	// hasComments reports whether the content of a file has comments which
+	// encode drops. It's nil if encode keeps them.
+	hasComments func(b []byte) bool
+}
+
+var (
+	yamlFormat = format{
+		name:   "YAML",
+		exts:   []string{".yaml", ".yml"},
+		parser: koanfyaml.Parser(),
+		decode: decodeYAML,
+		encode: encodeYAML,
+	}
+	tomlFormat = format{
+		name:        "TOML",
+		exts:        []string{".toml"},
+		parser:      koanftoml.Parser(),
+		decode:      decodeTOML,
+		encode:      encodeTOML,
+		hasComments: hasTOMLComments,
+	}
+	jsonFormat = format{
+		name:   "JSON",
+		exts:   []string{".json"},
+		parser: koanfjson.Parser(),
+		// JSON is valid YAML.
+		decode: decodeYAML,
+		encode: encodeJSON,
+	}
+
+	formats = []format{yamlFormat, tomlFormat, jsonFormat}
+)
+
+// formatOf returns the format of the file, YAML unless its extension is of
+// another format.
+func formatOf(path string) format {
+	ext := strings.ToLower(filepath.Ext(path))
+	for _, f := range formats {
+		if slices.Contains(f.exts, ext) {
+			return f
+		}
+	}
+
+	return yamlFormat
+}
+
+// findFile returns the config file of the directory with the base name, in
+// any format, e.g. "config.yaml" or "config.toml". It's an error if there
+// are several.
+func findFile(dir, base string) (string, bool, error) {
+	var found []string
+	for _, f := range formats {
+		for _, ext := range f.exts {
+			path := filepath.Join(dir, base+ext)
+			if info, err := os.Stat(path); err == nil && !info.IsDir() {
+				found = append(found, path)
+			} else if err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return "", false, err
+			}
+		}
+	}
+
+	switch len(found) {
+	case 0:
+		return "", false, nil
+	case 1:
+		return found[0], true, nil
+	default:
+		return "", false, fmt.Errorf("found several config files, keep only one: %s", strings.Join(found, ", "))
+	}
+}
+
+func decodeYAML(b []byte) (*yaml.Node, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(b, &doc); err != nil {
+		return nil, err
+	}
+
+	return &doc, nil
+}
+
+func encodeYAML(doc *yaml.Node) ([]byte, error) {
+	root := doc.Content[0]
+	if len(root.Content) == 0 && root.HeadComment == "" && root.FootComment == "" {
+		return nil, nil
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(doc); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// encodeJSON encodes the document, keeping the order of the keys.
+func encodeJSON(doc *yaml.Node) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := writeJSON(&buf, doc); err != nil {
+		return nil, err
+	}
+
+	var out bytes.Buffer
+	if err := json.Indent(&out, buf.Bytes(), "", "  "); err != nil {
+		return nil, err
+	}
+	out.WriteByte('\n')
+
+	return out.Bytes(), nil
+}
+
+func writeJSON(buf *bytes.Buffer, node *yaml.Node) error {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		return writeJSON(buf, node.Content[0])
+	case yaml.AliasNode:
+		return writeJSON(buf, node.Alias)
+	case yaml.MappingNode:
+		buf.WriteByte('{')
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			key, err := json.Marshal(node.Content[i].Value)
+			if err != nil {
+				return err
+			}
+			buf.Write(key)
+			buf.WriteByte(':')
+			if err := writeJSON(buf, node.Content[i+1]); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	case yaml.SequenceNode:
+		buf.WriteByte('[')
+		for i, item := range node.Content {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeJSON(buf, item); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	default:
+		var v any
+		if err := node.Decode(&v); err != nil {
+			return err
+		}
+		b, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		buf.Write(b)
+	}
+
+	return nil
+}
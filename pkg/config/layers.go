@@ -0,0 +1,337 @@
+package config
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/knadh/koanf/providers/confmap"
+	"github.com/knadh/koanf/providers/env"
+	"github.com/knadh/koanf/v2"
+
+	"github.com/zkhvan/z/pkg/cmdutil"
+	"github.com/zkhvan/z/pkg/dirs"
+	"github.com/zkhvan/z/pkg/oslib"
+)
+
+// projectFileBase is the base name of the project config file, e.g.
+// ".z.yaml", overriding the user config file in a directory and its
+// subdirectories.
+const projectFileBase = ".z"
+
+// includeKey lists the files included by a config file.
+const includeKey = "include"
+
+// Options configures the layers of the config.
+type Options struct {
+	// Dir is the directory of the user config file, defaulting to dirs.Config.
+	Dir string
+
+	// SystemDir is the directory of the system-wide config file, defaulting
+	// to dirs.System.
+	SystemDir string
+
+	// WorkDir is the directory where the search of the project config file
+	// starts, defaulting to the working directory.
+	WorkDir string
+
+	// Profile is the profile to use, see UseProfile.
+	Profile string
+
+	// Sections provide the defaults, and the keys which can be set by
+	// environment variables.
+	Sections []Section
+}
+
+// Load loads the config from the following layers, each overriding the
+// previous ones:
+//
+//   - the defaults of the sections
+//   - the system-wide config file, e.g. /etc/z/config.yaml
+//   - the user config file, e.g. ~/.config/z/config.yaml
+//   - the project config file, the first .z.yaml found from the working
+//     directory up to the root
+//   - the profile, see UseProfile
+//   - the environment variables prefixed with Z_, e.g. Z_PROJECTS_ROOT
+//
+// Each config file is preceded by the files it includes.
+//
+// The deprecated keys of the config files are migrated, see File.Migrate.
+// They, the environment variables with invalid values and a missing profile
+// are returned as an error along with the config.
+func Load(opts Options) (cmdutil.Config, error) {
+	p := &provider{workDir: opts.WorkDir}
+
+	if p.workDir == "" {
+		wd, err := os.Getwd()
+		if err != nil {
+			return nil, fmt.Errorf("error getting the working directory: %w", err)
+		}
+		p.workDir = wd
+	}
+
+	defaults, err := sectionDefaults(opts.Sections)
+	if err != nil {
+		return nil, err
+	}
+	l, err := newLayer(constOrigin("default"), confmap.Provider(defaults, "."), nil)
+	if err != nil {
+		return nil, err
+	}
+	p.layers = append(p.layers, l)
+
+	if opts.Dir == "" {
+		configDir, err := configDir()
+		if err != nil {
+			return nil, err
+		}
+		opts.Dir = configDir
+	}
+
+	var paths []string
+	for _, dir := range []string{cmp.Or(opts.SystemDir, dirs.System()), opts.Dir} {
+		path, ok, err := findFile(dir, fileBase)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			paths = append(paths, path)
+		}
+	}
+
+	path, ok, err := findProjectFile(p.workDir)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		paths = append(paths, path)
+	}
+
+	files := fileLoader{schema: NewSchema(opts.Sections...)}
+	for _, path := range paths {
+		layers, err := files.load(path, nil)
+		if err != nil {
+			return nil, err
+		}
+		p.layers = append(p.layers, layers...)
+	}
+
+	p.env, err = newLayer(constOrigin("env"), confmap.Provider(nil, "."), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	envErr := p.loadEnv(sectionsSchema(opts.Sections))
+	profileErr := p.useProfile(opts.Profile)
+
+	return p, errors.Join(append(files.deprecations, envErr, profileErr)...)
+}
+
+// layer is a source of the config, e.g. a config file.
+type layer struct {
+	k *koanf.Koanf
+	// origin returns the origin of the keys of the layer.
+	origin func(key string) string
+}
+
+func newLayer(origin func(key string) string, pr koanf.Provider, parser koanf.Parser) (layer, error) {
+	k := koanf.New(".")
+	if err := k.Load(pr, parser); err != nil {
+		return layer{}, fmt.Errorf("error loading %s: %w", origin(""), err)
+	}
+
+	return layer{k: k, origin: origin}, nil
+}
+
+// merge merges a layer into the config, recording the origin of its keys.
+func (p *provider) merge(l layer) error {
+	for _, key := range l.k.Keys() {
+		p.origins[key] = l.origin(key)
+	}
+
+	return p.k.Merge(l.k)
+}
+
+// fileLoader loads the config files, migrating their deprecated keys.
+type fileLoader struct {
+	schema       *Schema
+	deprecations []error
+}
+
+// load loads the config file, preceded by the files it includes. The
+// included paths are relative to the file, and the missing ones are skipped,
+// e.g. when a shared repository isn't cloned yet.
+func (fl *fileLoader) load(path string, including []string) ([]layer, error) {
+	if slices.Contains(including, path) {
+		return nil, fmt.Errorf("error loading %s: include cycle", path)
+	}
+	including = append(including, path)
+
+	f, err := OpenPath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if changes := f.Migrate(fl.schema); len(changes) > 0 {
+		deprecation := &DeprecationError{Path: path, Changes: changes}
+		if info, err := os.Stat(path); err == nil {
+			deprecation.ModTime = info.ModTime()
+		}
+		fl.deprecations = append(fl.deprecations, deprecation)
+	}
+
+	var values map[string]any
+	if err := f.doc.Decode(&values); err != nil {
+		return nil, fmt.Errorf("error decoding %s: %w", path, err)
+	}
+
+	l, err := newLayer(constOrigin("file:"+path), confmap.Provider(values, ""), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var layers []layer
+	for _, include := range l.k.Strings(includeKey) {
+		include = oslib.Expand(include)
+		if !filepath.IsAbs(include) {
+			include = filepath.Join(filepath.Dir(path), include)
+		}
+
+		if _, err := os.Stat(include); errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+
+		included, err := fl.load(include, including)
+		if err != nil {
+			return nil, err
+		}
+		layers = append(layers, included...)
+	}
+	l.k.Delete(includeKey)
+
+	return append(layers, l), nil
+}
+
+// loadEnv loads the keys set by environment variables.
+func (p *provider) loadEnv(schema *Schema) error {
+	keys := newEnvKeys(schema)
+	names := make(map[string]string)
+
+	var errs []error
+	pr := env.ProviderWithValue(EnvPrefix, ".", func(name, value string) (string, any) {
+		key, v, err := keys.lookup(name, value)
+		if err != nil {
+			errs = append(errs, err)
+			return "", nil
+		}
+		names[key] = name
+		return key, v
+	})
+
+	origin := func(key string) string {
+		if name, ok := names[key]; ok {
+			return "env:" + name
+		}
+		return "env"
+	}
+
+	l, err := newLayer(origin, pr, nil)
+	if err != nil {
+		return err
+	}
+	p.env = l
+
+	return errors.Join(errs...)
+}
+
+func constOrigin(origin string) func(string) string {
+	return func(string) string { return origin }
+}
+
+// findProjectFile returns the first project config file found from the
+// directory up to the root.
+func findProjectFile(dir string) (string, bool, error) {
+	for {
+		path, ok, err := findFile(dir, projectFileBase)
+		if err != nil || ok {
+			return path, ok, err
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false, nil
+		}
+		dir = parent
+	}
+}
+
+// sectionDefaults returns the non-zero values of the sections, keyed by the
+// sections.
+func sectionDefaults(sections []Section) (map[string]any, error) {
+	defaults := make(map[string]any)
+
+	for _, section := range sections {
+		b, err := json.Marshal(section.Value)
+		if err != nil {
+			return nil, fmt.Errorf("error encoding the defaults of %q: %w", section.Key, err)
+		}
+
+		var values map[string]any
+		if err := json.Unmarshal(b, &values); err != nil {
+			return nil, fmt.Errorf("error decoding the defaults of %q: %w", section.Key, err)
+		}
+
+		if values = pruneZero(values); len(values) > 0 {
+			defaults[section.Key] = values
+		}
+	}
+
+	return defaults, nil
+}
+
+func pruneZero(values map[string]any) map[string]any {
+	for key, value := range values {
+		switch v := value.(type) {
+		case map[string]any:
+			if v = pruneZero(v); len(v) > 0 {
+				values[key] = v
+				continue
+			}
+		case []any:
+			if len(v) > 0 {
+				continue
+			}
+		default:
+			if v != nil && !reflect.ValueOf(v).IsZero() {
+				continue
+			}
+		}
+		delete(values, key)
+	}
+
+	return values
+}
+
+// Origins returns the origin of each key of the config, e.g. "default",
+// "file:/etc/z/config.yaml" or "env:Z_PROJECTS_ROOT". It returns nil if the
+// config wasn't loaded by Load.
+func Origins(cfg cmdutil.Config) map[string]string {
+	p, ok := cfg.(*provider)
+	if !ok {
+		return nil
+	}
+
+	return p.origins
+}
+
+// hasPrefix reports whether the key is the prefix or one of its children.
+func hasPrefix(key, prefix string) bool {
+	return key == prefix || strings.HasPrefix(key, prefix+".")
+}
@@ -0,0 +1,210 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"go.yaml.in/yaml/v3"
+
+	"github.com/zkhvan/z/pkg/dirs"
+)
+
+// Change is a deprecated use of a config file, rewritten by a migration.
+type Change struct {
+	Line    int
+	Key     string
+	Message string
+}
+
+func (c Change) String() string {
+	return fmt.Sprintf("line %d: %s: %s", c.Line, c.Key, c.Message)
+}
+
+// migration rewrites a deprecated use of the config files in the value of a
+// key, whose schema is s.
+type migration func(s *Schema, node *yaml.Node, key string) []Change
+
+// migrations are applied in order, to each value of the config files. Add
+// one when a key or its shape changes, so the config files keep working and
+// can be updated with "z config migrate".
+var migrations = []migration{
+	renameCamelCaseKeys,
+	wrapSingleValues,
+	rewriteStrings,
+}
+
+// renameCamelCaseKeys renames the keys written in camel case, or with dashes,
+// to the snake case keys of the schema, e.g. "remotePatterns" to
+// "remote_patterns".
+func renameCamelCaseKeys(s *Schema, node *yaml.Node, key string) []Change {
+	if s.Type != "object" || s.Values != nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+
+	var changes []Change
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		name := node.Content[i]
+		if s.Properties[name.Value] != nil {
+			continue
+		}
+
+		suggestion := s.suggest(name.Value)
+		if suggestion == "" || indexOf(node, suggestion) >= 0 {
+			continue
+		}
+
+		changes = append(changes, Change{
+			Line:    name.Line,
+			Key:     joinKey(key, name.Value),
+			Message: fmt.Sprintf("renamed to %q", suggestion),
+		})
+		name.Value = suggestion
+	}
+
+	return changes
+}
+
+// wrapSingleValues replaces the single values of lists by lists, e.g.
+// "remote_patterns: my-org/*" by "remote_patterns: [my-org/*]".
+func wrapSingleValues(s *Schema, node *yaml.Node, key string) []Change {
+	if s.Type != "array" || !s.Items.scalar() || node.Kind != yaml.ScalarNode || node.Tag == "!!null" {
+		return nil
+	}
+
+	item := *node
+	item.HeadComment, item.FootComment = "", ""
+	*node = yaml.Node{
+		Kind:        yaml.SequenceNode,
+		Tag:         "!!seq",
+		Content:     []*yaml.Node{&item},
+		Line:        node.Line,
+		Column:      node.Column,
+		HeadComment: node.HeadComment,
+		FootComment: node.FootComment,
+	}
+
+	return []Change{{
+		Line:    node.Line,
+		Key:     key,
+		Message: "converted to a list",
+	}}
+}
+
+// rewriteStrings rewrites the deprecated strings with the migrations of the
+// sections, e.g. the remote pattern "cli/cli -> ./oss/" to
+// "cli/cli -> ./oss/*".
+func rewriteStrings(s *Schema, node *yaml.Node, key string) []Change {
+	if s.rewrite == nil || node.Kind != yaml.ScalarNode || node.Tag != "!!str" {
+		return nil
+	}
+
+	value, ok := s.rewrite(node.Value)
+	if !ok {
+		return nil
+	}
+	node.Value = value
+
+	return []Change{{
+		Line:    node.Line,
+		Key:     key,
+		Message: fmt.Sprintf("rewritten to %q", value),
+	}}
+}
+
+// migrate applies the migrations to the node and its children.
+func (s *Schema) migrate(node *yaml.Node, key string) []Change {
+	var changes []Change
+	for _, m := range migrations {
+		changes = append(changes, m(s, node, key)...)
+	}
+
+	switch {
+	case s.Type == "object" && node.Kind == yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			name := node.Content[i].Value
+
+			property := s.Values
+			if property == nil {
+				property = s.Properties[name]
+			}
+			if property != nil {
+				changes = append(changes, property.migrate(node.Content[i+1], joinKey(key, name))...)
+			}
+		}
+	case s.Type == "array" && node.Kind == yaml.SequenceNode:
+		for i, item := range node.Content {
+			changes = append(changes, s.Items.migrate(item, fmt.Sprintf("%s[%d]", key, i))...)
+		}
+	}
+
+	return changes
+}
+
+// Migrate rewrites the deprecated uses of the config file, and returns them.
+func (f *File) Migrate(s *Schema) []Change {
+	changes := s.migrate(f.doc.Content[0], "")
+	if len(changes) > 0 {
+		f.edited = true
+	}
+
+	return changes
+}
+
+// DeprecationError reports the deprecated uses of a config file, which are
+// migrated when the config is loaded.
+type DeprecationError struct {
+	Path    string
+	ModTime time.Time
+	Changes []Change
+}
+
+func (e *DeprecationError) Error() string {
+	keys := make([]string, 0, len(e.Changes))
+	for _, c := range e.Changes {
+		keys = append(keys, c.Key)
+	}
+
+	return fmt.Sprintf(
+		"%s uses deprecated settings (%s), update it with \"z config migrate %s\"",
+		e.Path,
+		strings.Join(slices.Compact(keys), ", "),
+		e.Path,
+	)
+}
+
+// FirstWarning reports whether the deprecations of this version of the file,
+// identified by its path and modification time, weren't reported yet, and
+// records that they are. It's true if the record fails, so the warning isn't
+// lost.
+func (e *DeprecationError) FirstWarning() bool {
+	stateDir, err := dirs.State()
+	if err != nil {
+		return true
+	}
+
+	sum := sha256.Sum256([]byte(e.Path + "\x00" + e.ModTime.UTC().Format(time.RFC3339Nano)))
+	marker := filepath.Join(stateDir, "deprecations", hex.EncodeToString(sum[:]))
+
+	if err := os.MkdirAll(filepath.Dir(marker), 0o755); err != nil {
+		return true
+	}
+
+	f, err := os.OpenFile(marker, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+	if errors.Is(err, fs.ErrExist) {
+		return false
+	}
+	if err != nil {
+		return true
+	}
+
+	_ = f.Close()
+	return true
+}
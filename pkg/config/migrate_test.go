@@ -0,0 +1,161 @@
+package config_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/MakeNowJust/heredoc/v2"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/zkhvan/z/pkg/assert"
+	"github.com/zkhvan/z/pkg/config"
+	"github.com/zkhvan/z/pkg/project"
+)
+
+func TestFile_Migrate(t *testing.T) {
+	schema := config.NewSchema(project.ConfigSection())
+
+	tests := map[string]struct {
+		original string
+		expected string
+		changes  []config.Change
+	}{
+		"camel case keys": {
+			original: heredoc.Doc(`
+				projects:
+				  # The patterns
+				  remotePatterns:
+				    - my-org/*
+			`),
+			expected: heredoc.Doc(`
+				projects:
+				  # The patterns
+				  remote_patterns:
+				    - my-org/*
+			`),
+			changes: []config.Change{
+				{Line: 3, Key: "projects.remotePatterns", Message: `renamed to "remote_patterns"`},
+			},
+		},
+		"single values": {
+			original: "projects:\n  remote_patterns: my-org/* # work\n",
+			expected: "projects:\n  remote_patterns:\n    - my-org/* # work\n",
+			changes: []config.Change{
+				{Line: 2, Key: "projects.remote_patterns", Message: "converted to a list"},
+			},
+		},
+		"profiles": {
+			original: "profiles:\n  work:\n    projects:\n      remotePatterns: my-org/*\n",
+			expected: "profiles:\n  work:\n    projects:\n      remote_patterns:\n        - my-org/*\n",
+			changes: []config.Change{
+				{Line: 4, Key: "profiles.work.projects.remotePatterns", Message: `renamed to "remote_patterns"`},
+				{Line: 4, Key: "profiles.work.projects.remote_patterns", Message: "converted to a list"},
+			},
+		},
+		"repo-only alternate paths": {
+			original: heredoc.Doc(`
+				projects:
+				  remote_patterns:
+				    - my-org/*
+				    - cli/cli -> ./oss/ # open source
+			`),
+			expected: heredoc.Doc(`
+				projects:
+				  remote_patterns:
+				    - my-org/*
+				    - cli/cli -> ./oss/* # open source
+			`),
+			changes: []config.Change{
+				{Line: 4, Key: "projects.remote_patterns[1]", Message: `rewritten to "cli/cli -> ./oss/*"`},
+			},
+		},
+		"single repo-only alternate path": {
+			original: "projects:\n  remotePatterns: cli/cli -> ./oss/\n",
+			expected: "projects:\n  remote_patterns:\n    - cli/cli -> ./oss/*\n",
+			changes: []config.Change{
+				{Line: 2, Key: "projects.remotePatterns", Message: `renamed to "remote_patterns"`},
+				{Line: 2, Key: "projects.remote_patterns", Message: "converted to a list"},
+				{Line: 2, Key: "projects.remote_patterns[0]", Message: `rewritten to "cli/cli -> ./oss/*"`},
+			},
+		},
+		"both keys": {
+			original: "projects:\n  remotePatterns: [a/*]\n  remote_patterns: [b/*]\n",
+			expected: "projects:\n  remotePatterns: [a/*]\n  remote_patterns: [b/*]\n",
+		},
+		"up to date": {
+			original: "projects:\n  root: ~/src\n",
+			expected: "projects:\n  root: ~/src\n",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.yaml")
+			assert.NoError(t, os.WriteFile(path, []byte(tc.original), 0o600))
+
+			f, err := config.OpenPath(path)
+			assert.NoError(t, err)
+
+			changes := f.Migrate(schema)
+			if diff := cmp.Diff(tc.changes, changes); diff != "" {
+				t.Errorf("Migrate() mismatch (-want +got):\n%s", diff)
+			}
+
+			b, err := f.Bytes()
+			assert.NoError(t, err)
+			assert.EqualString(t, string(b), tc.expected)
+		})
+	}
+}
+
+func TestLoad_Deprecations(t *testing.T) {
+	dir := t.TempDir()
+	content := "projects:\n  remotePatterns: my-org/*\n"
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(content), 0o600))
+
+	cfg, err := config.Load(config.Options{
+		Dir:       dir,
+		SystemDir: t.TempDir(),
+		WorkDir:   t.TempDir(),
+		Sections:  []config.Section{{Key: "projects", Value: testDefaults{}}},
+	})
+
+	var deprecation *config.DeprecationError
+	if !errors.As(err, &deprecation) {
+		t.Fatalf("expected a deprecation error, got %v", err)
+	}
+	assert.EqualString(t, deprecation.Path, filepath.Join(dir, "config.yaml"))
+
+	// The deprecated keys keep working.
+	if diff := cmp.Diff([]string{"my-org/*"}, cfg.Strings("projects.remote_patterns")); diff != "" {
+		t.Errorf("remote_patterns mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestDeprecationError_FirstWarning(t *testing.T) {
+	t.Setenv("Z_STATE_DIR", t.TempDir())
+
+	modTime := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	deprecation := &config.DeprecationError{Path: "/config.yaml", ModTime: modTime}
+
+	if !deprecation.FirstWarning() {
+		t.Error("expected the first warning")
+	}
+	if deprecation.FirstWarning() {
+		t.Error("expected the warning to be printed once")
+	}
+
+	// The file changed.
+	deprecation.ModTime = modTime.Add(time.Second)
+	if !deprecation.FirstWarning() {
+		t.Error("expected the first warning of the changed file")
+	}
+
+	other := &config.DeprecationError{Path: "/other.yaml", ModTime: modTime}
+	if !other.FirstWarning() {
+		t.Error("expected the first warning of another file")
+	}
+}
@@ -0,0 +1,127 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/knadh/koanf/v2"
+
+	"github.com/zkhvan/z/pkg/cmdutil"
+	"github.com/zkhvan/z/pkg/oslib"
+)
+
+// ProfileEnv is the environment variable selecting the profile.
+const ProfileEnv = "Z_PROFILE"
+
+const (
+	// profilesKey maps the names of the profiles to the keys they override.
+	profilesKey = "profiles"
+	// directoriesKey lists the directories selecting a profile.
+	directoriesKey = "directories"
+)
+
+// UseProfile selects the profile of the config, whose keys override the
+// config files, e.g. "profiles.work.projects.root" overrides "projects.root".
+//
+// Without a name, the profile is $Z_PROFILE, or the profile whose directories
+// contain the working directory. If the profile doesn't exist, the config is
+// left without a profile and an error is returned.
+func UseProfile(cfg cmdutil.Config, name string) error {
+	p, ok := cfg.(*provider)
+	if !ok {
+		return errors.New("the config doesn't support profiles")
+	}
+
+	return p.useProfile(name)
+}
+
+// ActiveProfile returns the name of the profile in use, or an empty string
+// if none is, e.g. to select it again in a child process.
+func ActiveProfile(cfg cmdutil.Config) string {
+	p, ok := cfg.(*provider)
+	if !ok {
+		return ""
+	}
+
+	return p.profile
+}
+
+func (p *provider) useProfile(name string) error {
+	p.k = koanf.New(".")
+	p.origins = make(map[string]string)
+	p.profile = ""
+
+	for _, l := range p.layers {
+		if err := p.merge(l); err != nil {
+			return err
+		}
+	}
+
+	if name == "" {
+		name = os.Getenv(ProfileEnv)
+	}
+	if name == "" {
+		name = p.matchProfile()
+	}
+
+	var profileErr error
+	key := profilesKey + "." + name
+
+	switch {
+	case name == "":
+	case !p.k.Exists(key):
+		profileErr = fmt.Errorf("profile %q not found", name)
+	default:
+		profile := p.k.Cut(key)
+		profile.Delete(directoriesKey)
+
+		origins := maps.Clone(p.origins)
+		origin := func(k string) string {
+			return fmt.Sprintf("%s (profile %s)", origins[key+"."+k], name)
+		}
+		if err := p.merge(layer{k: profile, origin: origin}); err != nil {
+			return err
+		}
+		p.profile = name
+	}
+
+	p.k.Delete(profilesKey)
+	for key := range p.origins {
+		if hasPrefix(key, profilesKey) {
+			delete(p.origins, key)
+		}
+	}
+
+	if err := p.merge(p.env); err != nil {
+		return err
+	}
+
+	return profileErr
+}
+
+// matchProfile returns the profile whose directories contain the working
+// directory, the one with the deepest directory if several do.
+func (p *provider) matchProfile() string {
+	var match, matchDir string
+
+	for _, name := range p.k.MapKeys(profilesKey) {
+		for _, dir := range p.k.Strings(profilesKey + "." + name + "." + directoriesKey) {
+			dir = filepath.Clean(oslib.Expand(dir))
+
+			rel, err := filepath.Rel(dir, p.workDir)
+			if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+				continue
+			}
+
+			if len(dir) > len(matchDir) {
+				match, matchDir = name, dir
+			}
+		}
+	}
+
+	return match
+}
@@ -0,0 +1,317 @@
+package config
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+const schemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// Section is a top-level key of the config, decoded into the struct Value.
+type Section struct {
+	Key   string
+	Value any
+
+	// Validators validate the strings of the keys, relative to the section,
+	// e.g. "remote_patterns" validates each remote pattern.
+	Validators map[string]func(string) error
+
+	// Migrations rewrite the deprecated strings of the keys, relative to the
+	// section, and report whether they did, e.g. "remote_patterns" rewrites
+	// the deprecated syntax of the remote patterns.
+	Migrations map[string]func(string) (string, bool)
+}
+
+// Schema is the JSON Schema of the config, or of one of its values.
+type Schema struct {
+	Dialect    string             `json:"$schema,omitempty"`
+	Type       string             `json:"type,omitempty"`
+	Enum       []string           `json:"enum,omitempty"`
+	Properties map[string]*Schema `json:"properties,omitempty"`
+	// Values is the schema of the values of a map, while objects with
+	// properties don't allow other keys.
+	Values *Schema `json:"-"`
+	Items  *Schema `json:"items,omitempty"`
+
+	validate func(string) error
+	rewrite  func(string) (string, bool)
+}
+
+// MarshalJSON adds the additionalProperties of objects.
+func (s *Schema) MarshalJSON() ([]byte, error) {
+	type schema Schema
+	v := struct {
+		*schema
+		AdditionalProperties any `json:"additionalProperties,omitempty"`
+	}{schema: (*schema)(s)}
+
+	switch {
+	case s.Values != nil:
+		v.AdditionalProperties = s.Values
+	case s.Type == "object":
+		v.AdditionalProperties = false
+	}
+
+	return json.Marshal(v)
+}
+
+// NewSchema returns the schema of a config made of the sections, which can
+// also include other files and override the sections in profiles.
+func NewSchema(sections ...Section) *Schema {
+	s := sectionsSchema(sections)
+	s.Dialect = schemaDialect
+
+	profile := &Schema{Type: "object", Properties: maps.Clone(s.Properties)}
+	profile.Properties[directoriesKey] = &Schema{Type: "array", Items: &Schema{Type: "string"}}
+
+	s.Properties[includeKey] = &Schema{Type: "array", Items: &Schema{Type: "string"}}
+	s.Properties[profilesKey] = &Schema{Type: "object", Values: profile}
+
+	return s
+}
+
+// sectionsSchema returns the schema of the sections only.
+func sectionsSchema(sections []Section) *Schema {
+	s := &Schema{
+		Type:       "object",
+		Properties: make(map[string]*Schema),
+	}
+
+	for _, section := range sections {
+		property := schemaOf(reflect.TypeOf(section.Value), "")
+		for key, validate := range section.Validators {
+			if v := property.lookup(key); v != nil {
+				v.validate = validate
+			}
+		}
+		for key, rewrite := range section.Migrations {
+			if v := property.lookup(key); v != nil {
+				v.rewrite = rewrite
+			}
+		}
+		s.Properties[section.Key] = property
+	}
+
+	return s
+}
+
+func schemaOf(t reflect.Type, enum string) *Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		s := &Schema{Type: "string"}
+		if enum != "" {
+			s.Enum = strings.Split(enum, ",")
+		}
+		return s
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: schemaOf(t.Elem(), enum)}
+	case reflect.Map:
+		return &Schema{Type: "object", Values: schemaOf(t.Elem(), "")}
+	case reflect.Struct:
+		s := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+		for i := range t.NumField() {
+			field := t.Field(i)
+			name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if !field.IsExported() || name == "-" {
+				continue
+			}
+			if name == "" {
+				name = field.Name
+			}
+			s.Properties[name] = schemaOf(field.Type, field.Tag.Get("enum"))
+		}
+		return s
+	default:
+		return &Schema{}
+	}
+}
+
+// lookup returns the schema of the strings of the key, e.g. the items of a
+// list of strings.
+func (s *Schema) lookup(key string) *Schema {
+	for _, segment := range strings.Split(key, ".") {
+		if s.Properties[segment] == nil {
+			return nil
+		}
+		s = s.Properties[segment]
+	}
+
+	if s.Items != nil {
+		return s.Items
+	}
+
+	return s
+}
+
+// Problem is an error of a config file.
+type Problem struct {
+	Line    int
+	Column  int
+	Key     string
+	Message string
+}
+
+func (p Problem) String() string {
+	// The keys added by edits have no line.
+	if p.Line == 0 {
+		return fmt.Sprintf("%s: %s", p.Key, p.Message)
+	}
+	return fmt.Sprintf("line %d: %s: %s", p.Line, p.Key, p.Message)
+}
+
+// Validate returns the problems of the content of a config file: unknown keys,
+// values of the wrong type and values rejected by the validators of the
+// sections. It returns an error if the content isn't valid YAML.
+func (s *Schema) Validate(b []byte) ([]Problem, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(b, &doc); err != nil {
+		return nil, err
+	}
+
+	return s.validateDoc(&doc), nil
+}
+
+func (s *Schema) validateDoc(doc *yaml.Node) []Problem {
+	if len(doc.Content) == 0 {
+		return nil
+	}
+
+	var problems []Problem
+	s.validateNode(doc.Content[0], "", &problems)
+
+	return problems
+}
+
+func (s *Schema) validateNode(node *yaml.Node, key string, problems *[]Problem) {
+	problem := func(node *yaml.Node, format string, args ...any) {
+		*problems = append(*problems, Problem{
+			Line:    node.Line,
+			Column:  node.Column,
+			Key:     cmp.Or(key, "(root)"),
+			Message: fmt.Sprintf(format, args...),
+		})
+	}
+
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+
+	// An empty value is the same as a missing one.
+	if node.Kind == yaml.ScalarNode && node.Tag == "!!null" {
+		return
+	}
+
+	switch s.Type {
+	case "object":
+		if node.Kind != yaml.MappingNode {
+			problem(node, "expected a mapping")
+			return
+		}
+
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			name, value := node.Content[i].Value, node.Content[i+1]
+			child := joinKey(key, name)
+
+			property := s.Values
+			if property == nil {
+				property = s.Properties[name]
+			}
+			if property == nil {
+				msg := fmt.Sprintf("unknown key %q", name)
+				if suggestion := s.suggest(name); suggestion != "" {
+					msg += fmt.Sprintf(", did you mean %q?", suggestion)
+				}
+				*problems = append(*problems, Problem{
+					Line:    node.Content[i].Line,
+					Column:  node.Content[i].Column,
+					Key:     child,
+					Message: msg,
+				})
+				continue
+			}
+
+			property.validateNode(value, child, problems)
+		}
+	case "array":
+		if node.Kind != yaml.SequenceNode {
+			problem(node, "expected a list")
+			return
+		}
+
+		for i, item := range node.Content {
+			s.Items.validateNode(item, fmt.Sprintf("%s[%d]", key, i), problems)
+		}
+	case "string", "integer", "number", "boolean":
+		if node.Kind != yaml.ScalarNode {
+			problem(node, "expected %s", article(s.Type))
+			return
+		}
+
+		switch {
+		case s.Type == "integer" && node.Tag != "!!int",
+			s.Type == "number" && node.Tag != "!!int" && node.Tag != "!!float",
+			s.Type == "boolean" && node.Tag != "!!bool":
+			problem(node, "expected %s, got %q", article(s.Type), node.Value)
+			return
+		}
+
+		if len(s.Enum) > 0 && !slices.Contains(s.Enum, node.Value) {
+			problem(node, "expected one of %s, got %q", strings.Join(s.Enum, ", "), node.Value)
+			return
+		}
+
+		if s.validate != nil {
+			if err := s.validate(node.Value); err != nil {
+				problem(node, "%v", err)
+			}
+		}
+	}
+}
+
+// suggest returns the property whose name is close to the unknown name, e.g.
+// "remote_patterns" for "remotePatterns".
+func (s *Schema) suggest(name string) string {
+	normalize := func(s string) string {
+		return strings.ToLower(strings.NewReplacer("_", "", "-", "").Replace(s))
+	}
+
+	for property := range s.Properties {
+		if normalize(property) == normalize(name) {
+			return property
+		}
+	}
+
+	return ""
+}
+
+func article(typ string) string {
+	if typ == "integer" {
+		return "an " + typ
+	}
+	return "a " + typ
+}
+
+func joinKey(parent, key string) string {
+	if parent == "" {
+		return key
+	}
+	return parent + "." + key
+}
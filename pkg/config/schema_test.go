@@ -0,0 +1,152 @@
+package config_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/MakeNowJust/heredoc/v2"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/zkhvan/z/pkg/assert"
+	"github.com/zkhvan/z/pkg/config"
+)
+
+type testSection struct {
+	Root     string            `json:"root"`
+	TTL      int64             `json:"ttl"`
+	Offline  bool              `json:"offline"`
+	Patterns []string          `json:"remote_patterns"`
+	Aliases  map[string]string `json:"aliases"`
+	Items    []testItem        `json:"items"`
+}
+
+type testItem struct {
+	Type string `json:"type" enum:"a,b"`
+}
+
+func TestSchema_Validate(t *testing.T) {
+	schema := config.NewSchema(config.Section{
+		Key:   "projects",
+		Value: testSection{},
+		Validators: map[string]func(string) error{
+			"remote_patterns": func(s string) error {
+				if !strings.Contains(s, "/") {
+					return errors.New("invalid pattern")
+				}
+				return nil
+			},
+		},
+	})
+
+	tests := map[string]struct {
+		input    string
+		expected []config.Problem
+	}{
+		"valid": {
+			input: heredoc.Doc(`
+				projects:
+				  root: ~/Projects
+				  ttl: 60
+				  offline: true
+				  remote_patterns: [a/*]
+				  aliases:
+				    z: zkhvan/z
+				  items:
+				    - type: a
+			`),
+		},
+		"empty": {
+			input: "",
+		},
+		"null values": {
+			input: heredoc.Doc(`
+				projects:
+				  aliases:
+			`),
+		},
+		"unknown keys": {
+			input: heredoc.Doc(`
+				projects:
+				  remotePatterns: [a/*]
+				  other: 1
+				tmux: {}
+			`),
+			expected: []config.Problem{
+				{
+					Line:    2,
+					Column:  3,
+					Key:     "projects.remotePatterns",
+					Message: `unknown key "remotePatterns", did you mean "remote_patterns"?`,
+				},
+				{Line: 3, Column: 3, Key: "projects.other", Message: `unknown key "other"`},
+				{Line: 4, Column: 1, Key: "tmux", Message: `unknown key "tmux"`},
+			},
+		},
+		"type errors": {
+			input: heredoc.Doc(`
+				projects:
+				  root: [a]
+				  ttl: soon
+				  offline: 1
+				  remote_patterns: a/*
+				  items:
+				    - type: c
+			`),
+			expected: []config.Problem{
+				{Line: 2, Column: 9, Key: "projects.root", Message: "expected a string"},
+				{Line: 3, Column: 8, Key: "projects.ttl", Message: `expected an integer, got "soon"`},
+				{Line: 4, Column: 12, Key: "projects.offline", Message: `expected a boolean, got "1"`},
+				{Line: 5, Column: 20, Key: "projects.remote_patterns", Message: "expected a list"},
+				{Line: 7, Column: 13, Key: "projects.items[0].type", Message: `expected one of a, b, got "c"`},
+			},
+		},
+		"validators": {
+			input: heredoc.Doc(`
+				projects:
+				  remote_patterns:
+				    - a/*
+				    - a
+			`),
+			expected: []config.Problem{
+				{Line: 4, Column: 7, Key: "projects.remote_patterns[1]", Message: "invalid pattern"},
+			},
+		},
+		"not a mapping": {
+			input: "projects: 1\n",
+			expected: []config.Problem{
+				{Line: 1, Column: 11, Key: "projects", Message: "expected a mapping"},
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			problems, err := schema.Validate([]byte(tc.input))
+			assert.NoError(t, err)
+
+			if diff := cmp.Diff(tc.expected, problems); diff != "" {
+				t.Errorf("Validate() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSchema_MarshalJSON(t *testing.T) {
+	schema := config.NewSchema(config.Section{Key: "projects", Value: testItem{}})
+
+	b, err := schema.MarshalJSON()
+	assert.NoError(t, err)
+
+	projects := `{"type":"object","properties":{"type":{"type":"string","enum":["a","b"]}},"additionalProperties":false}`
+	list := `{"type":"array","items":{"type":"string"}}`
+	assert.EqualString(
+		t,
+		string(b),
+		`{"$schema":"https://json-schema.org/draft/2020-12/schema","type":"object","properties":{`+
+			`"include":`+list+`,`+
+			`"profiles":{"type":"object","additionalProperties":{"type":"object","properties":{`+
+			`"directories":`+list+`,"projects":`+projects+`},"additionalProperties":false}},`+
+			`"projects":`+projects+`},"additionalProperties":false}`,
+	)
+}
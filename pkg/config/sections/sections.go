@@ -0,0 +1,15 @@
+// Package sections lists the sections of the config, which can't be done by
+// the config package since the sections import it.
+package sections
+
+import (
+	"github.com/zkhvan/z/pkg/config"
+	"github.com/zkhvan/z/pkg/project"
+)
+
+// All returns the sections of the config.
+func All() []config.Section {
+	return []config.Section{
+		project.ConfigSection(),
+	}
+}
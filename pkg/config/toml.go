@@ -0,0 +1,431 @@
+package config
+
+import (
+	"bytes"
+	"cmp"
+	"encoding"
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pelletier/go-toml/v2"
+	"github.com/pelletier/go-toml/v2/unstable"
+	"go.yaml.in/yaml/v3"
+)
+
+// decodeTOML returns the document of a TOML file, with the keys in the order
+// and at the lines of the file. The comments are lost.
+func decodeTOML(b []byte) (*yaml.Node, error) {
+	var values map[string]any
+	if err := toml.Unmarshal(b, &values); err != nil {
+		return nil, err
+	}
+
+	positions, err := tomlPositions(b)
+	if err != nil {
+		return nil, err
+	}
+
+	root, err := tomlNode(values, nil, unstable.Position{Line: 1, Column: 1}, positions)
+	if err != nil {
+		return nil, err
+	}
+
+	return &yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{root}}, nil
+}
+
+// tomlPositions returns the positions of the keys of a TOML file, by path.
+// The elements of the arrays of tables are at the path of the array followed
+// by their index.
+func tomlPositions(b []byte) (map[string]unstable.Position, error) {
+	var (
+		p         = unstable.Parser{}
+		positions = make(map[string]unstable.Position)
+		// arrays counts the elements of the arrays of tables.
+		arrays  = make(map[string]int)
+		current []string
+	)
+
+	record := func(path []string, pos unstable.Position) {
+		key := tomlPath(path)
+		if _, ok := positions[key]; !ok {
+			positions[key] = pos
+		}
+	}
+
+	// table returns the path of the table of the key, which is the last
+	// element of an array of tables.
+	table := func(path []string, keys unstable.Iterator) []string {
+		for keys.Next() {
+			path = append(path, string(keys.Node().Data))
+			record(path, p.Shape(keys.Node().Raw).Start)
+
+			if n, ok := arrays[tomlPath(path)]; ok && !keys.IsLast() {
+				path = append(path, strconv.Itoa(n-1))
+			}
+		}
+		return path
+	}
+
+	var keyValue func(path []string, n *unstable.Node)
+	keyValue = func(path []string, n *unstable.Node) {
+		path = table(slices.Clone(path), n.Key())
+
+		value := n.Value()
+		switch value.Kind {
+		case unstable.InlineTable:
+			for it := value.Children(); it.Next(); {
+				keyValue(path, it.Node())
+			}
+		case unstable.Array:
+			i := 0
+			for it := value.Children(); it.Next(); {
+				if it.Node().Kind != unstable.InlineTable {
+					i++
+					continue
+				}
+				for kv := it.Node().Children(); kv.Next(); {
+					keyValue(append(slices.Clone(path), strconv.Itoa(i)), kv.Node())
+				}
+				i++
+			}
+		}
+	}
+
+	p.Reset(b)
+	for p.NextExpression() {
+		e := p.Expression()
+
+		switch e.Kind {
+		case unstable.Table:
+			current = table(nil, e.Key())
+			if n, ok := arrays[tomlPath(current)]; ok {
+				current = append(current, strconv.Itoa(n-1))
+			}
+		case unstable.ArrayTable:
+			current = table(nil, e.Key())
+			key := tomlPath(current)
+			current = append(current, strconv.Itoa(arrays[key]))
+			arrays[key]++
+			record(current, p.Shape(e.Child().Raw).Start)
+		case unstable.KeyValue:
+			keyValue(current, e)
+		}
+	}
+	if err := p.Error(); err != nil {
+		return nil, err
+	}
+
+	return positions, nil
+}
+
+// tomlPath returns the key of the path in the positions.
+func tomlPath(path []string) string {
+	return strings.Join(path, "\x00")
+}
+
+// hasTOMLComments reports whether the TOML file has comments.
+func hasTOMLComments(b []byte) bool {
+	p := unstable.Parser{KeepComments: true}
+	p.Reset(b)
+
+	for p.NextExpression() {
+		if hasComment(p.Expression()) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// hasComment reports whether the node, its children or the nodes following
+// it are comments.
+func hasComment(n *unstable.Node) bool {
+	for ; n != nil; n = n.Next() {
+		if n.Kind == unstable.Comment || hasComment(n.Child()) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func tomlNode(v any, path []string, pos unstable.Position, positions map[string]unstable.Position) (*yaml.Node, error) {
+	if p, ok := positions[tomlPath(path)]; ok {
+		pos = p
+	}
+	node := &yaml.Node{Line: pos.Line, Column: pos.Column}
+
+	switch v := v.(type) {
+	case map[string]any:
+		node.Kind, node.Tag = yaml.MappingNode, "!!map"
+
+		position := func(key string) unstable.Position {
+			return positions[tomlPath(append(slices.Clone(path), key))]
+		}
+		keys := slices.SortedFunc(func(yield func(string) bool) {
+			for key := range v {
+				if !yield(key) {
+					return
+				}
+			}
+		}, func(a, b string) int {
+			pa, pb := position(a), position(b)
+			return cmp.Or(cmp.Compare(pa.Line, pb.Line), cmp.Compare(pa.Column, pb.Column), strings.Compare(a, b))
+		})
+
+		for _, key := range keys {
+			child := append(slices.Clone(path), key)
+			value, err := tomlNode(v[key], child, pos, positions)
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, &yaml.Node{
+				Kind:   yaml.ScalarNode,
+				Tag:    "!!str",
+				Value:  key,
+				Line:   value.Line,
+				Column: value.Column,
+			}, value)
+		}
+	case []any:
+		node.Kind, node.Tag = yaml.SequenceNode, "!!seq"
+		for i, item := range v {
+			child, err := tomlNode(item, append(slices.Clone(path), strconv.Itoa(i)), pos, positions)
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, child)
+		}
+	case encoding.TextMarshaler:
+		// The local dates and times.
+		text, err := v.MarshalText()
+		if err != nil {
+			return nil, err
+		}
+		node.Kind, node.Tag, node.Value = yaml.ScalarNode, "!!str", string(text)
+	default:
+		var scalar yaml.Node
+		if err := scalar.Encode(v); err != nil {
+			return nil, err
+		}
+		node.Kind, node.Tag, node.Value, node.Style = scalar.Kind, scalar.Tag, scalar.Value, scalar.Style
+	}
+
+	return node, nil
+}
+
+// encodeTOML encodes the document, keeping the order of the keys. The
+// comments are lost.
+func encodeTOML(doc *yaml.Node) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := writeTOMLTable(&buf, nil, doc.Content[0]); err != nil {
+		return nil, err
+	}
+
+	return bytes.TrimLeft(buf.Bytes(), "\n"), nil
+}
+
+// writeTOMLTable writes the keys of the mapping, followed by its tables,
+// since TOML requires it.
+func writeTOMLTable(buf *bytes.Buffer, path []string, mapping *yaml.Node) error {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		key, value := mapping.Content[i].Value, resolveAlias(mapping.Content[i+1])
+		if isTable(value) || value.Tag == "!!null" {
+			continue
+		}
+
+		v, err := tomlInline(value)
+		if err != nil {
+			return fmt.Errorf("error encoding %q: %w", key, err)
+		}
+		fmt.Fprintf(buf, "%s = %s\n", tomlKey(key), v)
+	}
+
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		key, value := mapping.Content[i].Value, resolveAlias(mapping.Content[i+1])
+		if !isTable(value) {
+			continue
+		}
+
+		child := append(slices.Clone(path), key)
+		if value.Kind == yaml.MappingNode {
+			// A table holding only tables is implied by them.
+			if len(value.Content) == 0 || !onlyTables(value) {
+				fmt.Fprintf(buf, "\n[%s]\n", tomlDottedKey(child))
+			}
+			if err := writeTOMLTable(buf, child, value); err != nil {
+				return err
+			}
+			continue
+		}
+
+		for _, item := range value.Content {
+			fmt.Fprintf(buf, "\n[[%s]]\n", tomlDottedKey(child))
+			if err := writeTOMLTable(buf, child, resolveAlias(item)); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// isTable reports whether the node is a table or an array of tables.
+func isTable(node *yaml.Node) bool {
+	node = resolveAlias(node)
+
+	switch node.Kind {
+	case yaml.MappingNode:
+		return true
+	case yaml.SequenceNode:
+		return len(node.Content) > 0 && resolveAlias(node.Content[0]).Kind == yaml.MappingNode
+	default:
+		return false
+	}
+}
+
+// onlyTables reports whether the values of the mapping are all tables.
+func onlyTables(mapping *yaml.Node) bool {
+	for i := 1; i < len(mapping.Content); i += 2 {
+		if !isTable(mapping.Content[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func resolveAlias(node *yaml.Node) *yaml.Node {
+	if node.Kind == yaml.AliasNode {
+		return node.Alias
+	}
+	return node
+}
+
+// tomlInline returns the inline TOML value of the node.
+func tomlInline(node *yaml.Node) (string, error) {
+	node = resolveAlias(node)
+
+	switch node.Kind {
+	case yaml.MappingNode:
+		items := make([]string, 0, len(node.Content)/2)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			v, err := tomlInline(node.Content[i+1])
+			if err != nil {
+				return "", err
+			}
+			items = append(items, tomlKey(node.Content[i].Value)+" = "+v)
+		}
+		if len(items) == 0 {
+			return "{}", nil
+		}
+		return "{ " + strings.Join(items, ", ") + " }", nil
+	case yaml.SequenceNode:
+		items := make([]string, 0, len(node.Content))
+		for _, item := range node.Content {
+			v, err := tomlInline(item)
+			if err != nil {
+				return "", err
+			}
+			items = append(items, v)
+		}
+		return "[" + strings.Join(items, ", ") + "]", nil
+	default:
+		var v any
+		if err := node.Decode(&v); err != nil {
+			return "", err
+		}
+		return tomlScalar(v), nil
+	}
+}
+
+func tomlScalar(v any) string {
+	switch v := v.(type) {
+	case nil:
+		// TOML has no null.
+		return `""`
+	case bool:
+		return strconv.FormatBool(v)
+	case int:
+		return strconv.Itoa(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case uint64:
+		return strconv.FormatUint(v, 10)
+	case float64:
+		switch {
+		case math.IsNaN(v):
+			return "nan"
+		case math.IsInf(v, 1):
+			return "inf"
+		case math.IsInf(v, -1):
+			return "-inf"
+		}
+		s := strconv.FormatFloat(v, 'g', -1, 64)
+		if !strings.ContainsAny(s, ".eE") {
+			s += ".0"
+		}
+		return s
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case string:
+		return tomlQuote(v)
+	default:
+		return tomlQuote(fmt.Sprint(v))
+	}
+}
+
+// bareKey matches the keys which don't need quotes.
+var bareKey = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+func tomlKey(key string) string {
+	if bareKey.MatchString(key) {
+		return key
+	}
+	return tomlQuote(key)
+}
+
+func tomlDottedKey(path []string) string {
+	keys := make([]string, 0, len(path))
+	for _, key := range path {
+		keys = append(keys, tomlKey(key))
+	}
+	return strings.Join(keys, ".")
+}
+
+// tomlQuote returns the TOML basic string of s.
+func tomlQuote(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\b':
+			b.WriteString(`\b`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\f':
+			b.WriteString(`\f`)
+		case '\r':
+			b.WriteString(`\r`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				fmt.Fprintf(&b, `\u%04X`, r)
+				continue
+			}
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
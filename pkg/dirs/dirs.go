@@ -0,0 +1,192 @@
+// Package dirs resolves the directories where z keeps its files, following
+// the XDG base directory specification:
+//
+//   - Config: the config file, $XDG_CONFIG_HOME/z.
+//   - Cache: what can be rebuilt, e.g. the remote projects, $XDG_CACHE_HOME/z.
+//   - State: what should persist but isn't worth backing up, e.g. history,
+//     $XDG_STATE_HOME/z.
+//   - Data: what should persist, $XDG_DATA_HOME/z.
+//
+// The system-wide config file is in System, /etc/z or %ProgramData%\z.
+//
+// Each directory can be overridden with its Z_*_DIR environment variable, e.g.
+// Z_CACHE_DIR.
+package dirs
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+)
+
+const appName = "z"
+
+// kind describes how to resolve a directory.
+type kind struct {
+	// override is the environment variable overriding the directory.
+	override string
+	// xdg is the XDG environment variable of the base directory.
+	xdg string
+	// home is the default base directory, relative to the home directory.
+	home string
+	// platform returns the default base directory on platforms which don't
+	// follow XDG, if set.
+	platform func() (string, error)
+}
+
+var (
+	config = kind{
+		override: "Z_CONFIG_DIR",
+		xdg:      "XDG_CONFIG_HOME",
+		home:     ".config",
+		platform: func() (string, error) {
+			switch runtime.GOOS {
+			case "darwin":
+				// os.UserConfigDir ignores XDG_CONFIG_HOME on darwin.
+				home, err := os.UserHomeDir()
+				if err != nil {
+					return "", err
+				}
+				return filepath.Join(home, "Library", "Application Support"), nil
+			case "windows":
+				return os.UserConfigDir()
+			default:
+				return "", nil
+			}
+		},
+	}
+	cache = kind{
+		override: "Z_CACHE_DIR",
+		xdg:      "XDG_CACHE_HOME",
+		home:     ".cache",
+		platform: windowsDir(os.UserCacheDir),
+	}
+	state = kind{
+		override: "Z_STATE_DIR",
+		xdg:      "XDG_STATE_HOME",
+		home:     filepath.Join(".local", "state"),
+		platform: windowsDir(os.UserCacheDir),
+	}
+	data = kind{
+		override: "Z_DATA_DIR",
+		xdg:      "XDG_DATA_HOME",
+		home:     filepath.Join(".local", "share"),
+		platform: windowsDir(os.UserConfigDir),
+	}
+)
+
+// Config returns the directory of the config file.
+func Config() (string, error) {
+	return resolve(config)
+}
+
+// System returns the directory of the system-wide config file.
+func System() string {
+	if dir := os.Getenv("Z_SYSTEM_CONFIG_DIR"); dir != "" {
+		return dir
+	}
+
+	if runtime.GOOS == "windows" {
+		return filepath.Join(os.Getenv("ProgramData"), appName)
+	}
+
+	return filepath.Join("/etc", appName)
+}
+
+// Cache returns the directory of the cache. The cache of previous versions
+// is moved there the first time.
+func Cache() (string, error) {
+	dir, err := resolve(cache)
+	if err != nil {
+		return "", err
+	}
+
+	if os.Getenv(cache.override) == "" {
+		migrateOnce.Do(func() { migrateLegacyCache(dir) })
+	}
+
+	return dir, nil
+}
+
+// State returns the directory of the state, e.g. the history.
+func State() (string, error) {
+	return resolve(state)
+}
+
+// Data returns the directory of the data.
+func Data() (string, error) {
+	return resolve(data)
+}
+
+func resolve(k kind) (string, error) {
+	if dir := os.Getenv(k.override); dir != "" {
+		return dir, nil
+	}
+
+	// Relative paths are invalid and should be ignored, per the specification.
+	if dir := os.Getenv(k.xdg); filepath.IsAbs(dir) {
+		return filepath.Join(dir, appName), nil
+	}
+
+	if k.platform != nil {
+		dir, err := k.platform()
+		if err != nil {
+			return "", err
+		}
+		if dir != "" {
+			return filepath.Join(dir, appName), nil
+		}
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", errors.New("neither $" + k.xdg + " nor $HOME are defined")
+	}
+
+	return filepath.Join(home, k.home, appName), nil
+}
+
+func windowsDir(dir func() (string, error)) func() (string, error) {
+	return func() (string, error) {
+		if runtime.GOOS != "windows" {
+			return "", nil
+		}
+		return dir()
+	}
+}
+
+var migrateOnce sync.Once
+
+// migrateLegacyCache moves the cache of previous versions, which were
+// reading $XDG_CACHE_DIR instead of $XDG_CACHE_HOME, to dir. It's left in
+// place if dir already exists, and rebuilt if it can't be moved.
+func migrateLegacyCache(dir string) {
+	legacy := os.Getenv("XDG_CACHE_DIR")
+	if legacy == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return
+		}
+		legacy = filepath.Join(home, ".cache")
+	}
+	legacy = filepath.Join(legacy, appName)
+
+	if filepath.Clean(legacy) == filepath.Clean(dir) {
+		return
+	}
+
+	if _, err := os.Stat(legacy); err != nil {
+		return
+	}
+	if _, err := os.Lstat(dir); !errors.Is(err, os.ErrNotExist) {
+		return
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dir), 0o755); err != nil {
+		return
+	}
+
+	_ = os.Rename(legacy, dir)
+}
@@ -0,0 +1,93 @@
+package dirs_test
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/zkhvan/z/pkg/assert"
+	"github.com/zkhvan/z/pkg/dirs"
+)
+
+func TestDirs(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the defaults differ on windows")
+	}
+
+	tests := map[string]struct {
+		env      map[string]string
+		dir      func() (string, error)
+		expected string
+	}{
+		"state default": {
+			dir:      dirs.State,
+			expected: "$HOME/.local/state/z",
+		},
+		"state xdg": {
+			env:      map[string]string{"XDG_STATE_HOME": "/xdg/state"},
+			dir:      dirs.State,
+			expected: "/xdg/state/z",
+		},
+		"state override": {
+			env:      map[string]string{"XDG_STATE_HOME": "/xdg/state", "Z_STATE_DIR": "/z/state"},
+			dir:      dirs.State,
+			expected: "/z/state",
+		},
+		"data relative xdg is ignored": {
+			env:      map[string]string{"XDG_DATA_HOME": "relative"},
+			dir:      dirs.Data,
+			expected: "$HOME/.local/share/z",
+		},
+		"config xdg": {
+			env:      map[string]string{"XDG_CONFIG_HOME": "/xdg/config"},
+			dir:      dirs.Config,
+			expected: "/xdg/config/z",
+		},
+		"cache override": {
+			env:      map[string]string{"Z_CACHE_DIR": "/z/cache"},
+			dir:      dirs.Cache,
+			expected: "/z/cache",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			home := t.TempDir()
+			t.Setenv("HOME", home)
+			for _, env := range []string{
+				"XDG_CONFIG_HOME", "XDG_CACHE_HOME", "XDG_STATE_HOME", "XDG_DATA_HOME",
+				"Z_CONFIG_DIR", "Z_CACHE_DIR", "Z_STATE_DIR", "Z_DATA_DIR",
+			} {
+				t.Setenv(env, tc.env[env])
+			}
+
+			dir, err := tc.dir()
+			assert.NoError(t, err)
+			assert.EqualString(t, os.Expand(tc.expected, func(string) string { return home }), dir)
+		})
+	}
+}
+
+func TestCache_MigratesLegacyCache(t *testing.T) {
+	root := t.TempDir()
+	t.Setenv("HOME", filepath.Join(root, "home"))
+	t.Setenv("Z_CACHE_DIR", "")
+	t.Setenv("XDG_CACHE_DIR", filepath.Join(root, "legacy"))
+	t.Setenv("XDG_CACHE_HOME", filepath.Join(root, "cache"))
+
+	legacy := filepath.Join(root, "legacy", "z")
+	assert.NoError(t, os.MkdirAll(legacy, 0o755))
+	assert.NoError(t, os.WriteFile(filepath.Join(legacy, "key.json"), []byte("{}"), 0o644))
+
+	dir, err := dirs.Cache()
+	assert.NoError(t, err)
+	assert.EqualString(t, filepath.Join(root, "cache", "z"), dir)
+
+	if _, err := os.Stat(filepath.Join(dir, "key.json")); err != nil {
+		t.Fatalf("expected the legacy cache to be moved: %v", err)
+	}
+	if _, err := os.Stat(legacy); !os.IsNotExist(err) {
+		t.Fatalf("expected the legacy cache to be removed: %v", err)
+	}
+}
@@ -1,11 +1,16 @@
 package factory
 
 import (
+	"errors"
+	"fmt"
 	"os"
+	"slices"
+	"strings"
 
 	"github.com/zkhvan/z/pkg/cmd"
 	"github.com/zkhvan/z/pkg/cmdutil"
 	"github.com/zkhvan/z/pkg/config"
+	"github.com/zkhvan/z/pkg/config/sections"
 	"github.com/zkhvan/z/pkg/iolib"
 )
 
@@ -31,10 +36,75 @@ func defaultPluginHandler(_ *cmdutil.Factory) cmdutil.PluginHandler {
 	return cmd.NewDefaultPluginHandler([]string{"z"})
 }
 
-func defaultConfig(_ *cmdutil.Factory) cmdutil.Config {
-	c, err := config.New()
+func defaultConfig(f *cmdutil.Factory) cmdutil.Config {
+	c, err := config.Load(config.Options{Sections: sections.All()})
 	if err != nil {
-		panic(err)
+		if c == nil {
+			fmt.Fprintf(f.IOStreams.ErrOut, "error loading the config: %v\n", err)
+			os.Exit(1)
+		}
+		for _, err := range warnings(err, os.Args[1:]) {
+			fmt.Fprintf(f.IOStreams.ErrOut, "warning: %v\n", err)
+		}
 	}
 	return c
 }
+
+// warnings returns the errors of loading the config to print as warnings.
+// The deprecations of a config file are printed once per version of the file,
+// and never in the runs which aren't interactive.
+func warnings(err error, args []string) []error {
+	errs := []error{err}
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		errs = joined.Unwrap()
+	}
+
+	var warnings []error
+	for _, err := range errs {
+		var deprecation *config.DeprecationError
+		if errors.As(err, &deprecation) && (BackgroundRun(args) || !deprecation.FirstWarning()) {
+			continue
+		}
+		warnings = append(warnings, err)
+	}
+
+	return warnings
+}
+
+// valueFlags are the flags of the parent commands taking a value, skipped
+// along with it to find the subcommand.
+var valueFlags = []string{"--profile", "--cache-dir"}
+
+// BackgroundRun reports whether the arguments are of a run which isn't
+// interactive: the shell completion, the preview of the fuzzy finder, the
+// background refresh and the daemon. The flags can come before the
+// subcommand, e.g. "--profile work project preview".
+func BackgroundRun(args []string) bool {
+	var commands []string
+	for i := 0; i < len(args); i++ {
+		switch arg := args[i]; {
+		case arg == "--":
+			i = len(args)
+		case slices.Contains(valueFlags, arg):
+			// The value follows the flag.
+			i++
+		case !strings.HasPrefix(arg, "-"):
+			commands = append(commands, arg)
+		}
+	}
+
+	switch {
+	case len(commands) == 0:
+		return false
+	case strings.HasPrefix(commands[0], "__complete"):
+		return true
+	case len(commands) >= 2 && commands[0] == "project" && commands[1] == "preview":
+		return true
+	case len(commands) >= 2 && commands[0] == "project" && commands[1] == "refresh":
+		return slices.Contains(args, "--background")
+	case len(commands) >= 2 && commands[0] == "daemon" && commands[1] == "run":
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,62 @@
+package factory_test
+
+import (
+	"testing"
+
+	"github.com/zkhvan/z/pkg/factory"
+)
+
+func TestBackgroundRun(t *testing.T) {
+	tests := map[string]struct {
+		args     []string
+		expected bool
+	}{
+		"no arguments": {
+			args: nil,
+		},
+		"interactive": {
+			args: []string{"project", "select"},
+		},
+		"completion": {
+			args:     []string{"__complete", "project", ""},
+			expected: true,
+		},
+		"preview": {
+			args:     []string{"project", "preview", "--profile", "work", "zkhvan/z"},
+			expected: true,
+		},
+		"preview with leading flags": {
+			args:     []string{"--profile", "work", "project", "--cache-dir", "/tmp/z", "preview", "zkhvan/z"},
+			expected: true,
+		},
+		"preview with a flag value": {
+			args:     []string{"--profile=work", "project", "preview", "zkhvan/z"},
+			expected: true,
+		},
+		"profile named like a command": {
+			args: []string{"--profile", "project", "preview"},
+		},
+		"background refresh": {
+			args:     []string{"--profile", "work", "project", "refresh", "--background"},
+			expected: true,
+		},
+		"refresh": {
+			args: []string{"project", "refresh"},
+		},
+		"daemon": {
+			args:     []string{"--profile", "work", "daemon", "run"},
+			expected: true,
+		},
+		"daemon status": {
+			args: []string{"daemon", "status"},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := factory.BackgroundRun(tc.args); got != tc.expected {
+				t.Errorf("BackgroundRun(%q) = %v, want %v", tc.args, got, tc.expected)
+			}
+		})
+	}
+}
@@ -1,101 +1,129 @@
 package fcache
 
 import (
-	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"io/fs"
 	"os"
 	"path/filepath"
 	"time"
 
-	"github.com/zkhvan/z/pkg/oslib"
+	"github.com/zkhvan/z/pkg/dirs"
 )
 
 var ErrNotFound = errors.New("cache not found")
 
+// NormalizeCacheDir returns the cache directory, see dirs.Cache, unless
+// cacheDir is set.
 func NormalizeCacheDir(cacheDir string) string {
 	if cacheDir != "" {
 		return cacheDir
 	}
 
-	cacheDir = oslib.Expand("~/.cache")
-	if os.Getenv("XDG_CACHE_DIR") != "" {
-		cacheDir = os.Getenv("XDG_CACHE_DIR")
+	dir, err := dirs.Cache()
+	if err != nil {
+		// Without a home directory, the cache is kept temporarily.
+		return filepath.Join(os.TempDir(), "z")
 	}
 
-	return filepath.Join(cacheDir, "z")
+	return dir
 }
 
 func LoadMany[T any](dir, key string) ([]T, error) {
+	data, _, err := load[[]T](dir, key, false)
+	return data, err
+}
+
+// LoadLatest loads the latest data saved under the key, even if it's expired.
+// It also returns the expiry of the data.
+func LoadLatest[T any](dir, key string) ([]T, time.Time, error) {
+	return load[[]T](dir, key, true)
+}
+
+func load[T any](dir, key string, includeExpired bool) (T, time.Time, error) {
+	var zero T
+
 	now := time.Now().Unix()
 
 	_, err := os.Stat(dir)
 	if errors.Is(err, fs.ErrNotExist) {
-		return nil, ErrNotFound
+		return zero, time.Time{}, ErrNotFound
 	}
 	if err != nil {
-		return nil, err
+		return zero, time.Time{}, err
 	}
 
 	root, err := os.OpenRoot(dir)
 	if err != nil {
-		return nil, err
+		return zero, time.Time{}, err
 	}
 
 	var latestFile string
 	var latestTimestamp int64
 
-	pattern := fmt.Sprintf("%s-%%d.json", key)
-	err = fs.WalkDir(root.FS(), ".", func(path string, d fs.DirEntry, err error) error {
-		if err != nil {
-			return err
-		}
+	// Only the top-level files hold keys, the subdirectories are the caches
+	// of other packages, e.g. the API responses.
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return zero, time.Time{}, err
+	}
 
+	pattern := fmt.Sprintf("%s-%%d.json", key)
+	for _, d := range entries {
 		if d.IsDir() {
-			return nil
+			continue
 		}
 
 		var timestamp int64
 		if _, err := fmt.Sscanf(d.Name(), pattern, &timestamp); err != nil {
-			return nil
+			continue
 		}
 
-		if timestamp < now {
+		if timestamp < now && !includeExpired {
 			// File is expired, skip it
-			return nil
+			continue
 		}
 
 		if timestamp > latestTimestamp {
 			latestTimestamp = timestamp
-			latestFile = path
+			latestFile = d.Name()
 		}
-
-		return nil
-	})
-	if err != nil {
-		return nil, err
 	}
 
 	if latestFile == "" {
-		return nil, ErrNotFound
+		return zero, time.Time{}, ErrNotFound
 	}
 
 	file, err := root.OpenFile(latestFile, os.O_RDONLY, 0)
 	if err != nil {
-		return nil, err
+		return zero, time.Time{}, err
 	}
 	defer file.Close()
 
-	var data []T
-	if err := json.NewDecoder(file).Decode(&data); err != nil {
-		return nil, err
+	b, err := io.ReadAll(file)
+	if err != nil {
+		return zero, time.Time{}, err
 	}
 
-	return data, nil
+	data, err := decode[T](b)
+	if errors.Is(err, errIncompatible) {
+		// A corrupt or outdated file is discarded, so it's rebuilt.
+		_ = root.Remove(latestFile)
+		return zero, time.Time{}, ErrNotFound
+	}
+	if err != nil {
+		return zero, time.Time{}, err
+	}
+
+	return data, time.Unix(latestTimestamp, 0), nil
 }
 
 func SaveMany[T any](dir, key string, data []T, expiry time.Time) error {
+	return save(dir, key, data, expiry)
+}
+
+func save[T any](dir, key string, data T, expiry time.Time) error {
 	if err := os.MkdirAll(dir, 0o755); err != nil {
 		return fmt.Errorf("failed to create directory: %w", err)
 	}
@@ -105,14 +133,13 @@ func SaveMany[T any](dir, key string, data []T, expiry time.Time) error {
 		return err
 	}
 
-	filename := fmt.Sprintf("%s-%d.json", key, expiry.Unix())
-	file, err := root.OpenFile(filename, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+	b, err := encode(data)
 	if err != nil {
-		return err
+		return fmt.Errorf("error marshalling %q: %w", key, err)
 	}
-	defer file.Close()
 
-	if err := json.NewEncoder(file).Encode(data); err != nil {
+	filename := fmt.Sprintf("%s-%d.json", key, expiry.Unix())
+	if err := WriteFile(filepath.Join(dir, filename), b, 0o644); err != nil {
 		return err
 	}
 
@@ -124,31 +151,27 @@ func SaveMany[T any](dir, key string, data []T, expiry time.Time) error {
 }
 
 func cleanupOldCacheFiles(root *os.Root, key string, latestTimestamp time.Time) error {
-	pattern := fmt.Sprintf("%s-%%d.json", key)
-	err := fs.WalkDir(root.FS(), ".", func(path string, d fs.DirEntry, err error) error {
-		if err != nil {
-			return err
-		}
+	entries, err := fs.ReadDir(root.FS(), ".")
+	if err != nil {
+		return fmt.Errorf("failed to cleanup old cache files: %w", err)
+	}
 
+	pattern := fmt.Sprintf("%s-%%d.json", key)
+	for _, d := range entries {
 		if d.IsDir() {
-			return nil
+			continue
 		}
 
 		var timestamp int64
 		if _, err := fmt.Sscanf(d.Name(), pattern, &timestamp); err != nil {
-			return nil
+			continue
 		}
 
 		if timestamp < latestTimestamp.Unix() {
-			if err := root.Remove(path); err != nil {
-				return fmt.Errorf("failed to remove expired cache file %q: %w", path, err)
+			if err := root.Remove(d.Name()); err != nil {
+				return fmt.Errorf("failed to remove expired cache file %q: %w", d.Name(), err)
 			}
 		}
-
-		return nil
-	})
-	if err != nil {
-		return fmt.Errorf("failed to cleanup old cache files: %w", err)
 	}
 
 	return nil
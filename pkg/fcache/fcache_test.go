@@ -0,0 +1,121 @@
+package fcache_test
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/zkhvan/z/pkg/assert"
+	"github.com/zkhvan/z/pkg/fcache"
+)
+
+func TestLoadMany(t *testing.T) {
+	tests := map[string]struct {
+		// content is written to the cache file instead of saving the data.
+		content  string
+		expected []string
+		err      error
+	}{
+		"saved data": {
+			expected: []string{"a", "b"},
+		},
+		"corrupt file": {
+			content: `{"version": 1, "data": ["a",`,
+			err:     fcache.ErrNotFound,
+		},
+		"previous format": {
+			content: `["a", "b"]`,
+			err:     fcache.ErrNotFound,
+		},
+		"other schema version": {
+			content: fmt.Sprintf(`{"version": %d, "data": ["a"]}`, fcache.SchemaVersion+1),
+			err:     fcache.ErrNotFound,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			expiry := time.Now().Add(time.Hour)
+
+			err := fcache.SaveMany(dir, "key", []string{"a", "b"}, expiry)
+			assert.NoError(t, err)
+
+			file := filepath.Join(dir, fmt.Sprintf("key-%d.json", expiry.Unix()))
+			if tc.content != "" {
+				assert.NoError(t, os.WriteFile(file, []byte(tc.content), 0o644))
+			}
+
+			data, err := fcache.LoadMany[string](dir, "key")
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("expected error %v, got %v", tc.err, err)
+			}
+
+			if diff := cmp.Diff(tc.expected, data); diff != "" {
+				t.Errorf("data mismatch (-want +got):\n%s", diff)
+			}
+
+			// An incompatible file is discarded.
+			if _, err := os.Stat(file); (err == nil) != (tc.err == nil) {
+				t.Errorf("unexpected cache file state: %v", err)
+			}
+
+			// Nothing but the cache file is left in the directory.
+			entries, err := os.ReadDir(dir)
+			assert.NoError(t, err)
+			if len(entries) > 1 {
+				t.Errorf("expected at most 1 file, got %d", len(entries))
+			}
+		})
+	}
+}
+
+func TestLoad_Corrupt(t *testing.T) {
+	dir := t.TempDir()
+
+	assert.NoError(t, fcache.Save(dir, "key", "value"))
+
+	v, err := fcache.Load[string](dir, "key")
+	assert.NoError(t, err)
+	assert.EqualString(t, "value", v)
+
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "key.json"), []byte(`"value"`), 0o644))
+
+	if _, err := fcache.Load[string](dir, "key"); !errors.Is(err, fcache.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestLoadMany_SkipsSubdirectories(t *testing.T) {
+	dir := t.TempDir()
+	expiry := time.Now().Add(time.Hour)
+
+	// A file of another cache in a subdirectory, named like a key.
+	sub := filepath.Join(dir, "gh")
+	assert.NoError(t, os.MkdirAll(sub, 0o755))
+	assert.NoError(t, fcache.SaveMany(sub, "key", []string{"other"}, expiry.Add(time.Hour)))
+
+	if _, err := fcache.LoadMany[string](dir, "key"); !errors.Is(err, fcache.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+
+	assert.NoError(t, fcache.SaveMany(dir, "key", []string{"a"}, expiry))
+
+	data, err := fcache.LoadMany[string](dir, "key")
+	assert.NoError(t, err)
+	if diff := cmp.Diff([]string{"a"}, data); diff != "" {
+		t.Errorf("data mismatch (-want +got):\n%s", diff)
+	}
+
+	// Saving doesn't clean up the files of the subdirectory.
+	files, err := filepath.Glob(filepath.Join(sub, "key-*.json"))
+	assert.NoError(t, err)
+	if len(files) != 1 {
+		t.Errorf("expected the file of the subdirectory to be kept, got %v", files)
+	}
+}
@@ -0,0 +1,194 @@
+package fcache
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Entry describes a file of the cache.
+type Entry struct {
+	// Key is the key the value is saved under. Files of subdirectories, like
+	// the API responses in "gh", are keyed by their path, e.g. "gh/<hash>".
+	Key string `json:"key"`
+	// Path is the path of the file, relative to the cache directory.
+	Path string `json:"path"`
+	// Expiry is zero for values saved without expiry.
+	Expiry  time.Time `json:"expiry,omitzero"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mod_time"`
+	// Version is the schema version of the file, or 0 if the file wasn't
+	// written by this package or an older version of it.
+	Version int `json:"version"`
+	// Count is the number of items of a list value, or -1.
+	Count int `json:"count"`
+}
+
+// Expired reports whether the value of the entry expired.
+func (e Entry) Expired() bool {
+	return !e.Expiry.IsZero() && e.Expiry.Before(time.Now())
+}
+
+// Namespace returns the namespace of the entry: its subdirectory, or its key
+// up to the last dot, e.g. "projects.remote" for "projects.remote.<hash>".
+func (e Entry) Namespace() string {
+	if dir, _, ok := strings.Cut(e.Key, "/"); ok {
+		return dir
+	}
+
+	if i := strings.LastIndex(e.Key, "."); i > 0 {
+		return e.Key[:i]
+	}
+
+	return e.Key
+}
+
+// List returns the entries of the cache, sorted by key.
+func List(dir string) ([]Entry, error) {
+	var entries []Entry
+
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if errors.Is(err, fs.ErrNotExist) && path == dir {
+			return fs.SkipAll
+		}
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() || !isCacheFile(d.Name()) {
+			return nil
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+
+		entry, err := readEntry(path, filepath.ToSlash(rel))
+		if err != nil {
+			return err
+		}
+
+		entries = append(entries, entry)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	slices.SortFunc(entries, func(a, b Entry) int {
+		return strings.Compare(a.Key, b.Key)
+	})
+
+	return entries, nil
+}
+
+// Read returns the entry of the key and its value. The value of a file which
+// wasn't written by this package is returned as is. If several files are
+// saved under the key, the one expiring last is read.
+func Read(dir, key string) (Entry, json.RawMessage, error) {
+	entries, err := List(dir)
+	if err != nil {
+		return Entry{}, nil, err
+	}
+
+	var found *Entry
+	for i, e := range entries {
+		if e.Key == key && (found == nil || e.Expiry.After(found.Expiry)) {
+			found = &entries[i]
+		}
+	}
+	if found == nil {
+		return Entry{}, nil, ErrNotFound
+	}
+
+	b, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(found.Path)))
+	if err != nil {
+		return Entry{}, nil, err
+	}
+
+	var e envelope[json.RawMessage]
+	if found.Version > 0 && json.Unmarshal(b, &e) == nil {
+		return *found, e.Data, nil
+	}
+
+	return *found, b, nil
+}
+
+// Clear removes the files of the key, or of every key of the namespace if the
+// key is a namespace. Every file is removed if the key is empty. It returns the
+// number of removed files.
+func Clear(dir, key string) (int, error) {
+	entries, err := List(dir)
+	if err != nil {
+		return 0, err
+	}
+
+	var removed int
+	for _, e := range entries {
+		if key != "" && e.Key != key && !strings.HasPrefix(e.Key, key+".") && !strings.HasPrefix(e.Key, key+"/") {
+			continue
+		}
+
+		if err := os.Remove(filepath.Join(dir, filepath.FromSlash(e.Path))); err != nil {
+			return removed, fmt.Errorf("failed to remove %q: %w", e.Path, err)
+		}
+		removed++
+	}
+
+	return removed, nil
+}
+
+// isCacheFile reports whether the file holds a value, unlike locks and the
+// temporary files of WriteFile.
+func isCacheFile(name string) bool {
+	return strings.HasSuffix(name, ".json") && !strings.HasPrefix(name, ".")
+}
+
+func readEntry(path, rel string) (Entry, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return Entry{}, err
+	}
+
+	entry := Entry{
+		Key:     strings.TrimSuffix(rel, ".json"),
+		Path:    rel,
+		Size:    info.Size(),
+		ModTime: info.ModTime(),
+		Count:   -1,
+	}
+
+	// Values saved with an expiry are in "<key>-<unix expiry>.json" files.
+	if i := strings.LastIndex(entry.Key, "-"); i > 0 {
+		if ts, err := strconv.ParseInt(entry.Key[i+1:], 10, 64); err == nil {
+			entry.Key = entry.Key[:i]
+			entry.Expiry = time.Unix(ts, 0)
+		}
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return Entry{}, err
+	}
+
+	var e envelope[json.RawMessage]
+	if json.Unmarshal(b, &e) == nil {
+		entry.Version = e.Version
+
+		var items []json.RawMessage
+		if bytes.HasPrefix(bytes.TrimSpace(e.Data), []byte("[")) && json.Unmarshal(e.Data, &items) == nil {
+			entry.Count = len(items)
+		}
+	}
+
+	return entry, nil
+}
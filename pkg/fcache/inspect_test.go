@@ -0,0 +1,71 @@
+package fcache_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/zkhvan/z/pkg/assert"
+	"github.com/zkhvan/z/pkg/fcache"
+)
+
+func TestInspect(t *testing.T) {
+	dir := t.TempDir()
+	expiry := time.Now().Add(time.Hour).Truncate(time.Second)
+
+	ns := fcache.NewNamespace[[]string](dir, "projects.remote")
+	assert.NoError(t, ns.Save("a", []string{"x", "y"}, expiry))
+	assert.NoError(t, ns.Save("b", []string{"z"}, time.Now().Add(-time.Hour)))
+	assert.NoError(t, fcache.Save(dir, "projects.status", map[string]int{"n": 1}))
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "gh"), 0o755))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "gh", "abc.json"), []byte(`{"etag":"e"}`), 0o644))
+
+	entries, err := fcache.List(dir)
+	assert.NoError(t, err)
+
+	type summary struct {
+		Key       string
+		Namespace string
+		Version   int
+		Count     int
+		Expired   bool
+	}
+	var got []summary
+	for _, e := range entries {
+		got = append(got, summary{e.Key, e.Namespace(), e.Version, e.Count, e.Expired()})
+	}
+
+	expected := []summary{
+		{"gh/abc", "gh", 0, -1, false},
+		{"projects.remote.a", "projects.remote", 1, 2, false},
+		{"projects.remote.b", "projects.remote", 1, 1, true},
+		{"projects.status", "projects", 1, -1, false},
+	}
+	if diff := cmp.Diff(expected, got); diff != "" {
+		t.Errorf("entries mismatch (-want +got):\n%s", diff)
+	}
+
+	entry, value, err := fcache.Read(dir, "projects.remote.a")
+	assert.NoError(t, err)
+	assert.EqualString(t, `["x","y"]`, string(value))
+	if !entry.Expiry.Equal(expiry) {
+		t.Errorf("expected expiry %v, got %v", expiry, entry.Expiry)
+	}
+
+	keys, err := ns.Keys()
+	assert.NoError(t, err)
+	if diff := cmp.Diff([]string{"a", "b"}, keys); diff != "" {
+		t.Errorf("keys mismatch (-want +got):\n%s", diff)
+	}
+
+	assert.NoError(t, ns.Clear())
+
+	removed, err := fcache.Clear(dir, "")
+	assert.NoError(t, err)
+	if removed != 2 {
+		t.Errorf("expected 2 removed entries, got %d", removed)
+	}
+}
@@ -0,0 +1,19 @@
+package fcache
+
+import "errors"
+
+// ErrLocked is returned by TryLock when the lock is held by another process.
+var ErrLocked = errors.New("locked")
+
+// TryLock takes the advisory lock of the key without waiting. The returned
+// function releases it. The lock is released when the process exits, even if
+// it crashes.
+func TryLock(dir, key string) (func() error, error) {
+	return lock(dir, key, false)
+}
+
+// Lock takes the advisory lock of the key, waiting until it's released by
+// other processes. The returned function releases it.
+func Lock(dir, key string) (func() error, error) {
+	return lock(dir, key, true)
+}
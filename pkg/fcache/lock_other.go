@@ -0,0 +1,9 @@
+//go:build !unix
+
+package fcache
+
+// lock doesn't lock on platforms without flock, at worst a refresh runs
+// twice.
+func lock(_, _ string, _ bool) (func() error, error) {
+	return func() error { return nil }, nil
+}
@@ -0,0 +1,43 @@
+//go:build unix
+
+package fcache
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+func lock(dir, key string, wait bool) (func() error, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	file, err := os.OpenFile(filepath.Join(dir, key+".lock"), os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	how := syscall.LOCK_EX
+	if !wait {
+		how |= syscall.LOCK_NB
+	}
+
+	if err := syscall.Flock(int(file.Fd()), how); err != nil {
+		file.Close()
+		if errors.Is(err, syscall.EWOULDBLOCK) {
+			return nil, ErrLocked
+		}
+		return nil, fmt.Errorf("failed to lock %q: %w", key, err)
+	}
+
+	return func() error {
+		if err := syscall.Flock(int(file.Fd()), syscall.LOCK_UN); err != nil {
+			file.Close()
+			return err
+		}
+		return file.Close()
+	}, nil
+}
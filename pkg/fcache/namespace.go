@@ -0,0 +1,62 @@
+package fcache
+
+import (
+	"strings"
+	"time"
+)
+
+// Namespace caches values of the same type under keys prefixed with its name,
+// e.g. the repositories of each remote pattern under "projects.remote".
+type Namespace[T any] struct {
+	dir  string
+	name string
+}
+
+func NewNamespace[T any](dir, name string) *Namespace[T] {
+	return &Namespace[T]{dir: dir, name: name}
+}
+
+// Key returns the cache key of the key in the namespace.
+func (n *Namespace[T]) Key(key string) string {
+	return n.name + "." + key
+}
+
+// Load loads the value saved under the key, unless it's expired.
+func (n *Namespace[T]) Load(key string) (T, error) {
+	v, _, err := load[T](n.dir, n.Key(key), false)
+	return v, err
+}
+
+// LoadLatest loads the value saved under the key, even if it's expired. It
+// also returns the expiry of the value.
+func (n *Namespace[T]) LoadLatest(key string) (T, time.Time, error) {
+	return load[T](n.dir, n.Key(key), true)
+}
+
+// Save saves the value under the key until the expiry.
+func (n *Namespace[T]) Save(key string, v T, expiry time.Time) error {
+	return save(n.dir, n.Key(key), v, expiry)
+}
+
+// Keys returns the keys of the namespace, without its name.
+func (n *Namespace[T]) Keys() ([]string, error) {
+	entries, err := List(n.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []string
+	for _, e := range entries {
+		if key, ok := strings.CutPrefix(e.Key, n.name+"."); ok {
+			keys = append(keys, key)
+		}
+	}
+
+	return keys, nil
+}
+
+// Clear removes the values of the namespace.
+func (n *Namespace[T]) Clear() error {
+	_, err := Clear(n.dir, n.name)
+	return err
+}
@@ -0,0 +1,41 @@
+package fcache
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// SchemaVersion is the version of the format of the cache files. Bump it when
+// the format of the cached data changes incompatibly, the files of other
+// versions are then discarded and rebuilt.
+const SchemaVersion = 1
+
+// errIncompatible is returned when decoding a corrupt cache file or one of
+// another schema version.
+var errIncompatible = errors.New("incompatible cache file")
+
+// envelope wraps the cached data with the version of its schema.
+type envelope[T any] struct {
+	Version int `json:"version"`
+	Data    T   `json:"data"`
+}
+
+func encode[T any](v T) ([]byte, error) {
+	return json.Marshal(envelope[T]{Version: SchemaVersion, Data: v})
+}
+
+func decode[T any](b []byte) (T, error) {
+	var e envelope[T]
+	if err := json.Unmarshal(b, &e); err != nil {
+		var zero T
+		return zero, fmt.Errorf("%w: %w", errIncompatible, err)
+	}
+
+	if e.Version != SchemaVersion {
+		var zero T
+		return zero, fmt.Errorf("%w: version %d, want %d", errIncompatible, e.Version, SchemaVersion)
+	}
+
+	return e.Data, nil
+}
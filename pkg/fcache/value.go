@@ -0,0 +1,45 @@
+package fcache
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// Load loads a single value saved under the key with Save.
+func Load[T any](dir, key string) (T, error) {
+	var v T
+
+	b, err := os.ReadFile(filepath.Join(dir, key+".json"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return v, ErrNotFound
+	}
+	if err != nil {
+		return v, err
+	}
+
+	v, err = decode[T](b)
+	if errors.Is(err, errIncompatible) {
+		// A corrupt or outdated file is discarded, so it's rebuilt.
+		_ = os.Remove(filepath.Join(dir, key+".json"))
+		return v, ErrNotFound
+	}
+
+	return v, err
+}
+
+// Save saves a single value under the key, without expiry.
+func Save[T any](dir, key string, v T) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	b, err := encode(v)
+	if err != nil {
+		return fmt.Errorf("error marshalling %q: %w", key, err)
+	}
+
+	return WriteFile(filepath.Join(dir, key+".json"), b, 0o644)
+}
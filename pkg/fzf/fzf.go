@@ -18,10 +18,26 @@ type Binding[T any] struct {
 	Action func(item T) error
 }
 
+// Preview shows the output of a command for the focused item.
+type Preview[T any] struct {
+	// Command is the shell command printing the preview, run with the
+	// argument of the item appended.
+	Command string
+
+	// Argument returns the argument of the command for the item. It's
+	// hidden from the list.
+	Argument func(item T) string
+
+	// Window is the layout of the preview window, e.g. "right:50%", see the
+	// --preview-window option of fzf.
+	Window string
+}
+
 type Options[T any] struct {
 	Iterator func(item T, index int) string
 	Bindings []Binding[T]
 	Header   string
+	Preview  *Preview[T]
 }
 
 type Option[T any] func(*Options[T])
@@ -47,6 +63,14 @@ func WithHeader[T any](header string) Option[T] {
 	}
 }
 
+// WithPreview shows the preview of the focused item, which is toggled with
+// CTRL-/.
+func WithPreview[T any](preview Preview[T]) Option[T] {
+	return func(opts *Options[T]) {
+		opts.Preview = &preview
+	}
+}
+
 func One[T any](
 	ctx context.Context,
 	items []T,
@@ -67,7 +91,12 @@ func One[T any](
 
 	inputs := make([]string, 0, len(items))
 	for index, item := range items {
-		inputs = append(inputs, opts.Iterator(item, index))
+		input := opts.Iterator(item, index)
+		if opts.Preview != nil {
+			// The argument of the preview is a hidden field.
+			input += "\t" + opts.Preview.Argument(item)
+		}
+		inputs = append(inputs, input)
 	}
 
 	args := []string{"--bind", "enter:become(echo {+n})"}
@@ -82,6 +111,18 @@ func One[T any](
 		args = append(args, "--header", opts.Header)
 	}
 
+	if opts.Preview != nil {
+		args = append(args,
+			"--delimiter", "\t",
+			"--with-nth", "1",
+			"--preview", opts.Preview.Command+" {2}",
+			"--bind", "ctrl-/:toggle-preview",
+		)
+		if opts.Preview.Window != "" {
+			args = append(args, "--preview-window", opts.Preview.Window)
+		}
+	}
+
 	cmd := exec.CommandContext(
 		ctx,
 		"fzf",
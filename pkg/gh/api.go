@@ -0,0 +1,188 @@
+package gh
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zkhvan/z/pkg/httplib"
+)
+
+var ErrNotFound = errors.New("not found")
+
+// RateLimitError is returned when the API rate limit is exceeded.
+type RateLimitError struct {
+	Host  string
+	Limit int
+	// Reset is when the rate limit resets.
+	Reset time.Time
+}
+
+func (e *RateLimitError) Error() string {
+	msg := fmt.Sprintf("GitHub API rate limit exceeded for %s", e.Host)
+	if e.Limit > 0 {
+		msg += fmt.Sprintf(" (%d requests per hour)", e.Limit)
+	}
+	if !e.Reset.IsZero() {
+		msg += fmt.Sprintf(", resets at %s", e.Reset.Local().Format(time.Kitchen))
+	}
+	return msg
+}
+
+// StatusError is returned when the API responds with an unexpected status.
+type StatusError struct {
+	URL        string
+	StatusCode int
+	Status     string
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("error requesting %q: %s: %s", e.URL, e.Status, e.Body)
+}
+
+// fallBack reports whether a failed API request is retried with the gh CLI,
+// which might get through where the API doesn't, e.g. on a network error, a
+// server error, or a 403 of an organization enforcing SAML SSO for tokens.
+// Missing resources and rate limits aren't retried.
+func fallBack(ctx context.Context, err error) bool {
+	var rateLimitErr *RateLimitError
+	switch {
+	case err == nil, ctx.Err() != nil, errors.Is(err, ErrNotFound), errors.As(err, &rateLimitErr):
+		return false
+	}
+
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode >= http.StatusInternalServerError || statusErr.StatusCode == http.StatusForbidden
+	}
+
+	return true
+}
+
+// apiURL returns the URL of the API path.
+func (c *Client) apiURL(path string) string {
+	baseURL := c.baseURL
+	switch {
+	case baseURL != "":
+	case c.Host() == DefaultHost:
+		baseURL = "https://api.github.com"
+	default:
+		baseURL = fmt.Sprintf("https://%s/api/v3", c.Host())
+	}
+
+	return baseURL + path
+}
+
+// get requests the API URL and decodes the JSON response into v. It returns
+// the URL of the next page, or an empty string if it's the last page.
+//
+// If a response cache is set, the request is conditional on the ETag of the
+// cached response, and the cached response is used if it's not modified.
+func (c *Client) get(ctx context.Context, u string, v any) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return "", err
+	}
+
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	if token := c.Token(ctx); token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	cached, ok := c.cachedResponse(u)
+	if ok {
+		req.Header.Set("If-None-Match", cached.ETag)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("error requesting %q: %w", u, err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified && ok:
+	case resp.StatusCode == http.StatusOK:
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return "", fmt.Errorf("error reading response from %q: %w", u, err)
+		}
+
+		cached = CachedResponse{
+			ETag: resp.Header.Get("ETag"),
+			Link: resp.Header.Get("Link"),
+			Body: body,
+		}
+		if c.cache != nil && cached.ETag != "" {
+			if err := c.cache.Set(u, cached); err != nil {
+				return "", fmt.Errorf("error caching response from %q: %w", u, err)
+			}
+		}
+	case resp.StatusCode == http.StatusNotFound:
+		return "", fmt.Errorf("error requesting %q: %w", u, ErrNotFound)
+	case isRateLimited(resp):
+		return "", c.rateLimitError(resp)
+	default:
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return "", &StatusError{
+			URL:        u,
+			StatusCode: resp.StatusCode,
+			Status:     resp.Status,
+			Body:       strings.TrimSpace(string(body)),
+		}
+	}
+
+	if err := json.NewDecoder(bytes.NewReader(cached.Body)).Decode(v); err != nil {
+		return "", fmt.Errorf("error decoding response from %q: %w", u, err)
+	}
+
+	return httplib.NextLink(cached.Link, resp.Request.URL), nil
+}
+
+func (c *Client) cachedResponse(u string) (CachedResponse, bool) {
+	if c.cache == nil {
+		return CachedResponse{}, false
+	}
+
+	cached, ok := c.cache.Get(u)
+	if !ok || cached.ETag == "" {
+		return CachedResponse{}, false
+	}
+
+	return cached, true
+}
+
+// isRateLimited reports whether the response is a primary or secondary rate
+// limit error.
+func isRateLimited(resp *http.Response) bool {
+	if resp.StatusCode != http.StatusForbidden && resp.StatusCode != http.StatusTooManyRequests {
+		return false
+	}
+
+	return resp.Header.Get("X-RateLimit-Remaining") == "0" || resp.Header.Get("Retry-After") != ""
+}
+
+func (c *Client) rateLimitError(resp *http.Response) error {
+	err := &RateLimitError{Host: c.Host()}
+
+	if limit, convErr := strconv.Atoi(resp.Header.Get("X-RateLimit-Limit")); convErr == nil {
+		err.Limit = limit
+	}
+
+	if seconds, convErr := strconv.Atoi(resp.Header.Get("Retry-After")); convErr == nil {
+		err.Reset = time.Now().Add(time.Duration(seconds) * time.Second)
+	} else if reset, convErr := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); convErr == nil {
+		err.Reset = time.Unix(reset, 0)
+	}
+
+	return err
+}
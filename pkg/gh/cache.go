@@ -0,0 +1,69 @@
+package gh
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/zkhvan/z/pkg/fcache"
+)
+
+// CachedResponse is an API response kept to make conditional requests.
+type CachedResponse struct {
+	ETag string `json:"etag"`
+	Link string `json:"link,omitempty"`
+	Body []byte `json:"body"`
+}
+
+// ResponseCache stores API responses by URL.
+type ResponseCache interface {
+	Get(url string) (CachedResponse, bool)
+	Set(url string, resp CachedResponse) error
+}
+
+// FileCache is a ResponseCache storing each response in a file of a
+// directory.
+type FileCache struct {
+	dir string
+}
+
+var _ ResponseCache = (*FileCache)(nil)
+
+func NewFileCache(dir string) *FileCache {
+	return &FileCache{dir: dir}
+}
+
+func (c *FileCache) Get(url string) (CachedResponse, bool) {
+	b, err := os.ReadFile(c.path(url))
+	if err != nil {
+		return CachedResponse{}, false
+	}
+
+	var resp CachedResponse
+	if err := json.Unmarshal(b, &resp); err != nil {
+		return CachedResponse{}, false
+	}
+
+	return resp, true
+}
+
+func (c *FileCache) Set(url string, resp CachedResponse) error {
+	if err := os.MkdirAll(c.dir, 0o755); err != nil {
+		return fmt.Errorf("error creating cache directory: %w", err)
+	}
+
+	b, err := json.Marshal(resp)
+	if err != nil {
+		return fmt.Errorf("error marshalling: %w", err)
+	}
+
+	return fcache.WriteFile(c.path(url), b, 0o600)
+}
+
+func (c *FileCache) path(url string) string {
+	sum := sha256.Sum256([]byte(url))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:])+".json")
+}
@@ -1,20 +1,111 @@
 package gh
 
 import (
+	"context"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
 	"github.com/zkhvan/z/pkg/exec"
 )
 
+// DefaultHost is the host gh uses unless told otherwise.
+const DefaultHost = "github.com"
+
 var defaultExecutor exec.Interface = exec.New()
 
+// Client talks to GitHub through its REST API when a token is available, and
+// falls back to the gh CLI otherwise.
 type Client struct {
 	executor exec.Interface
+
+	// host is the GitHub host to run the commands against, e.g. a GitHub
+	// Enterprise Server. If empty, gh picks the host.
+	host string
+
+	baseURL    string
+	token      string
+	httpClient *http.Client
+	cache      ResponseCache
+
+	// auth holds the token looked up by Token, shared by the copies of the
+	// client for the same host.
+	auth *auth
+}
+
+type auth struct {
+	once  sync.Once
+	token string
 }
 
 func NewClient() *Client {
-	return &Client{executor: defaultExecutor}
+	return &Client{
+		executor:   defaultExecutor,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		auth:       &auth{},
+	}
 }
 
 func (c *Client) SetExecutor(executor exec.Interface) *Client {
 	c.executor = executor
 	return c
 }
+
+// SetBaseURL overrides the API base URL, which defaults to
+// https://api.github.com, or https://<host>/api/v3 for other hosts.
+func (c *Client) SetBaseURL(baseURL string) *Client {
+	c.baseURL = strings.TrimSuffix(baseURL, "/")
+	return c
+}
+
+// SetToken sets the token used to authenticate, see Token.
+func (c *Client) SetToken(token string) *Client {
+	c.token = token
+	return c
+}
+
+func (c *Client) SetHTTPClient(httpClient *http.Client) *Client {
+	c.httpClient = httpClient
+	return c
+}
+
+// SetResponseCache sets the cache of API responses used to make conditional
+// requests. Without a cache, every request is a full one.
+func (c *Client) SetResponseCache(cache ResponseCache) *Client {
+	c.cache = cache
+	return c
+}
+
+// WithHost returns a copy of the client that runs the commands against the
+// host.
+func (c *Client) WithHost(host string) *Client {
+	clone := *c
+	clone.host = host
+	clone.baseURL = ""
+	clone.token = ""
+	clone.auth = &auth{}
+	return &clone
+}
+
+// Host returns the host of the client, which is DefaultHost if not set.
+func (c *Client) Host() string {
+	if c.host == "" {
+		return DefaultHost
+	}
+	return c.host
+}
+
+// command returns a gh command. Commands against another host than
+// DefaultHost are routed with GH_HOST, since not every gh command supports
+// --hostname.
+func (c *Client) command(ctx context.Context, args ...string) exec.Cmd {
+	cmd := c.executor.CommandContext(ctx, "gh", args...)
+
+	if c.host != "" && c.host != DefaultHost {
+		cmd.SetEnv(append(os.Environ(), "GH_HOST="+c.host))
+	}
+
+	return cmd
+}
@@ -16,9 +16,9 @@ func (c *Client) Clone(ctx context.Context, url, path string) (string, error) {
 		return "", errors.New("path is required")
 	}
 
-	cmd := c.executor.CommandContext(
+	cmd := c.command(
 		ctx,
-		"gh", "repo", "clone",
+		"repo", "clone",
 		url, path,
 	)
 
@@ -6,11 +6,21 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net/url"
+	"strings"
+	"time"
 )
 
 type Repo struct {
 	Owner string `json:"owner"`
 	Name  string `json:"name"`
+
+	// The fields below are set by GetRepo and the listings.
+	Description string    `json:"description,omitempty"`
+	Language    string    `json:"language,omitempty"`
+	Stars       int       `json:"stars,omitempty"`
+	PushedAt    time.Time `json:"pushed_at,omitzero"`
+	URL         string    `json:"url,omitempty"`
 }
 
 func (r *Repo) String() string {
@@ -30,11 +40,18 @@ func (c *Client) ListRepos(ctx context.Context, opts *RepoListOptions) ([]*Repo,
 		return nil, errors.New("owner is required")
 	}
 
-	cmd := c.executor.CommandContext(
+	if c.Token(ctx) != "" {
+		repos, err := c.listReposAPI(ctx, opts.Owner)
+		if !fallBack(ctx, err) {
+			return repos, err
+		}
+	}
+
+	cmd := c.command(
 		ctx,
-		"gh", "repo", "list",
+		"repo", "list",
 		"--limit", "9999",
-		opts.Owner, "--json", "owner,name",
+		opts.Owner, "--json", cliRepoFields,
 	)
 
 	output, err := cmd.Output()
@@ -44,25 +61,127 @@ func (c *Client) ListRepos(ctx context.Context, opts *RepoListOptions) ([]*Repo,
 
 	output = bytes.TrimSpace(output)
 
-	type nameOwner struct {
-		Name  string `json:"name"`
-		Owner struct {
-			Login string `json:"login"`
-		} `json:"owner"`
+	var cliRepos []cliRepo
+	if err := json.Unmarshal(output, &cliRepos); err != nil {
+		return nil, fmt.Errorf("error unmarshalling: %w", err)
 	}
 
-	var nameOwners []*nameOwner
-	if err := json.Unmarshal(output, &nameOwners); err != nil {
-		return nil, fmt.Errorf("error unmarshalling: %w", err)
+	var repos []*Repo
+	for _, r := range cliRepos {
+		repos = append(repos, r.toRepo())
 	}
 
+	return repos, nil
+}
+
+// cliRepoFields are the JSON fields of a repository requested from the gh
+// CLI.
+const cliRepoFields = "owner,name,description,primaryLanguage,stargazerCount,pushedAt,url"
+
+// cliRepo is a repository as returned by the gh CLI.
+type cliRepo struct {
+	Name  string `json:"name"`
+	Owner struct {
+		Login string `json:"login"`
+	} `json:"owner"`
+	Description     string `json:"description"`
+	PrimaryLanguage struct {
+		Name string `json:"name"`
+	} `json:"primaryLanguage"`
+	StargazerCount int       `json:"stargazerCount"`
+	PushedAt       time.Time `json:"pushedAt"`
+	URL            string    `json:"url"`
+}
+
+func (r cliRepo) toRepo() *Repo {
+	return &Repo{
+		Owner:       r.Owner.Login,
+		Name:        r.Name,
+		Description: r.Description,
+		Language:    r.PrimaryLanguage.Name,
+		Stars:       r.StargazerCount,
+		PushedAt:    r.PushedAt,
+		URL:         r.URL,
+	}
+}
+
+// apiRepo is a repository as returned by the REST API.
+type apiRepo struct {
+	Name  string `json:"name"`
+	Owner struct {
+		Login string `json:"login"`
+	} `json:"owner"`
+	Description string    `json:"description"`
+	Language    string    `json:"language"`
+	Stars       int       `json:"stargazers_count"`
+	PushedAt    time.Time `json:"pushed_at"`
+	HTMLURL     string    `json:"html_url"`
+}
+
+func (r apiRepo) toRepo() *Repo {
+	return &Repo{
+		Owner:       r.Owner.Login,
+		Name:        r.Name,
+		Description: r.Description,
+		Language:    r.Language,
+		Stars:       r.Stars,
+		PushedAt:    r.PushedAt,
+		URL:         r.HTMLURL,
+	}
+}
+
+// listReposAPI lists the repositories of an organization or, if there's no
+// such organization, of a user. The private repositories of the
+// authenticated user are included.
+func (c *Client) listReposAPI(ctx context.Context, owner string) ([]*Repo, error) {
+	repos, err := c.listReposPages(ctx, c.apiURL("/orgs/"+url.PathEscape(owner)+"/repos?per_page=100"))
+	if !errors.Is(err, ErrNotFound) {
+		return repos, err
+	}
+
+	viewer, err := c.viewer(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	u := c.apiURL("/users/" + url.PathEscape(owner) + "/repos?type=owner&per_page=100")
+	if strings.EqualFold(viewer, owner) {
+		u = c.apiURL("/user/repos?affiliation=owner&per_page=100")
+	}
+
+	return c.listReposPages(ctx, u)
+}
+
+func (c *Client) listReposPages(ctx context.Context, u string) ([]*Repo, error) {
 	var repos []*Repo
-	for _, r := range nameOwners {
-		repos = append(repos, &Repo{
-			Owner: r.Owner.Login,
-			Name:  r.Name,
-		})
+
+	for u != "" {
+		var page repoPage
+
+		next, err := c.get(ctx, u, &page)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, r := range page {
+			repos = append(repos, r.toRepo())
+		}
+
+		u = next
 	}
 
 	return repos, nil
 }
+
+// viewer returns the login of the authenticated user.
+func (c *Client) viewer(ctx context.Context) (string, error) {
+	var user struct {
+		Login string `json:"login"`
+	}
+
+	if _, err := c.get(ctx, c.apiURL("/user"), &user); err != nil {
+		return "", err
+	}
+
+	return user.Login, nil
+}
@@ -0,0 +1,116 @@
+package gh
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// ListViewerRepos returns the repositories owned by the authenticated user.
+func (c *Client) ListViewerRepos(ctx context.Context) ([]*Repo, error) {
+	return c.listReposAt(ctx, "/user/repos?affiliation=owner&per_page=100", ".[]")
+}
+
+// ListStarredRepos returns the repositories starred by the user, or by the
+// authenticated user if user is empty.
+func (c *Client) ListStarredRepos(ctx context.Context, user string) ([]*Repo, error) {
+	path := "/user/starred?per_page=100"
+	if user != "" {
+		path = "/users/" + url.PathEscape(user) + "/starred?per_page=100"
+	}
+
+	return c.listReposAt(ctx, path, ".[]")
+}
+
+// ListTeamRepos returns the repositories of an organization's team.
+func (c *Client) ListTeamRepos(ctx context.Context, org, team string) ([]*Repo, error) {
+	if org == "" || team == "" {
+		return nil, errors.New("organization and team are required")
+	}
+
+	path := fmt.Sprintf("/orgs/%s/teams/%s/repos?per_page=100", url.PathEscape(org), url.PathEscape(team))
+	return c.listReposAt(ctx, path, ".[]")
+}
+
+// SearchRepos returns the repositories matching a search query, e.g.
+// "topic:cli org:acme". The search API returns at most 1000 results.
+func (c *Client) SearchRepos(ctx context.Context, query string) ([]*Repo, error) {
+	if strings.TrimSpace(query) == "" {
+		return nil, errors.New("query is required")
+	}
+
+	path := "/search/repositories?per_page=100&q=" + url.QueryEscape(query)
+	return c.listReposAt(ctx, path, ".items[]")
+}
+
+// listReposAt lists the repositories of an API path through the API, or
+// through "gh api" if there's no token or the API request fails, see
+// fallBack. The jq filter selects the repositories of a page for "gh api".
+func (c *Client) listReposAt(ctx context.Context, path, jq string) ([]*Repo, error) {
+	if c.Token(ctx) != "" {
+		repos, err := c.listReposPages(ctx, c.apiURL(path))
+		if !fallBack(ctx, err) {
+			return repos, err
+		}
+	}
+
+	cmd := c.command(
+		ctx,
+		"api", "--paginate",
+		strings.TrimPrefix(path, "/"),
+		"--jq", jq,
+	)
+
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("error running command %q: %w", cmd.String(), err)
+	}
+
+	// The jq filter prints a repository per line.
+	var repos []*Repo
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	scanner.Buffer(nil, 1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		var r apiRepo
+		if err := json.Unmarshal(line, &r); err != nil {
+			return nil, fmt.Errorf("error unmarshalling: %w", err)
+		}
+		repos = append(repos, r.toRepo())
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error scanning: %w", err)
+	}
+
+	return repos, nil
+}
+
+// repoPage is a page of repositories, either a list or the items of search
+// results.
+type repoPage []apiRepo
+
+func (p *repoPage) UnmarshalJSON(b []byte) error {
+	if !bytes.HasPrefix(bytes.TrimSpace(b), []byte("{")) {
+		return json.Unmarshal(b, (*[]apiRepo)(p))
+	}
+
+	var results struct {
+		Items []apiRepo `json:"items"`
+	}
+	if err := json.Unmarshal(b, &results); err != nil {
+		return err
+	}
+
+	*p = results.Items
+	return nil
+}
@@ -0,0 +1,299 @@
+package gh_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/zkhvan/z/pkg/assert"
+	"github.com/zkhvan/z/pkg/exec"
+	testingexec "github.com/zkhvan/z/pkg/exec/testing"
+	"github.com/zkhvan/z/pkg/gh"
+)
+
+func TestListRepos(t *testing.T) {
+	tests := map[string]struct {
+		owner string
+		want  []string
+	}{
+		"organization repos should be paginated": {
+			owner: "acme",
+			want:  []string{"acme/api", "acme/web"},
+		},
+		"the viewer's repos should include private ones": {
+			owner: "me",
+			want:  []string{"me/private"},
+		},
+		"user repos should be listed when the organization doesn't exist": {
+			owner: "someone",
+			want:  []string{"someone/dotfiles"},
+		},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /orgs/{org}/repos", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		if r.PathValue("org") != "acme" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		switch r.URL.Query().Get("page") {
+		case "":
+			w.Header().Set("Link", `</orgs/acme/repos?per_page=100&page=2>; rel="next"`)
+			fmt.Fprint(w, `[{"name":"api","owner":{"login":"acme"}}]`)
+		case "2":
+			fmt.Fprint(w, `[{"name":"web","owner":{"login":"acme"}}]`)
+		default:
+			t.Errorf("unexpected page %q", r.URL.Query().Get("page"))
+		}
+	})
+	mux.HandleFunc("GET /user", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, `{"login":"me"}`)
+	})
+	mux.HandleFunc("GET /user/repos", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("affiliation") != "owner" {
+			t.Errorf("expected only the owned repos")
+		}
+		fmt.Fprint(w, `[{"name":"private","owner":{"login":"me"}}]`)
+	})
+	mux.HandleFunc("GET /users/{user}/repos", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `[{"name":"dotfiles","owner":{"login":%q}}]`, r.PathValue("user"))
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			client := gh.NewClient().SetBaseURL(server.URL).SetToken("secret")
+
+			repos, err := client.ListRepos(context.Background(), &gh.RepoListOptions{Owner: test.owner})
+			assert.NoError(t, err)
+
+			var got []string
+			for _, r := range repos {
+				got = append(got, r.String())
+			}
+
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Fatalf("repos mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestListRepos_ConditionalRequests(t *testing.T) {
+	var full, notModified int
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /orgs/acme/repos", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			notModified++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		full++
+		w.Header().Set("ETag", `"v1"`)
+		fmt.Fprint(w, `[{"name":"api","owner":{"login":"acme"}}]`)
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	client := gh.NewClient().
+		SetBaseURL(server.URL).
+		SetToken("secret").
+		SetResponseCache(gh.NewFileCache(t.TempDir()))
+
+	for range 2 {
+		repos, err := client.ListRepos(context.Background(), &gh.RepoListOptions{Owner: "acme"})
+		assert.NoError(t, err)
+
+		if len(repos) != 1 || repos[0].String() != "acme/api" {
+			t.Fatalf("expected acme/api, got %v", repos)
+		}
+	}
+
+	if full != 1 || notModified != 1 {
+		t.Fatalf("expected 1 full and 1 conditional request, got %d and %d", full, notModified)
+	}
+}
+
+func TestListRepos_RateLimit(t *testing.T) {
+	reset := time.Now().Add(10 * time.Minute).Truncate(time.Second)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("X-RateLimit-Limit", "5000")
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(reset.Unix(), 10))
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	t.Cleanup(server.Close)
+
+	client := gh.NewClient().SetBaseURL(server.URL).SetToken("secret")
+
+	_, err := client.ListRepos(context.Background(), &gh.RepoListOptions{Owner: "acme"})
+
+	var rateLimitErr *gh.RateLimitError
+	if !errors.As(err, &rateLimitErr) {
+		t.Fatalf("expected a rate limit error, got %v", err)
+	}
+
+	if rateLimitErr.Limit != 5000 || !rateLimitErr.Reset.Equal(reset) {
+		t.Fatalf("unexpected rate limit error: %+v", rateLimitErr)
+	}
+}
+
+func TestListRepos_FallbackToCLI(t *testing.T) {
+	for _, env := range []string{"GH_TOKEN", "GITHUB_TOKEN"} {
+		t.Setenv(env, "")
+	}
+
+	fakeexec := &testingexec.FakeExec{
+		CommandScript: []testingexec.FakeCommandAction{
+			func(_ string, _ ...string) exec.Cmd {
+				fakeCmd := testingexec.NewFakeCmd("gh", "auth", "token", "--hostname", "github.com")
+				fakeCmd.OutputScripts = []testingexec.FakeAction{
+					func() ([]byte, []byte, error) {
+						return nil, nil, errors.New("not logged in")
+					},
+				}
+				return fakeCmd
+			},
+			func(_ string, _ ...string) exec.Cmd {
+				fakeCmd := testingexec.NewFakeCmd(
+					"gh", "repo", "list", "--limit", "9999", "acme",
+					"--json", "owner,name,description,primaryLanguage,stargazerCount,pushedAt,url",
+				)
+				fakeCmd.OutputScripts = []testingexec.FakeAction{
+					func() ([]byte, []byte, error) {
+						return []byte(`[{"owner":{"login":"acme"},"name":"api"}]`), nil, nil
+					},
+				}
+				return fakeCmd
+			},
+		},
+	}
+
+	client := gh.NewClient().SetExecutor(fakeexec)
+
+	repos, err := client.ListRepos(context.Background(), &gh.RepoListOptions{Owner: "acme"})
+	assert.NoError(t, err)
+
+	if len(repos) != 1 || repos[0].String() != "acme/api" {
+		t.Fatalf("expected acme/api, got %v", repos)
+	}
+}
+
+func TestSearchRepos_FallbackToCLI(t *testing.T) {
+	for _, env := range []string{"GH_TOKEN", "GITHUB_TOKEN"} {
+		t.Setenv(env, "")
+	}
+
+	fakeexec := &testingexec.FakeExec{
+		CommandScript: []testingexec.FakeCommandAction{
+			func(_ string, _ ...string) exec.Cmd {
+				fakeCmd := testingexec.NewFakeCmd("gh", "auth", "token", "--hostname", "github.com")
+				fakeCmd.OutputScripts = []testingexec.FakeAction{
+					func() ([]byte, []byte, error) {
+						return nil, nil, errors.New("not logged in")
+					},
+				}
+				return fakeCmd
+			},
+			func(_ string, _ ...string) exec.Cmd {
+				fakeCmd := testingexec.NewFakeCmd(
+					"gh", "api", "--paginate",
+					"search/repositories?per_page=100&q=topic%3Acli",
+					"--jq", ".items[]",
+				)
+				fakeCmd.OutputScripts = []testingexec.FakeAction{
+					func() ([]byte, []byte, error) {
+						return []byte(`{"name":"cli","owner":{"login":"cli"}}` + "\n" +
+							`{"name":"z","owner":{"login":"zkhvan"}}` + "\n"), nil, nil
+					},
+				}
+				return fakeCmd
+			},
+		},
+	}
+
+	client := gh.NewClient().SetExecutor(fakeexec)
+
+	repos, err := client.SearchRepos(context.Background(), "topic:cli")
+	assert.NoError(t, err)
+
+	var got []string
+	for _, r := range repos {
+		got = append(got, r.String())
+	}
+
+	if diff := cmp.Diff([]string{"cli/cli", "zkhvan/z"}, got); diff != "" {
+		t.Fatalf("repos mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestListRepos_FallbackToCLIOnAPIError(t *testing.T) {
+	tests := map[string]struct {
+		status int
+		body   string
+	}{
+		"server error": {
+			status: http.StatusBadGateway,
+			body:   `{"message":"Server Error"}`,
+		},
+		"SSO enforced": {
+			status: http.StatusForbidden,
+			body:   `{"message":"Resource protected by organization SAML enforcement."}`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(test.status)
+				_, _ = w.Write([]byte(test.body))
+			}))
+			t.Cleanup(server.Close)
+
+			fakeexec := &testingexec.FakeExec{
+				CommandScript: []testingexec.FakeCommandAction{
+					func(_ string, _ ...string) exec.Cmd {
+						fakeCmd := testingexec.NewFakeCmd(
+							"gh", "repo", "list", "--limit", "9999", "acme",
+							"--json", "owner,name,description,primaryLanguage,stargazerCount,pushedAt,url",
+						)
+						fakeCmd.OutputScripts = []testingexec.FakeAction{
+							func() ([]byte, []byte, error) {
+								return []byte(`[{"owner":{"login":"acme"},"name":"api"}]`), nil, nil
+							},
+						}
+						return fakeCmd
+					},
+				},
+			}
+
+			client := gh.NewClient().SetBaseURL(server.URL).SetToken("secret").SetExecutor(fakeexec)
+
+			repos, err := client.ListRepos(context.Background(), &gh.RepoListOptions{Owner: "acme"})
+			assert.NoError(t, err)
+
+			if len(repos) != 1 || repos[0].String() != "acme/api" {
+				t.Fatalf("expected acme/api, got %v", repos)
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ package gh
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 )
@@ -28,9 +29,8 @@ func (c *Client) RepoView(ctx context.Context, opts *RepoViewOptions) (string, e
 		args = append(args, "--web")
 	}
 
-	cmd := c.executor.CommandContext(
+	cmd := c.command(
 		ctx,
-		"gh",
 		args...,
 	)
 
@@ -46,3 +46,39 @@ func (c *Client) RepoView(ctx context.Context, opts *RepoViewOptions) (string, e
 
 	return string(output), nil
 }
+
+// GetRepo returns the details of a repository.
+func (c *Client) GetRepo(ctx context.Context, id string) (*Repo, error) {
+	if id == "" {
+		return nil, errors.New("repository ID is required")
+	}
+
+	if c.Token(ctx) != "" {
+		var r apiRepo
+		_, err := c.get(ctx, c.apiURL("/repos/"+id), &r)
+		if err == nil {
+			return r.toRepo(), nil
+		}
+		if !fallBack(ctx, err) {
+			return nil, err
+		}
+	}
+
+	cmd := c.command(
+		ctx,
+		"repo", "view", id,
+		"--json", cliRepoFields,
+	)
+
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("error running command %q: %w", cmd.String(), err)
+	}
+
+	var r cliRepo
+	if err := json.Unmarshal(output, &r); err != nil {
+		return nil, fmt.Errorf("error unmarshalling: %w", err)
+	}
+
+	return r.toRepo(), nil
+}
@@ -0,0 +1,58 @@
+package gh
+
+import (
+	"bytes"
+	"context"
+	"os"
+)
+
+// Token returns the token used to authenticate with the API. It's looked up
+// in the following order:
+//
+//  1. the token set with SetToken
+//  2. $GH_TOKEN or $GITHUB_TOKEN, or $GH_ENTERPRISE_TOKEN or
+//     $GITHUB_ENTERPRISE_TOKEN for other hosts than github.com
+//  3. gh auth token --hostname <host>
+//
+// If no token is found, an empty string is returned and the gh CLI is used
+// instead of the API.
+func (c *Client) Token(ctx context.Context) string {
+	if c.token != "" {
+		return c.token
+	}
+
+	c.auth.once.Do(func() {
+		// The token is kept for the later calls, so it isn't looked up with
+		// the caller's deadline or cancellation.
+		c.auth.token = c.lookupToken(context.WithoutCancel(ctx))
+	})
+
+	return c.auth.token
+}
+
+func (c *Client) lookupToken(ctx context.Context) string {
+	envs := []string{"GH_TOKEN", "GITHUB_TOKEN"}
+	if c.Host() != DefaultHost {
+		envs = []string{"GH_ENTERPRISE_TOKEN", "GITHUB_ENTERPRISE_TOKEN"}
+	}
+
+	for _, env := range envs {
+		if token := os.Getenv(env); token != "" {
+			return token
+		}
+	}
+
+	cmd := c.executor.CommandContext(
+		ctx,
+		"gh", "auth", "token",
+		"--hostname", c.Host(),
+	)
+
+	output, err := cmd.Output()
+	if err != nil {
+		// gh isn't installed or not logged in.
+		return ""
+	}
+
+	return string(bytes.TrimSpace(output))
+}
@@ -0,0 +1,59 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+)
+
+// RemoteURL returns the URL of the given remote for the repository in dir.
+// If remote is empty, "origin" is used.
+func (c *Client) RemoteURL(ctx context.Context, dir, remote string) (string, error) {
+	if dir == "" {
+		return "", errors.New("dir is required")
+	}
+
+	if remote == "" {
+		remote = "origin"
+	}
+
+	cmd := c.executor.CommandContext(
+		ctx,
+		"git", "-C", dir,
+		"remote", "get-url", remote,
+	)
+
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("error running command %q: %w", cmd.String(), err)
+	}
+	output = bytes.TrimSpace(output)
+
+	return string(output), nil
+}
+
+// Clone clones the repository at url into path.
+func (c *Client) Clone(ctx context.Context, url, path string) (string, error) {
+	if url == "" {
+		return "", errors.New("url is required")
+	}
+
+	if path == "" {
+		return "", errors.New("path is required")
+	}
+
+	cmd := c.executor.CommandContext(
+		ctx,
+		"git", "clone",
+		url, path,
+	)
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("error running command %q: %w", cmd.String(), err)
+	}
+	output = bytes.TrimSpace(output)
+
+	return string(output), nil
+}
@@ -0,0 +1,90 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Status is the state of the working tree of a repository.
+type Status struct {
+	// Branch is the current branch, empty if the HEAD is detached.
+	Branch string
+
+	// Upstream is the tracked branch, e.g. "origin/main", if any.
+	Upstream string
+
+	// Ahead and Behind are the number of commits the branch is ahead and
+	// behind its upstream.
+	Ahead  int
+	Behind int
+
+	// Changes is the number of changed or untracked files.
+	Changes int
+}
+
+// Status returns the status of the repository in dir.
+func (c *Client) Status(ctx context.Context, dir string) (Status, error) {
+	if dir == "" {
+		return Status{}, errors.New("dir is required")
+	}
+
+	cmd := c.executor.CommandContext(
+		ctx,
+		"git", "-C", dir,
+		"status", "--porcelain=v2", "--branch",
+	)
+
+	output, err := cmd.Output()
+	if err != nil {
+		return Status{}, fmt.Errorf("error running command %q: %w", cmd.String(), err)
+	}
+
+	var s Status
+	for _, line := range strings.Split(string(bytes.TrimSpace(output)), "\n") {
+		switch {
+		case line == "":
+		case strings.HasPrefix(line, "# branch.head "):
+			if head := strings.TrimPrefix(line, "# branch.head "); head != "(detached)" {
+				s.Branch = head
+			}
+		case strings.HasPrefix(line, "# branch.upstream "):
+			s.Upstream = strings.TrimPrefix(line, "# branch.upstream ")
+		case strings.HasPrefix(line, "# branch.ab "):
+			_, _ = fmt.Sscanf(strings.TrimPrefix(line, "# branch.ab "), "+%d -%d", &s.Ahead, &s.Behind)
+		case strings.HasPrefix(line, "#"):
+		default:
+			s.Changes++
+		}
+	}
+
+	return s, nil
+}
+
+// Log returns the last n commits of the repository in dir, one line each with
+// the short hash, the subject and the relative date.
+func (c *Client) Log(ctx context.Context, dir string, n int) ([]string, error) {
+	if dir == "" {
+		return nil, errors.New("dir is required")
+	}
+
+	cmd := c.executor.CommandContext(
+		ctx,
+		"git", "-C", dir,
+		"log", fmt.Sprintf("-n%d", n), "--format=%h %s (%cr)",
+	)
+
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("error running command %q: %w", cmd.String(), err)
+	}
+
+	output = bytes.TrimSpace(output)
+	if len(output) == 0 {
+		return nil, nil
+	}
+
+	return strings.Split(string(output), "\n"), nil
+}
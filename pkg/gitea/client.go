@@ -0,0 +1,107 @@
+package gitea
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/zkhvan/z/pkg/httplib"
+)
+
+var ErrNotFound = errors.New("not found")
+
+// Client talks to the Gitea REST API (v1), which is also served by Forgejo.
+type Client struct {
+	host       string
+	baseURL    string
+	token      string
+	httpClient *http.Client
+}
+
+// NewClient returns a client for the Gitea instance at host, e.g.
+// "git.example.com".
+func NewClient(host string) *Client {
+	return &Client{
+		host:       host,
+		baseURL:    fmt.Sprintf("https://%s/api/v1", host),
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// SetBaseURL overrides the API base URL, which defaults to
+// https://<host>/api/v1.
+func (c *Client) SetBaseURL(baseURL string) *Client {
+	c.baseURL = strings.TrimSuffix(baseURL, "/")
+	return c
+}
+
+// SetToken sets the token used to authenticate. If no token is set,
+// $GITEA_TOKEN is used.
+func (c *Client) SetToken(token string) *Client {
+	c.token = token
+	return c
+}
+
+func (c *Client) SetHTTPClient(httpClient *http.Client) *Client {
+	c.httpClient = httpClient
+	return c
+}
+
+func (c *Client) Host() string {
+	return c.host
+}
+
+// WebURL returns the URL of the instance's web pages, which is the API base
+// URL without "/api/v1", e.g. https://host/gitea for an instance served under
+// a subpath.
+func (c *Client) WebURL() string {
+	return strings.TrimSuffix(c.baseURL, "/api/v1")
+}
+
+func (c *Client) authToken() string {
+	if c.token != "" {
+		return c.token
+	}
+	return os.Getenv("GITEA_TOKEN")
+}
+
+// get requests the API URL and decodes the JSON response into v. It returns
+// the URL of the next page, or an empty string if it's the last page.
+func (c *Client) get(ctx context.Context, u string, v any) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return "", err
+	}
+
+	req.Header.Set("Accept", "application/json")
+	if token := c.authToken(); token != "" {
+		req.Header.Set("Authorization", "token "+token)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("error requesting %q: %w", u, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return "", fmt.Errorf("error requesting %q: %w", u, ErrNotFound)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return "", fmt.Errorf("error requesting %q: %s: %s", u, resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return "", fmt.Errorf("error decoding response from %q: %w", u, err)
+	}
+
+	return httplib.NextLink(resp.Header.Get("Link"), resp.Request.URL), nil
+}
@@ -0,0 +1,85 @@
+package gitea
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+type Repo struct {
+	Name  string `json:"name"`
+	Owner struct {
+		Login string `json:"login"`
+	} `json:"owner"`
+	Description string    `json:"description"`
+	Language    string    `json:"language"`
+	Stars       int       `json:"stars_count"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	CloneURL    string    `json:"clone_url"`
+	SSHURL      string    `json:"ssh_url"`
+	HTMLURL     string    `json:"html_url"`
+}
+
+type RepoListOptions struct {
+	// Owner is an organization or a user.
+	Owner string
+}
+
+// ListRepos lists the repositories of an organization. If the owner isn't an
+// organization, the repositories of the user with that username are listed
+// instead.
+func (c *Client) ListRepos(ctx context.Context, opts *RepoListOptions) ([]*Repo, error) {
+	if opts == nil {
+		opts = &RepoListOptions{}
+	}
+
+	if opts.Owner == "" {
+		return nil, errors.New("owner is required")
+	}
+
+	repos, err := c.listRepos(ctx, "/orgs/"+url.PathEscape(opts.Owner)+"/repos")
+	if errors.Is(err, ErrNotFound) {
+		return c.listRepos(ctx, "/users/"+url.PathEscape(opts.Owner)+"/repos")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return repos, nil
+}
+
+func (c *Client) listRepos(ctx context.Context, path string) ([]*Repo, error) {
+	var repos []*Repo
+
+	next := c.baseURL + path + "?limit=50&page=1"
+	for next != "" {
+		var batch []*Repo
+
+		var err error
+		next, err = c.get(ctx, next, &batch)
+		if err != nil {
+			return nil, err
+		}
+
+		repos = append(repos, batch...)
+	}
+
+	return repos, nil
+}
+
+// GetRepo returns a repository by its owner and name.
+func (c *Client) GetRepo(ctx context.Context, owner, name string) (*Repo, error) {
+	if owner == "" || name == "" {
+		return nil, errors.New("owner and name are required")
+	}
+
+	var repo Repo
+	u := c.baseURL + "/repos/" + url.PathEscape(owner) + "/" + url.PathEscape(name)
+	if _, err := c.get(ctx, u, &repo); err != nil {
+		return nil, fmt.Errorf("error getting repository %s/%s: %w", owner, name, err)
+	}
+
+	return &repo, nil
+}
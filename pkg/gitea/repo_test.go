@@ -0,0 +1,80 @@
+package gitea_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/zkhvan/z/pkg/assert"
+	"github.com/zkhvan/z/pkg/gitea"
+)
+
+func TestListRepos(t *testing.T) {
+	tests := map[string]struct {
+		owner string
+		want  []string
+	}{
+		"organization repos should follow the next links": {
+			owner: "acme",
+			want:  []string{"acme/api", "acme/web"},
+		},
+		"user repos should be listed when the organization doesn't exist": {
+			owner: "someone",
+			want:  []string{"someone/dotfiles"},
+		},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/orgs/{org}/repos", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "token secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		if r.PathValue("org") != "acme" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		switch r.URL.Query().Get("page") {
+		case "1":
+			w.Header().Set("Link", `</api/v1/orgs/acme/repos?limit=50&page=2>; rel="next", `+
+				`</api/v1/orgs/acme/repos?limit=50&page=2>; rel="last"`)
+			fmt.Fprint(w, `[{"name":"api","owner":{"login":"acme"}}]`)
+		case "2":
+			fmt.Fprint(w, `[{"name":"web","owner":{"login":"acme"}}]`)
+		default:
+			t.Errorf("unexpected page %q", r.URL.Query().Get("page"))
+		}
+	})
+	mux.HandleFunc("GET /api/v1/users/{user}/repos", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `[{"name":"dotfiles","owner":{"login":%q}}]`, r.PathValue("user"))
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			client := gitea.NewClient("git.example.com").
+				SetBaseURL(server.URL + "/api/v1").
+				SetToken("secret")
+
+			repos, err := client.ListRepos(context.Background(), &gitea.RepoListOptions{Owner: test.owner})
+			assert.NoError(t, err)
+
+			var got []string
+			for _, r := range repos {
+				got = append(got, r.Owner.Login+"/"+r.Name)
+			}
+
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Fatalf("repos mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zkhvan/z/pkg/exec"
+)
+
+var defaultExecutor exec.Interface = exec.New()
+
+var ErrNotFound = errors.New("not found")
+
+// Client talks to the GitLab REST API (v4).
+type Client struct {
+	host       string
+	baseURL    string
+	token      string
+	httpClient *http.Client
+	executor   exec.Interface
+
+	// auth holds the token looked up by Token.
+	auth auth
+}
+
+type auth struct {
+	once  sync.Once
+	token string
+}
+
+// NewClient returns a client for the GitLab instance at host, e.g.
+// "gitlab.com".
+func NewClient(host string) *Client {
+	return &Client{
+		host:       host,
+		baseURL:    fmt.Sprintf("https://%s/api/v4", host),
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		executor:   defaultExecutor,
+	}
+}
+
+// SetBaseURL overrides the API base URL, which defaults to
+// https://<host>/api/v4.
+func (c *Client) SetBaseURL(baseURL string) *Client {
+	c.baseURL = strings.TrimSuffix(baseURL, "/")
+	return c
+}
+
+// SetToken sets the token used to authenticate. If no token is set, it's
+// looked up with Token.
+func (c *Client) SetToken(token string) *Client {
+	c.token = token
+	return c
+}
+
+func (c *Client) SetHTTPClient(httpClient *http.Client) *Client {
+	c.httpClient = httpClient
+	return c
+}
+
+func (c *Client) SetExecutor(executor exec.Interface) *Client {
+	c.executor = executor
+	return c
+}
+
+func (c *Client) Host() string {
+	return c.host
+}
+
+// get requests the API path and decodes the JSON response into v. It returns
+// the next page number, or 0 if it's the last page.
+func (c *Client) get(ctx context.Context, path string, query url.Values, v any) (int, error) {
+	u := c.baseURL + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	token, err := c.Token(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if token != "" {
+		req.Header.Set("PRIVATE-TOKEN", token)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("error requesting %q: %w", u, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return 0, fmt.Errorf("error requesting %q: %w", u, ErrNotFound)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return 0, fmt.Errorf("error requesting %q: %s: %s", u, resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return 0, fmt.Errorf("error decoding response from %q: %w", u, err)
+	}
+
+	var next int
+	if h := resp.Header.Get("X-Next-Page"); h != "" {
+		if _, err := fmt.Sscanf(h, "%d", &next); err != nil {
+			return 0, fmt.Errorf("error parsing X-Next-Page %q: %w", h, err)
+		}
+	}
+
+	return next, nil
+}
@@ -0,0 +1,97 @@
+package gitlab
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+type Project struct {
+	Name           string    `json:"path"`
+	PathWithNS     string    `json:"path_with_namespace"`
+	Description    string    `json:"description"`
+	HTTPURLToRepo  string    `json:"http_url_to_repo"`
+	SSHURLToRepo   string    `json:"ssh_url_to_repo"`
+	WebURL         string    `json:"web_url"`
+	StarCount      int       `json:"star_count"`
+	LastActivityAt time.Time `json:"last_activity_at"`
+	Namespace      struct {
+		FullPath string `json:"full_path"`
+	} `json:"namespace"`
+}
+
+// Owner returns the full path of the project's namespace, e.g.
+// "group/subgroup".
+func (p *Project) Owner() string {
+	return p.Namespace.FullPath
+}
+
+type ProjectListOptions struct {
+	// Owner is a group (including nested groups, e.g. "group/subgroup") or
+	// a user.
+	Owner string
+}
+
+// ListProjects lists the projects of a group, including the projects of its
+// subgroups. If the owner isn't a group, the projects of the user with that
+// username are listed instead.
+func (c *Client) ListProjects(ctx context.Context, opts *ProjectListOptions) ([]*Project, error) {
+	if opts == nil {
+		opts = &ProjectListOptions{}
+	}
+
+	if opts.Owner == "" {
+		return nil, errors.New("owner is required")
+	}
+
+	query := url.Values{}
+	query.Set("include_subgroups", "true")
+	query.Set("archived", "false")
+
+	projects, err := c.listProjects(ctx, "/groups/"+url.PathEscape(opts.Owner)+"/projects", query)
+	if errors.Is(err, ErrNotFound) {
+		return c.listProjects(ctx, "/users/"+url.PathEscape(opts.Owner)+"/projects", url.Values{})
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return projects, nil
+}
+
+func (c *Client) listProjects(ctx context.Context, path string, query url.Values) ([]*Project, error) {
+	query.Set("per_page", "100")
+
+	var projects []*Project
+	for page := 1; page > 0; {
+		query.Set("page", strconv.Itoa(page))
+
+		var batch []*Project
+		next, err := c.get(ctx, path, query, &batch)
+		if err != nil {
+			return nil, err
+		}
+
+		projects = append(projects, batch...)
+		page = next
+	}
+
+	return projects, nil
+}
+
+// GetProject returns a project by its full path, e.g. "group/subgroup/repo".
+func (c *Client) GetProject(ctx context.Context, fullPath string) (*Project, error) {
+	if fullPath == "" {
+		return nil, errors.New("project path is required")
+	}
+
+	var project Project
+	if _, err := c.get(ctx, "/projects/"+url.PathEscape(fullPath), nil, &project); err != nil {
+		return nil, fmt.Errorf("error getting project %q: %w", fullPath, err)
+	}
+
+	return &project, nil
+}
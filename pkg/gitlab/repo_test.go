@@ -0,0 +1,85 @@
+package gitlab_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/zkhvan/z/pkg/assert"
+	"github.com/zkhvan/z/pkg/gitlab"
+)
+
+func TestListProjects(t *testing.T) {
+	tests := map[string]struct {
+		owner string
+		want  []string
+	}{
+		"group projects should be paginated": {
+			owner: "group/subgroup",
+			want:  []string{"group/subgroup/one", "group/subgroup/nested/two"},
+		},
+		"user projects should be listed when the group doesn't exist": {
+			owner: "someone",
+			want:  []string{"someone/dotfiles"},
+		},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v4/groups/{group}/projects", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("PRIVATE-TOKEN") != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		if r.PathValue("group") != "group/subgroup" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		if r.URL.Query().Get("include_subgroups") != "true" {
+			t.Errorf("expected subgroups to be included")
+		}
+
+		switch r.URL.Query().Get("page") {
+		case "1":
+			w.Header().Set("X-Next-Page", "2")
+			fmt.Fprint(w, `[{"path":"one","namespace":{"full_path":"group/subgroup"}}]`)
+		case "2":
+			fmt.Fprint(w, `[{"path":"two","namespace":{"full_path":"group/subgroup/nested"}}]`)
+		default:
+			t.Errorf("unexpected page %q", r.URL.Query().Get("page"))
+		}
+	})
+	mux.HandleFunc("GET /api/v4/users/{user}/projects", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `[{"path":"dotfiles","namespace":{"full_path":%q}}]`, r.PathValue("user"))
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			client := gitlab.NewClient("gitlab.example.com").
+				SetBaseURL(server.URL + "/api/v4").
+				SetToken("secret")
+
+			projects, err := client.ListProjects(context.Background(), &gitlab.ProjectListOptions{
+				Owner: test.owner,
+			})
+			assert.NoError(t, err)
+
+			var got []string
+			for _, p := range projects {
+				got = append(got, p.Owner()+"/"+p.Name)
+			}
+
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Fatalf("projects mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package gitlab
+
+import (
+	"bytes"
+	"context"
+	"os"
+)
+
+// Token returns the token used to authenticate. It's looked up in the
+// following order:
+//
+//  1. the token set with SetToken
+//  2. $GITLAB_TOKEN
+//  3. glab config get token --host <host>
+//
+// If no token is found, requests are made anonymously. The lookup is done
+// once, even if no token is found, and is safe for concurrent use.
+func (c *Client) Token(ctx context.Context) (string, error) {
+	if c.token != "" {
+		return c.token, nil
+	}
+
+	c.auth.once.Do(func() {
+		// The token is kept for the later calls, so it isn't looked up with
+		// the caller's deadline or cancellation.
+		c.auth.token = c.lookupToken(context.WithoutCancel(ctx))
+	})
+
+	return c.auth.token, nil
+}
+
+func (c *Client) lookupToken(ctx context.Context) string {
+	if token := os.Getenv("GITLAB_TOKEN"); token != "" {
+		return token
+	}
+
+	cmd := c.executor.CommandContext(
+		ctx,
+		"glab", "config", "get", "token",
+		"--host", c.host,
+	)
+
+	output, err := cmd.Output()
+	if err != nil {
+		// glab isn't installed or not logged in, fall back to anonymous
+		// requests which can still list public projects.
+		return ""
+	}
+
+	return string(bytes.TrimSpace(output))
+}
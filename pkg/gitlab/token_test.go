@@ -0,0 +1,52 @@
+package gitlab_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/zkhvan/z/pkg/assert"
+	"github.com/zkhvan/z/pkg/exec"
+	testingexec "github.com/zkhvan/z/pkg/exec/testing"
+	"github.com/zkhvan/z/pkg/gitlab"
+)
+
+func TestToken_LookedUpOnce(t *testing.T) {
+	t.Setenv("GITLAB_TOKEN", "")
+
+	fakeexec := &testingexec.FakeExec{
+		CommandScript: []testingexec.FakeCommandAction{
+			func(_ string, _ ...string) exec.Cmd {
+				fakeCmd := testingexec.NewFakeCmd("glab", "config", "get", "token", "--host", "gitlab.com")
+				fakeCmd.OutputScripts = []testingexec.FakeAction{
+					func() ([]byte, []byte, error) {
+						return nil, nil, errors.New("not logged in")
+					},
+				}
+				return fakeCmd
+			},
+		},
+	}
+
+	client := gitlab.NewClient("gitlab.com").SetExecutor(fakeexec)
+
+	// The projects of the patterns are loaded concurrently, and "no token" is
+	// cached too.
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			token, err := client.Token(context.Background())
+			assert.NoError(t, err)
+			assert.EqualString(t, token, "")
+		}()
+	}
+	wg.Wait()
+
+	if fakeexec.CommandCalls != 1 {
+		t.Fatalf("expected glab to be run once, got %d", fakeexec.CommandCalls)
+	}
+}
@@ -0,0 +1,37 @@
+package httplib
+
+import (
+	"net/url"
+	"strings"
+)
+
+// NextLink returns the absolute URL of the rel="next" link of a Link header,
+// resolved against the URL of the request, or an empty string if it's the
+// last page.
+func NextLink(header string, base *url.URL) string {
+	for _, link := range strings.Split(header, ",") {
+		parts := strings.Split(link, ";")
+		if len(parts) < 2 {
+			continue
+		}
+
+		isNext := false
+		for _, param := range parts[1:] {
+			if strings.TrimSpace(param) == `rel="next"` {
+				isNext = true
+			}
+		}
+		if !isNext {
+			continue
+		}
+
+		target := strings.Trim(strings.TrimSpace(parts[0]), "<>")
+		u, err := base.Parse(target)
+		if err != nil {
+			return ""
+		}
+		return u.String()
+	}
+
+	return ""
+}
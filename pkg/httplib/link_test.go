@@ -0,0 +1,38 @@
+package httplib_test
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/zkhvan/z/pkg/assert"
+	"github.com/zkhvan/z/pkg/httplib"
+)
+
+func TestNextLink(t *testing.T) {
+	tests := map[string]struct {
+		header string
+		want   string
+	}{
+		"absolute next link should be returned": {
+			header: `<https://api.example.com/repos?page=2>; rel="next", <https://api.example.com/repos?page=5>; rel="last"`,
+			want:   "https://api.example.com/repos?page=2",
+		},
+		"relative next link should be resolved": {
+			header: `</api/v1/repos?page=3>; rel="next"`,
+			want:   "https://git.example.com/api/v1/repos?page=3",
+		},
+		"last page should have no next link": {
+			header: `<https://api.example.com/repos?page=1>; rel="first", <https://api.example.com/repos?page=4>; rel="prev"`,
+		},
+		"missing header should have no next link": {},
+	}
+
+	base, err := url.Parse("https://git.example.com/api/v1/repos?page=2")
+	assert.NoError(t, err)
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.EqualString(t, httplib.NextLink(test.header, base), test.want)
+		})
+	}
+}
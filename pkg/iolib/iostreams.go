@@ -2,6 +2,7 @@ package iolib
 
 import (
 	"io"
+	"os"
 )
 
 type IOStreams struct {
@@ -9,3 +10,23 @@ type IOStreams struct {
 	Out    io.Writer // think os.Stdout
 	ErrOut io.Writer // think os.Stderr
 }
+
+// CanPrompt reports whether the user can be prompted for input. Prompts are
+// written to ErrOut, since Out is often captured by the shell integration.
+func (s *IOStreams) CanPrompt() bool {
+	return isTerminal(s.In) && isTerminal(s.ErrOut)
+}
+
+func isTerminal(v any) bool {
+	f, ok := v.(*os.File)
+	if !ok {
+		return false
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+
+	return info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,97 @@
+package jsonrpc
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Client calls the methods of a server over a connection, one call at a time.
+type Client struct {
+	mu      sync.Mutex
+	conn    net.Conn
+	scanner *bufio.Scanner
+	nextID  int
+}
+
+// Dial connects to the server at the address, e.g. the path of a Unix socket.
+func Dial(ctx context.Context, network, address string) (*Client, error) {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, network, address)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewClient(conn), nil
+}
+
+func NewClient(conn net.Conn) *Client {
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+
+	return &Client{conn: conn, scanner: scanner}
+}
+
+// Call calls the method with the params and decodes its result into result,
+// unless nil. The context's deadline applies to the call.
+func (c *Client) Call(ctx context.Context, method string, params, result any) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.nextID++
+	req := struct {
+		JSONRPC string `json:"jsonrpc"`
+		ID      int    `json:"id"`
+		Method  string `json:"method"`
+		Params  any    `json:"params,omitempty"`
+	}{JSONRPC: version, ID: c.nextID, Method: method, Params: params}
+
+	deadline, _ := ctx.Deadline()
+	if err := c.conn.SetDeadline(deadline); err != nil {
+		return err
+	}
+	stop := context.AfterFunc(ctx, func() {
+		_ = c.conn.SetDeadline(time.Now())
+	})
+	defer stop()
+
+	if err := json.NewEncoder(c.conn).Encode(req); err != nil {
+		return fmt.Errorf("error sending %q: %w", method, err)
+	}
+
+	if !c.scanner.Scan() {
+		err := c.scanner.Err()
+		if err == nil {
+			err = fmt.Errorf("connection closed")
+		}
+		return fmt.Errorf("error receiving %q: %w", method, err)
+	}
+
+	var resp Response
+	if err := json.Unmarshal(c.scanner.Bytes(), &resp); err != nil {
+		return fmt.Errorf("error decoding the response of %q: %w", method, err)
+	}
+
+	if string(resp.ID) != strconv.Itoa(c.nextID) {
+		return fmt.Errorf("unexpected response id %s to %q", resp.ID, method)
+	}
+
+	if resp.Error != nil {
+		return resp.Error
+	}
+
+	if result == nil {
+		return nil
+	}
+
+	return json.Unmarshal(resp.Result, result)
+}
+
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
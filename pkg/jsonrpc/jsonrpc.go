@@ -0,0 +1,60 @@
+// Package jsonrpc implements JSON-RPC 2.0 over stream connections, e.g. Unix
+// sockets, with one JSON message per line.
+package jsonrpc
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+const version = "2.0"
+
+// The error codes defined by the specification.
+const (
+	CodeParseError     = -32700
+	CodeInvalidRequest = -32600
+	CodeMethodNotFound = -32601
+	CodeInvalidParams  = -32602
+	CodeInternalError  = -32603
+)
+
+// Request is a call of a method. A request without ID is a notification,
+// which isn't answered.
+type Request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// Response is the answer to a request, with either a result or an error.
+type Response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+}
+
+// Error is the error of a response.
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s (code %d)", e.Message, e.Code)
+}
+
+// DecodeParams decodes the params of a request into v. It returns an invalid
+// params error, so handlers can return it as is.
+func DecodeParams(params json.RawMessage, v any) error {
+	if len(params) == 0 {
+		return nil
+	}
+
+	if err := json.Unmarshal(params, v); err != nil {
+		return &Error{Code: CodeInvalidParams, Message: err.Error()}
+	}
+
+	return nil
+}
@@ -0,0 +1,186 @@
+package jsonrpc_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zkhvan/z/pkg/assert"
+	"github.com/zkhvan/z/pkg/jsonrpc"
+)
+
+// newServer returns a server with the methods used by the tests.
+func newServer() *jsonrpc.Server {
+	s := jsonrpc.NewServer()
+
+	s.Handle("sum", func(_ context.Context, params json.RawMessage) (any, error) {
+		var numbers []int
+		if err := jsonrpc.DecodeParams(params, &numbers); err != nil {
+			return nil, err
+		}
+
+		sum := 0
+		for _, n := range numbers {
+			sum += n
+		}
+		return sum, nil
+	})
+	s.Handle("fail", func(context.Context, json.RawMessage) (any, error) {
+		return nil, errors.New("something failed")
+	})
+	s.Handle("reject", func(context.Context, json.RawMessage) (any, error) {
+		return nil, &jsonrpc.Error{Code: 42, Message: "rejected"}
+	})
+	s.Handle("block", func(ctx context.Context, _ json.RawMessage) (any, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+
+	return s
+}
+
+// serve serves a connection of the server, and returns the other end.
+func serve(t *testing.T, s *jsonrpc.Server) net.Conn {
+	t.Helper()
+
+	server, client := net.Pipe()
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		s.ServeConn(ctx, server)
+	}()
+
+	t.Cleanup(func() {
+		cancel()
+		client.Close()
+		<-done
+	})
+
+	return client
+}
+
+func TestServer_Framing(t *testing.T) {
+	conn := serve(t, newServer())
+
+	// The requests are answered in order, one JSON message per line. The
+	// notification isn't answered.
+	go func() {
+		_, _ = conn.Write([]byte(strings.Join([]string{
+			`{"jsonrpc":"2.0","id":1,"method":"sum","params":[1,2]}`,
+			`{"jsonrpc":"2.0","method":"sum","params":[3]}`,
+			`not json`,
+			`{"jsonrpc":"1.0","id":"b","method":"sum"}`,
+			`{"jsonrpc":"2.0","id":"c","method":"sum","params":{"not":"a list"}}`,
+		}, "\n") + "\n"))
+	}()
+
+	expected := []string{
+		`{"jsonrpc":"2.0","id":1,"result":3}`,
+		`{"jsonrpc":"2.0","id":null,"error":{"code":-32700,` +
+			`"message":"invalid character 'o' in literal null (expecting 'u')"}}`,
+		`{"jsonrpc":"2.0","id":"b","error":{"code":-32600,"message":"invalid request"}}`,
+		`{"jsonrpc":"2.0","id":"c","error":{"code":-32602,` +
+			`"message":"json: cannot unmarshal object into Go value of type []int"}}`,
+	}
+
+	scanner := bufio.NewScanner(conn)
+	for _, want := range expected {
+		if !scanner.Scan() {
+			t.Fatalf("expected %s, got %v", want, scanner.Err())
+		}
+		assert.EqualString(t, scanner.Text(), want)
+	}
+}
+
+func TestClient_Call(t *testing.T) {
+	client := jsonrpc.NewClient(serve(t, newServer()))
+
+	var sum int
+	assert.NoError(t, client.Call(context.Background(), "sum", []int{1, 2, 3}, &sum))
+	if sum != 6 {
+		t.Fatalf("expected 6, got %d", sum)
+	}
+
+	// Without params nor result.
+	assert.NoError(t, client.Call(context.Background(), "sum", nil, nil))
+}
+
+func TestClient_CallErrors(t *testing.T) {
+	tests := map[string]struct {
+		method   string
+		expected *jsonrpc.Error
+	}{
+		"unknown method": {
+			method:   "unknown",
+			expected: &jsonrpc.Error{Code: jsonrpc.CodeMethodNotFound, Message: `method "unknown" not found`},
+		},
+		"error": {
+			method:   "fail",
+			expected: &jsonrpc.Error{Code: jsonrpc.CodeInternalError, Message: "something failed"},
+		},
+		"rpc error": {
+			method:   "reject",
+			expected: &jsonrpc.Error{Code: 42, Message: "rejected"},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			client := jsonrpc.NewClient(serve(t, newServer()))
+
+			err := client.Call(context.Background(), tc.method, nil, nil)
+
+			var rpcErr *jsonrpc.Error
+			if !errors.As(err, &rpcErr) {
+				t.Fatalf("expected a JSON-RPC error, got %v", err)
+			}
+			if *rpcErr != *tc.expected {
+				t.Fatalf("expected %v, got %v", tc.expected, rpcErr)
+			}
+
+			// The connection is still usable.
+			assert.NoError(t, client.Call(context.Background(), "sum", []int{1}, nil))
+		})
+	}
+}
+
+func TestClient_ClosedConnection(t *testing.T) {
+	server, conn := net.Pipe()
+	client := jsonrpc.NewClient(conn)
+
+	// The server closes the connection without answering.
+	go func() {
+		_, _ = bufio.NewReader(server).ReadBytes('\n')
+		server.Close()
+	}()
+
+	err := client.Call(context.Background(), "sum", nil, nil)
+	assert.Error(t, err, errors.New(`error receiving "sum": connection closed`))
+
+	// The client's own connection is closed.
+	assert.NoError(t, client.Close())
+	err = client.Call(context.Background(), "sum", nil, nil)
+	if err == nil {
+		t.Fatal("expected an error on a closed connection")
+	}
+}
+
+func TestClient_CallCanceled(t *testing.T) {
+	client := jsonrpc.NewClient(serve(t, newServer()))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	err := client.Call(ctx, "block", nil, nil)
+	var netErr net.Error
+	if !errors.As(err, &netErr) || !netErr.Timeout() {
+		t.Fatalf("expected a timeout, got %v", err)
+	}
+}
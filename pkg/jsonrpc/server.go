@@ -0,0 +1,134 @@
+package jsonrpc
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+)
+
+// HandlerFunc handles the calls of a method. The returned result is encoded
+// as JSON. A returned *Error is sent as is, other errors as internal errors.
+type HandlerFunc func(ctx context.Context, params json.RawMessage) (any, error)
+
+// Server answers the requests of its connections with the handlers of their
+// methods.
+type Server struct {
+	mu      sync.RWMutex
+	methods map[string]HandlerFunc
+}
+
+func NewServer() *Server {
+	return &Server{methods: make(map[string]HandlerFunc)}
+}
+
+// Handle registers the handler of the method.
+func (s *Server) Handle(method string, handler HandlerFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.methods[method] = handler
+}
+
+// Serve accepts connections on the listener and serves them until the context
+// is canceled, then closes the listener.
+func (s *Server) Serve(ctx context.Context, l net.Listener) error {
+	go func() {
+		<-ctx.Done()
+		l.Close()
+	}()
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("error accepting connection: %w", err)
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.ServeConn(ctx, conn)
+		}()
+	}
+}
+
+// ServeConn answers the requests of the connection, in order, until it's
+// closed or the context is canceled.
+func (s *Server) ServeConn(ctx context.Context, conn net.Conn) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	go func() {
+		<-ctx.Done()
+		conn.Close()
+	}()
+
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	enc := json.NewEncoder(conn)
+
+	for scanner.Scan() {
+		resp, ok := s.handle(ctx, scanner.Bytes())
+		if !ok {
+			continue
+		}
+
+		if err := enc.Encode(resp); err != nil {
+			return
+		}
+	}
+}
+
+// handle answers a request. It returns false for notifications.
+func (s *Server) handle(ctx context.Context, line []byte) (Response, bool) {
+	var req Request
+	if err := json.Unmarshal(line, &req); err != nil {
+		return errorResponse(nil, &Error{Code: CodeParseError, Message: err.Error()}), true
+	}
+
+	if req.JSONRPC != version || req.Method == "" {
+		return errorResponse(req.ID, &Error{Code: CodeInvalidRequest, Message: "invalid request"}), req.ID != nil
+	}
+
+	s.mu.RLock()
+	handler, ok := s.methods[req.Method]
+	s.mu.RUnlock()
+
+	if !ok {
+		err := &Error{Code: CodeMethodNotFound, Message: fmt.Sprintf("method %q not found", req.Method)}
+		return errorResponse(req.ID, err), req.ID != nil
+	}
+
+	result, err := handler(ctx, req.Params)
+	if err != nil {
+		var rpcErr *Error
+		if !errors.As(err, &rpcErr) {
+			rpcErr = &Error{Code: CodeInternalError, Message: err.Error()}
+		}
+		return errorResponse(req.ID, rpcErr), req.ID != nil
+	}
+
+	b, err := json.Marshal(result)
+	if err != nil {
+		return errorResponse(req.ID, &Error{Code: CodeInternalError, Message: err.Error()}), req.ID != nil
+	}
+
+	return Response{JSONRPC: version, ID: req.ID, Result: b}, req.ID != nil
+}
+
+func errorResponse(id json.RawMessage, err *Error) Response {
+	if id == nil {
+		id = json.RawMessage("null")
+	}
+
+	return Response{JSONRPC: version, ID: id, Error: err}
+}
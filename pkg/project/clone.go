@@ -2,27 +2,283 @@ package project
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
+	"time"
 )
 
+// ConflictPolicy decides what CloneProject does when the destination
+// directory already exists, but isn't a clone of the project.
+type ConflictPolicy string
+
+const (
+	// ConflictPolicyAsk asks the user through CloneOptions.Prompt.
+	ConflictPolicyAsk ConflictPolicy = "ask"
+	// ConflictPolicyFail returns a *ConflictError.
+	ConflictPolicyFail ConflictPolicy = "fail"
+	// ConflictPolicyRename renames the existing directory, then clones the
+	// project into the original path.
+	ConflictPolicyRename ConflictPolicy = "rename"
+	// ConflictPolicyMoveAside moves the existing directory to a timestamped
+	// backup, then clones the project into the original path.
+	ConflictPolicyMoveAside ConflictPolicy = "move-aside"
+	// ConflictPolicyElsewhere leaves the existing directory alone and clones
+	// the project into a different path.
+	ConflictPolicyElsewhere ConflictPolicy = "elsewhere"
+)
+
+// ConflictPolicies lists the valid conflict policies.
+var ConflictPolicies = []ConflictPolicy{
+	ConflictPolicyAsk,
+	ConflictPolicyFail,
+	ConflictPolicyRename,
+	ConflictPolicyMoveAside,
+	ConflictPolicyElsewhere,
+}
+
+func ParseConflictPolicy(s string) (ConflictPolicy, error) {
+	for _, p := range ConflictPolicies {
+		if string(p) == s {
+			return p, nil
+		}
+	}
+
+	valid := make([]string, 0, len(ConflictPolicies))
+	for _, p := range ConflictPolicies {
+		valid = append(valid, string(p))
+	}
+
+	return "", fmt.Errorf("invalid conflict policy %q, must be one of: %s", s, strings.Join(valid, ", "))
+}
+
+// Conflict describes an existing directory that's in the way of a clone.
+type Conflict struct {
+	Project Project
+
+	// RemoteURL is the URL of the "origin" remote of the existing directory.
+	// It's empty if the directory isn't a Git repository.
+	RemoteURL string
+
+	// Reason describes why the existing directory can't be reused.
+	Reason string
+}
+
+// ConflictResolution is the answer to a Conflict.
+type ConflictResolution struct {
+	Policy ConflictPolicy
+
+	// Path is the new path for the existing directory (rename) or for the
+	// clone (elsewhere). If empty, a path is chosen automatically.
+	Path string
+}
+
+type ConflictError struct {
+	Conflict Conflict
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("project already exists: %s (%s)", e.Conflict.Project.AbsolutePath, e.Conflict.Reason)
+}
+
+type CloneOptions struct {
+	// OnConflict is the policy applied when the destination already exists.
+	// Defaults to ConflictPolicyAsk.
+	OnConflict ConflictPolicy
+
+	// Prompt is called to resolve a conflict when OnConflict is
+	// ConflictPolicyAsk. If nil, the conflict is returned as an error.
+	Prompt func(Conflict) (ConflictResolution, error)
+}
+
 // CloneProject clones a project.
-func (s *Service) CloneProject(ctx context.Context, project Project) (string, error) {
-	url := project.URL()
-	if url == "" {
-		return "", fmt.Errorf("error getting project URL")
+//
+// If the destination already contains a clone of the same remote, nothing is
+// cloned and the project is returned as synced. Otherwise, the conflict is
+// resolved according to opts.OnConflict.
+//
+// The returned project reflects where the project was actually cloned.
+func (s *Service) CloneProject(ctx context.Context, project Project, opts *CloneOptions) (Project, string, error) {
+	if opts == nil {
+		opts = &CloneOptions{}
+	}
+
+	if project.RemoteID == "" {
+		return project, "", fmt.Errorf("error getting project URL")
 	}
 
 	// Check if absolute path exists
 	if _, err := os.Stat(project.AbsolutePath); err == nil {
-		// TODO: confirm with the user what to do in this scenario.
-		return "", fmt.Errorf("project already exists: %s", project.AbsolutePath)
+		conflict, ok := s.detectConflict(ctx, project)
+		if !ok {
+			project.Source = SourceTypeSynced
+			return project, fmt.Sprintf("Project already cloned: %s", project.AbsolutePath), nil
+		}
+
+		project, err = s.resolveConflict(conflict, opts)
+		if err != nil {
+			return project, "", err
+		}
+	}
+
+	output, err := s.clone(ctx, project)
+	if err != nil {
+		return project, "", fmt.Errorf("error cloning project: %w", err)
+	}
+	project.Source = SourceTypeSynced
+
+	return project, output, nil
+}
+
+// detectConflict checks whether the existing directory of the project is a
+// clone of the same remote. It returns false if there's no conflict.
+func (s *Service) detectConflict(ctx context.Context, project Project) (Conflict, bool) {
+	conflict := Conflict{Project: project}
+
+	if _, err := os.Stat(filepath.Join(project.AbsolutePath, ".git")); err != nil {
+		conflict.Reason = "not a git repository"
+		return conflict, true
+	}
+
+	remoteURL, err := s.git.RemoteURL(ctx, project.AbsolutePath, "")
+	if err != nil {
+		conflict.Reason = "no origin remote"
+		return conflict, true
+	}
+	conflict.RemoteURL = remoteURL
+
+	for _, expected := range s.remoteURLs(project) {
+		if sameRemote(remoteURL, expected) {
+			return conflict, false
+		}
+	}
+
+	conflict.Reason = fmt.Sprintf("different remote: %s", remoteURL)
+	return conflict, true
+}
+
+// remoteURLs returns the URLs a clone of the project could have as its
+// remote.
+func (s *Service) remoteURLs(project Project) []string {
+	urls := []string{project.URL()}
+
+	if provider, err := s.provider(project.Host); err == nil {
+		urls = append(urls, provider.CloneURL(project.Repo()))
+	}
+
+	return urls
+}
+
+func (s *Service) resolveConflict(conflict Conflict, opts *CloneOptions) (Project, error) {
+	project := conflict.Project
+
+	resolution := ConflictResolution{Policy: opts.OnConflict}
+	if resolution.Policy == "" {
+		resolution.Policy = ConflictPolicyAsk
+	}
+
+	if resolution.Policy == ConflictPolicyAsk {
+		if opts.Prompt == nil {
+			return project, &ConflictError{Conflict: conflict}
+		}
+
+		var err error
+		resolution, err = opts.Prompt(conflict)
+		if err != nil {
+			return project, err
+		}
+	}
+
+	switch resolution.Policy {
+	case ConflictPolicyRename, ConflictPolicyMoveAside, ConflictPolicyElsewhere:
+		return s.relocate(project, resolution)
+	case ConflictPolicyFail, ConflictPolicyAsk:
+		return project, &ConflictError{Conflict: conflict}
+	default:
+		return project, fmt.Errorf("unsupported conflict policy: %q", resolution.Policy)
+	}
+}
+
+// relocate applies a resolution which moves the existing directory aside, or
+// clones the project elsewhere. The local ID of the returned project follows
+// where it's cloned.
+func (s *Service) relocate(project Project, resolution ConflictResolution) (Project, error) {
+	target := resolution.Path
+	if target == "" {
+		target = SuggestConflictPath(project, resolution.Policy)
+	}
+
+	target, err := filepath.Abs(target)
+	if err != nil {
+		return project, fmt.Errorf("error resolving %q: %w", resolution.Path, err)
 	}
 
-	output, err := s.gh.Clone(ctx, url, project.AbsolutePath)
+	if resolution.Policy == ConflictPolicyElsewhere {
+		if _, err := os.Lstat(target); err == nil {
+			return project, fmt.Errorf("path already exists: %s", target)
+		}
+		project.AbsolutePath = target
+	} else if err := moveAside(project.AbsolutePath, target); err != nil {
+		return project, err
+	}
+
+	project.LocalID = s.localIDOf(project.AbsolutePath)
+	return project, nil
+}
+
+// localIDOf returns the local ID of a project directory, which is relative to
+// the root even if the directory is outside of it.
+func (s *Service) localIDOf(abs string) string {
+	rel, err := filepath.Rel(s.cfg.Root, abs)
 	if err != nil {
-		return "", fmt.Errorf("error cloning project: %w", err)
+		return abs
+	}
+	return rel
+}
+
+// SuggestConflictPath returns the default path used by a conflict policy:
+// the new name of the existing directory for ConflictPolicyRename and
+// ConflictPolicyMoveAside, or the clone destination for
+// ConflictPolicyElsewhere.
+func SuggestConflictPath(project Project, policy ConflictPolicy) string {
+	abs := project.AbsolutePath
+
+	switch policy {
+	case ConflictPolicyMoveAside:
+		return availablePath(fmt.Sprintf("%s.bak-%s", abs, time.Now().Format("20060102150405")))
+	case ConflictPolicyRename:
+		return availablePath(abs + "-old")
+	default:
+		return availablePath(abs + "-2")
+	}
+}
+
+// availablePath returns path, or path with a numeric suffix, such that
+// nothing exists at the returned path.
+func availablePath(path string) string {
+	candidate := path
+	for i := 1; ; i++ {
+		if _, err := os.Lstat(candidate); errors.Is(err, os.ErrNotExist) {
+			return candidate
+		}
+		candidate = fmt.Sprintf("%s.%d", path, i)
+	}
+}
+
+func moveAside(from, to string) error {
+	if _, err := os.Lstat(to); err == nil {
+		return fmt.Errorf("path already exists: %s", to)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(to), 0o755); err != nil {
+		return fmt.Errorf("error creating directory: %w", err)
+	}
+
+	if err := os.Rename(from, to); err != nil {
+		return fmt.Errorf("error moving %q to %q: %w", from, to, err)
 	}
 
-	return output, nil
+	return nil
 }
@@ -0,0 +1,174 @@
+package project_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/MakeNowJust/heredoc/v2"
+
+	"github.com/zkhvan/z/pkg/assert"
+	"github.com/zkhvan/z/pkg/exec"
+	testingexec "github.com/zkhvan/z/pkg/exec/testing"
+	"github.com/zkhvan/z/pkg/project"
+)
+
+func TestCloneProject(t *testing.T) {
+	type existing struct {
+		// git creates a .git directory when true.
+		git bool
+		// remote is the origin URL returned by git, if git is true.
+		remote string
+	}
+
+	tests := map[string]struct {
+		existing   *existing
+		opts       *project.CloneOptions
+		clone      bool
+		err        string
+		wantPath   string
+		wantSource project.SourceType
+		movedAside bool
+	}{
+		"missing destination should be cloned": {
+			clone:      true,
+			wantPath:   "owner/repo",
+			wantSource: project.SourceTypeSynced,
+		},
+		"clone of the same remote should be reused": {
+			existing:   &existing{git: true, remote: "git@github.com:owner/repo.git"},
+			wantPath:   "owner/repo",
+			wantSource: project.SourceTypeSynced,
+		},
+		"different remote should fail without a prompt": {
+			existing: &existing{git: true, remote: "https://github.com/other/repo"},
+			err:      "project already exists: $PROJECTSDIR/owner/repo (different remote: https://github.com/other/repo)",
+		},
+		"non-repo directory should fail with the fail policy": {
+			existing: &existing{},
+			opts:     &project.CloneOptions{OnConflict: project.ConflictPolicyFail},
+			err:      "project already exists: $PROJECTSDIR/owner/repo (not a git repository)",
+		},
+		"non-repo directory should be moved aside": {
+			existing:   &existing{},
+			opts:       &project.CloneOptions{OnConflict: project.ConflictPolicyMoveAside},
+			clone:      true,
+			wantPath:   "owner/repo",
+			wantSource: project.SourceTypeSynced,
+			movedAside: true,
+		},
+		"non-repo directory should be kept when cloning elsewhere": {
+			existing:   &existing{},
+			opts:       &project.CloneOptions{OnConflict: project.ConflictPolicyElsewhere},
+			clone:      true,
+			wantPath:   "owner/repo-2",
+			wantSource: project.SourceTypeSynced,
+		},
+		"relative path outside the root should set the local ID": {
+			existing: &existing{},
+			opts: &project.CloneOptions{
+				Prompt: func(project.Conflict) (project.ConflictResolution, error) {
+					return project.ConflictResolution{Policy: project.ConflictPolicyElsewhere, Path: "elsewhere/repo"}, nil
+				},
+			},
+			clone:      true,
+			wantPath:   "../elsewhere/repo",
+			wantSource: project.SourceTypeSynced,
+		},
+		"prompt should decide the resolution": {
+			existing: &existing{},
+			opts: &project.CloneOptions{
+				Prompt: func(project.Conflict) (project.ConflictResolution, error) {
+					return project.ConflictResolution{Policy: project.ConflictPolicyRename}, nil
+				},
+			},
+			clone:      true,
+			wantPath:   "owner/repo",
+			wantSource: project.SourceTypeSynced,
+			movedAside: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			td := setupTestDir(t)
+			cfg := setupConfig(t, td, heredoc.Doc(`
+				projects:
+				  root: $PROJECTSDIR
+			`))
+			// Relative paths are resolved from the working directory.
+			t.Chdir(td.root)
+
+			dest := filepath.Join(td.projects, "owner", "repo")
+			fakeexec := &testingexec.FakeExec{}
+
+			if test.existing != nil {
+				assert.NoError(t, os.MkdirAll(dest, 0o700))
+				if test.existing.git {
+					assert.NoError(t, os.MkdirAll(filepath.Join(dest, ".git"), 0o700))
+					fakeexec.CommandScript = append(fakeexec.CommandScript, func(_ string, _ ...string) exec.Cmd {
+						fakeCmd := testingexec.NewFakeCmd("git", "-C", dest, "remote", "get-url", "origin")
+						fakeCmd.OutputScripts = []testingexec.FakeAction{
+							func() ([]byte, []byte, error) {
+								return []byte(test.existing.remote + "\n"), nil, nil
+							},
+						}
+						return fakeCmd
+					})
+				}
+			}
+
+			if test.clone {
+				wantDest := filepath.Join(td.projects, test.wantPath)
+				fakeexec.CommandScript = append(fakeexec.CommandScript, func(_ string, _ ...string) exec.Cmd {
+					fakeCmd := testingexec.NewFakeCmd("gh", "repo", "clone", "https://github.com/owner/repo", wantDest)
+					fakeCmd.CombinedOutputScripts = []testingexec.FakeAction{
+						func() ([]byte, []byte, error) {
+							return nil, nil, os.MkdirAll(wantDest, 0o700)
+						},
+					}
+					return fakeCmd
+				})
+			}
+
+			service, err := project.NewService(
+				cfg,
+				project.WithCacheDir(td.cache),
+				project.WithExecutor(fakeexec),
+			)
+			assert.NoError(t, err)
+
+			proj, err := service.Get(context.Background(), "owner/repo")
+			assert.NoError(t, err)
+			proj.Source = project.SourceTypeRemote
+
+			got, _, err := service.CloneProject(context.Background(), proj, test.opts)
+			if test.err != "" {
+				var conflictErr *project.ConflictError
+				if !errors.As(err, &conflictErr) {
+					t.Fatalf("expected a conflict error, got %v", err)
+				}
+				assert.Error(t, err, errors.New(replaceTestDirs(test.err, td)))
+				return
+			}
+			assert.NoError(t, err)
+
+			assert.EqualString(t, got.AbsolutePath, filepath.Join(td.projects, test.wantPath))
+			assert.EqualString(t, got.LocalID, filepath.FromSlash(test.wantPath))
+			if got.Source != test.wantSource {
+				t.Fatalf("expected source %s, got %s", test.wantSource, got.Source)
+			}
+			if fakeexec.CommandCalls != len(fakeexec.CommandScript) {
+				t.Fatalf("expected %d commands, got %d", len(fakeexec.CommandScript), fakeexec.CommandCalls)
+			}
+
+			siblings, err := filepath.Glob(dest + "?*")
+			assert.NoError(t, err)
+			if test.movedAside && len(siblings) != 1 {
+				t.Fatalf("expected the existing directory to be moved aside, found %v", siblings)
+			}
+		})
+	}
+}
@@ -2,9 +2,16 @@ package project
 
 import (
 	"cmp"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"path"
 	"path/filepath"
+	"slices"
 	"strings"
+	"unicode"
 
 	"github.com/zkhvan/z/pkg/cmdutil"
 	"github.com/zkhvan/z/pkg/config"
@@ -21,20 +28,63 @@ type Config struct {
 	// TTL is the time to live (in seconds) for the cache.
 	TTL int64 `json:"ttl"`
 
+	// Concurrency is the maximum number of remote patterns loaded at once.
+	Concurrency int `json:"concurrency"`
+
+	// Offline serves the remote projects from the cache, even if expired,
+	// without calling the providers.
+	Offline bool `json:"offline"`
+
+	// PreviewWindow is the layout of the project preview in "z project
+	// select", see the --preview-window option of fzf, e.g. "down:40%". With
+	// "hidden", the preview is only shown with CTRL-/.
+	PreviewWindow string `json:"preview_window"`
+
 	// RemotePatterns is a list of patterns to match remote repositories.
 	//
 	// The pattern format is as follows:
 	//
-	//	owner/repo -> ./alternate-path
+	//	[host/]owner/repo -> ./alternate-path
 	//
-	// The repo can be "*" to find all the repos under that owner. The
-	// alternate path is relative to the root directory. If the alternate path
-	// ends with a "/", the repo name (without the owner) will be used
-	// instead.
+	// The repo can be "*" to find all the repos under that owner. The host
+	// selects the provider and defaults to github.com. For providers with
+	// nested groups, the owner can contain slashes, e.g.
+	// "gitlab.com/group/subgroup/*". The alternate path is relative to the
+	// root directory. If the alternate path ends with "/*", e.g. "./oss/*",
+	// the repo name (without the owner) will be used instead. The former
+	// spelling, a trailing "/", is deprecated.
+	//
+	// Instead of an owner and repo, GitHub patterns can list the following
+	// sources:
+	//
+	//	@me/*                 the repos of the authenticated user
+	//	starred:[user]        the repos starred by the user, or by you
+	//	team:org/team         the repos of a team
+	//	topic:name            the repos with a topic
+	//	search:query          the repos matching a search query
 	RemotePatterns []string `json:"remote_patterns"`
 
+	// Providers configures the services hosting remote repositories. The
+	// github.com and gitlab.com hosts are available by default.
+	//
+	//	providers:
+	//	  - host: gitlab.example.com
+	//	    type: gitlab
+	//	    token: $GITLAB_EXAMPLE_TOKEN
+	Providers []ProviderConfig `json:"providers"`
+
+	// Aliases maps short names to project IDs, for example:
+	//
+	//	aliases:
+	//	  z: zkhvan/z
+	//	  dots: ~/Projects/personal/dotfiles
+	Aliases map[string]string `json:"aliases"`
+
 	// remotePatterns is a list of parsed remote patterns.
 	remotePatterns []remotePattern `json:"-"`
+
+	// profile is the config profile in use, if any.
+	profile string
 }
 
 func NewConfig(cfg cmdutil.Config) (Config, error) {
@@ -47,6 +97,7 @@ func NewConfig(cfg cmdutil.Config) (Config, error) {
 
 	c = c.setDefaults()
 	c.Root = oslib.Expand(c.Root)
+	c.profile = config.ActiveProfile(cfg)
 
 	patterns, err := c.parseRemotePatterns()
 	if err != nil {
@@ -57,13 +108,49 @@ func NewConfig(cfg cmdutil.Config) (Config, error) {
 	return c, nil
 }
 
+// ConfigSection returns the config section of the projects, with its
+// defaults.
+func ConfigSection() config.Section {
+	return config.Section{
+		Key:   "projects",
+		Value: Config{}.setDefaults(),
+		Validators: map[string]func(string) error{
+			"remote_patterns": validateRemotePattern,
+		},
+		Migrations: map[string]func(string) (string, bool){
+			"remote_patterns": migrateRemotePattern,
+		},
+	}
+}
+
+// hash returns a hash of the settings which change the listed projects, so a
+// client can tell whether the daemon runs with the same config.
+func (c Config) hash() string {
+	// The settings of the client only.
+	c.Offline = false
+	c.PreviewWindow = ""
+
+	b, err := json.Marshal(c)
+	if err != nil {
+		return ""
+	}
+
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:8])
+}
+
 func (c Config) setDefaults() Config {
 	c.MaxDepth = cmp.Or(c.MaxDepth, 3)
+	c.Concurrency = cmp.Or(c.Concurrency, 4)
 
 	if c.Root == "" {
 		c.Root = "~/Projects"
 	}
 
+	if c.PreviewWindow == "" {
+		c.PreviewWindow = "right:50%"
+	}
+
 	if c.TTL == 0 {
 		c.TTL = 15 * 60 // 15 minutes
 	}
@@ -73,22 +160,84 @@ func (c Config) setDefaults() Config {
 
 // remotePattern represents a pattern with the following format:
 //
-//	owner/repo -> ./alternate-path
+//	[host/]owner/repo -> ./alternate-path
+//	[host/]source -> ./alternate-path
+//	[host/]owner/repo -> ./alternate-path/*
 //
-// If the pattern is in the format above, the AlternatePath will be set.
+// If the pattern is in the format above, the AlternatePath will be set. A
+// trailing "/*" puts the repositories right in the alternate path, leaving the
+// owner out.
 type remotePattern struct {
 	original string
 
-	Owner         string
-	Repo          string
+	// Host is empty for DefaultHost.
+	Host  string
+	Owner string
+	Repo  string
+
+	// Source is set instead of the owner and repo for the patterns listing
+	// other sources, e.g. "starred:".
+	Source Source
+
 	AlternatePath string
+	// RepoOnly is set if the alternate path ends with "/*", or the deprecated
+	// "/", in which case the owner is left out of the local ID.
+	RepoOnly bool
+}
+
+// matches reports whether the pattern matches the repository. A "*" pattern
+// also matches the repositories of nested groups, since they're listed along
+// with the group's own repositories. Source patterns don't match any
+// repository, since membership of a source isn't known without listing it,
+// see Service.sourceLists.
+func (p remotePattern) matches(host, owner, repo string) bool {
+	if p.Source.Kind != "" || p.Host != projectHost(host) {
+		return false
+	}
+
+	if p.Repo == "*" {
+		return p.Owner == owner || strings.HasPrefix(owner, p.Owner+"/")
+	}
+
+	return p.Owner == owner && p.Repo == repo
+}
+
+// selectedBy reports whether the pattern is selected by a filter, which is
+// the pattern itself, e.g. "my-org/*", or its owner, e.g. "my-org" or
+// "ghe.corp.com/my-org".
+func (p remotePattern) selectedBy(filter string) bool {
+	filter = strings.TrimSpace(filter)
+
+	target, _, _ := strings.Cut(p.original, "->")
+	if filter == strings.TrimSpace(p.original) || filter == strings.TrimSpace(target) {
+		return true
+	}
+
+	if p.Source.Kind != "" {
+		return false
+	}
+
+	host := cmp.Or(p.Host, DefaultHost)
+	return filter == host+"/"+p.Owner || (p.Host == "" && filter == p.Owner)
+}
+
+// mapLocalID maps the local ID of a repository matched or listed by the
+// pattern into its alternate path.
+func (p remotePattern) mapLocalID(localID string) string {
+	if p.RepoOnly {
+		localID = path.Base(filepath.ToSlash(localID))
+	}
+
+	// The alternate path might be empty, but filepath.Join will handle it
+	// gracefully.
+	return filepath.Join(p.AlternatePath, localID)
 }
 
 func (c Config) parseRemotePatterns() ([]remotePattern, error) {
 	patterns := make([]remotePattern, 0, len(c.RemotePatterns))
 
 	for _, pattern := range c.RemotePatterns {
-		parsed, err := parseRemotePattern(pattern)
+		parsed, err := parseRemotePattern(pattern, c.isHost)
 		if err != nil {
 			return nil, err
 		}
@@ -99,7 +248,45 @@ func (c Config) parseRemotePatterns() ([]remotePattern, error) {
 	return patterns, nil
 }
 
-func parseRemotePattern(pattern string) (remotePattern, error) {
+// isHost reports whether the segment is a host, either because it looks like
+// one or because it's the name of a configured provider.
+func (c Config) isHost(segment string) bool {
+	if looksLikeHost(segment) {
+		return true
+	}
+
+	for _, pc := range c.Providers {
+		if normalizeHost(pc.Host) == normalizeHost(segment) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// validateRemotePattern checks the syntax of a remote pattern. Any segment
+// can be a host, since the configured providers aren't known.
+func validateRemotePattern(pattern string) error {
+	_, err := parseRemotePattern(pattern, func(string) bool { return true })
+	return err
+}
+
+// repoOnlyMarker ends the alternate paths which leave the owner out, e.g.
+// "cli/cli -> ./oss/*" maps to "oss/cli".
+const repoOnlyMarker = "*"
+
+// migrateRemotePattern rewrites the deprecated alternate paths ending with a
+// "/", e.g. "cli/cli -> ./oss/", to the repo-only marker they stand for.
+func migrateRemotePattern(pattern string) (string, bool) {
+	_, alternatePath, ok := strings.Cut(pattern, "->")
+	if !ok || !strings.HasSuffix(strings.TrimSpace(alternatePath), "/") {
+		return pattern, false
+	}
+
+	return strings.TrimRightFunc(pattern, unicode.IsSpace) + repoOnlyMarker, true
+}
+
+func parseRemotePattern(pattern string, isHost func(string) bool) (remotePattern, error) {
 	out := remotePattern{
 		original: pattern,
 	}
@@ -108,17 +295,93 @@ func parseRemotePattern(pattern string) (remotePattern, error) {
 	parts := strings.Split(pattern, "->")
 	if len(parts) == 2 {
 		alternatePath := strings.TrimSpace(parts[1])
+		switch {
+		case alternatePath == repoOnlyMarker || strings.HasSuffix(alternatePath, "/"+repoOnlyMarker):
+			alternatePath = strings.TrimSuffix(alternatePath, repoOnlyMarker)
+			out.RepoOnly = true
+		case strings.HasSuffix(alternatePath, "/"):
+			// The deprecated spelling of the marker, rewritten by
+			// migrateRemotePattern when the config is loaded.
+			out.RepoOnly = true
+		}
 		out.AlternatePath = filepath.Clean(alternatePath)
 	}
 
-	// Parse the owner/repo
-	parts = strings.Split(strings.TrimSpace(parts[0]), "/")
-	if len(parts) != 2 {
+	// Parse the [host/]source
+	target := strings.TrimSpace(parts[0])
+	if host, rest, ok := strings.Cut(target, "/"); ok && isHost(host) && isSource(rest) {
+		out.Host = projectHost(host)
+		target = rest
+	}
+
+	if isSource(target) {
+		source, err := parseSource(target)
+		if err != nil {
+			return out, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+
+		out.Source = source
+		return out, nil
+	}
+
+	// Parse the [host/]owner/repo
+	parts = strings.Split(target, "/")
+	if len(parts) > 2 && isHost(parts[0]) {
+		out.Host = projectHost(parts[0])
+		parts = parts[1:]
+	}
+
+	if len(parts) < 2 || (out.Host == "" && len(parts) != 2) {
 		return out, fmt.Errorf("invalid pattern: %q", pattern)
 	}
 
-	out.Owner = parts[0]
-	out.Repo = parts[1]
+	for _, part := range parts {
+		if part == "" {
+			return out, fmt.Errorf("invalid pattern: %q", pattern)
+		}
+	}
+
+	out.Owner = strings.Join(parts[:len(parts)-1], "/")
+	out.Repo = parts[len(parts)-1]
 
 	return out, nil
 }
+
+var sourceKinds = []string{SourceKindStarred, SourceKindTeam, SourceKindTopic, SourceKindSearch}
+
+// isSource reports whether the pattern lists a source instead of an owner.
+func isSource(pattern string) bool {
+	if pattern == SourceKindViewer+"/*" {
+		return true
+	}
+
+	kind, _, ok := strings.Cut(pattern, ":")
+	return ok && slices.Contains(sourceKinds, kind)
+}
+
+func parseSource(pattern string) (Source, error) {
+	if pattern == SourceKindViewer+"/*" {
+		return Source{Kind: SourceKindViewer}, nil
+	}
+
+	kind, arg, _ := strings.Cut(pattern, ":")
+	source := Source{Kind: kind, Arg: strings.TrimSpace(arg)}
+
+	switch kind {
+	case SourceKindStarred:
+		if strings.Contains(source.Arg, "/") {
+			return source, errors.New("expected starred: or starred:user")
+		}
+	case SourceKindTeam:
+		org, team, ok := strings.Cut(source.Arg, "/")
+		if !ok || org == "" || team == "" || strings.Contains(team, "/") {
+			return source, errors.New("expected team:org/team")
+		}
+	case SourceKindTopic, SourceKindSearch:
+		if source.Arg == "" {
+			return source, fmt.Errorf("expected %s:<%s>", kind, kind)
+		}
+	}
+
+	return source, nil
+}
@@ -0,0 +1,355 @@
+package project
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/zkhvan/z/pkg/fcache"
+	"github.com/zkhvan/z/pkg/jsonrpc"
+)
+
+const (
+	daemonSocketName = "daemon.sock"
+	daemonLockKey    = "daemon"
+
+	// reindexDelay groups the filesystem events of, e.g., a clone into a
+	// single reindex.
+	reindexDelay = 200 * time.Millisecond
+	// daemonTick is how often the daemon refreshes the expired patterns and
+	// reindexes the projects root, in case filesystem events were missed.
+	daemonTick = time.Minute
+)
+
+// ErrDaemonRunning is returned by Daemon.Run when a daemon is already running.
+var ErrDaemonRunning = errors.New("the daemon is already running")
+
+// DaemonSocket returns the path of the Unix socket of the daemon.
+func DaemonSocket(cacheDir string) string {
+	return filepath.Join(fcache.NormalizeCacheDir(cacheDir), daemonSocketName)
+}
+
+// DaemonStatus is the answer of the daemon to the "status" method.
+type DaemonStatus struct {
+	PID       int       `json:"pid"`
+	StartedAt time.Time `json:"started_at"`
+	Root      string    `json:"root"`
+	// LocalProjects is the number of local projects in the index.
+	LocalProjects int       `json:"local_projects"`
+	IndexedAt     time.Time `json:"indexed_at"`
+	// Watches is the number of watched directories.
+	Watches int           `json:"watches"`
+	Refresh RefreshStatus `json:"refresh"`
+	// Profile is the config profile of the daemon, if any.
+	Profile string `json:"profile,omitempty"`
+	// ConfigHash identifies the config the daemon was started with, which
+	// the clients compare with theirs.
+	ConfigHash string `json:"config_hash"`
+}
+
+// ResolveParams are the params of the "resolve" method.
+type ResolveParams struct {
+	Input string `json:"input"`
+}
+
+// Daemon keeps the local projects indexed by watching the projects root, and
+// refreshes the remote projects once their cache expires. It answers the
+// "list", "status" and "resolve" methods over a Unix socket, with JSON-RPC.
+type Daemon struct {
+	s         *Service
+	startedAt time.Time
+
+	// reindexMu serializes the reindexes, and guards the index and the
+	// watched directories.
+	reindexMu sync.Mutex
+	index     localIndex
+	watcher   *fsnotify.Watcher
+	watched   map[string]bool
+
+	// mu guards what's served.
+	mu        sync.RWMutex
+	local     []Project
+	indexedAt time.Time
+	watches   int
+
+	refresh chan struct{}
+	stop    context.CancelFunc
+}
+
+// NewDaemon returns a daemon serving the projects of the service.
+func NewDaemon(s *Service) *Daemon {
+	d := &Daemon{
+		s:       s,
+		watched: make(map[string]bool),
+		refresh: make(chan struct{}, 1),
+	}
+
+	// An expired cache is served right away, and refreshed by the daemon.
+	s.backgroundRefresh = func() error {
+		d.requestRefresh()
+		return nil
+	}
+
+	return d
+}
+
+// Run indexes the projects and serves the API until the context is canceled
+// or the "shutdown" method is called.
+func (d *Daemon) Run(ctx context.Context) error {
+	if d.s.cacheDir == "" {
+		return errors.New("the daemon requires a cache directory")
+	}
+
+	ctx, d.stop = context.WithCancel(ctx)
+	defer d.stop()
+
+	unlock, err := fcache.TryLock(d.s.cacheDir, daemonLockKey)
+	if errors.Is(err, fcache.ErrLocked) {
+		return ErrDaemonRunning
+	}
+	if err != nil {
+		return fmt.Errorf("error locking the daemon: %w", err)
+	}
+	defer unlock()
+
+	d.startedAt = time.Now()
+
+	d.watcher, err = fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("error creating the watcher: %w", err)
+	}
+	defer d.watcher.Close()
+
+	previous, err := fcache.Load[localIndex](d.s.cacheDir, localIndexKey)
+	if err != nil && !errors.Is(err, fcache.ErrNotFound) {
+		return fmt.Errorf("error loading the local index: %w", err)
+	}
+	d.index = previous
+
+	if err := d.reindex(ctx); err != nil {
+		return err
+	}
+
+	// The lock is held, so the socket of a daemon which crashed can be
+	// removed.
+	socket := filepath.Join(d.s.cacheDir, daemonSocketName)
+	if err := os.Remove(socket); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("error removing the stale socket: %w", err)
+	}
+
+	l, err := net.Listen("unix", socket)
+	if err != nil {
+		return fmt.Errorf("error listening on %q: %w", socket, err)
+	}
+	defer os.Remove(socket)
+
+	if err := os.Chmod(socket, 0o600); err != nil {
+		l.Close()
+		return fmt.Errorf("error restricting the socket: %w", err)
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		d.watch(ctx)
+	}()
+	go func() {
+		defer wg.Done()
+		d.schedule(ctx)
+	}()
+	defer wg.Wait()
+
+	server := jsonrpc.NewServer()
+	server.Handle("list", d.handleList)
+	server.Handle("status", d.handleStatus)
+	server.Handle("resolve", d.handleResolve)
+	server.Handle("shutdown", func(context.Context, json.RawMessage) (any, error) {
+		d.stop()
+		return nil, nil
+	})
+
+	return server.Serve(ctx, l)
+}
+
+func (d *Daemon) handleList(ctx context.Context, params json.RawMessage) (any, error) {
+	opts := &ListOptions{}
+	if err := jsonrpc.DecodeParams(params, opts); err != nil {
+		return nil, err
+	}
+
+	remote, err := d.s.listRemoteProjects(ctx, opts)
+	if err != nil {
+		return nil, fmt.Errorf("error listing remote projects: %w", err)
+	}
+
+	var local []Project
+	if opts.Local {
+		d.mu.RLock()
+		local = d.local
+		d.mu.RUnlock()
+	}
+
+	return d.s.combineProjects(remote, local), nil
+}
+
+func (d *Daemon) handleStatus(context.Context, json.RawMessage) (any, error) {
+	refresh, err := d.s.RefreshStatus()
+	if err != nil && !errors.Is(err, fcache.ErrNotFound) {
+		return nil, err
+	}
+
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	return DaemonStatus{
+		PID:           os.Getpid(),
+		StartedAt:     d.startedAt,
+		Root:          d.s.cfg.Root,
+		LocalProjects: len(d.local),
+		IndexedAt:     d.indexedAt,
+		Watches:       d.watches,
+		Refresh:       refresh,
+		Profile:       d.s.cfg.profile,
+		ConfigHash:    d.s.cfg.hash(),
+	}, nil
+}
+
+func (d *Daemon) handleResolve(ctx context.Context, params json.RawMessage) (any, error) {
+	var p ResolveParams
+	if err := jsonrpc.DecodeParams(params, &p); err != nil {
+		return nil, err
+	}
+
+	return d.s.Resolve(ctx, p.Input)
+}
+
+// reindex updates the index of the projects root, and watches its
+// directories.
+func (d *Daemon) reindex(ctx context.Context) error {
+	d.reindexMu.Lock()
+	defer d.reindexMu.Unlock()
+
+	index, local, err := d.s.indexLocalProjects(ctx, d.index)
+	if err != nil {
+		return err
+	}
+	d.index = index
+
+	d.updateWatches(index)
+
+	d.mu.Lock()
+	d.local = local
+	d.indexedAt = time.Now()
+	d.watches = len(d.watched)
+	d.mu.Unlock()
+
+	return nil
+}
+
+// updateWatches watches the directories of the index, which get an event
+// when a subdirectory or a ".git" entry is added or removed.
+func (d *Daemon) updateWatches(index localIndex) {
+	for dir := range d.watched {
+		if _, ok := index.Dirs[dir]; !ok {
+			_ = d.watcher.Remove(filepath.Join(index.Root, dir))
+			delete(d.watched, dir)
+		}
+	}
+
+	for dir := range index.Dirs {
+		if d.watched[dir] {
+			continue
+		}
+
+		if err := d.watcher.Add(filepath.Join(index.Root, dir)); err != nil {
+			// The periodic reindex still finds the changes.
+			d.s.warnf("error watching %q: %v", dir, err)
+			continue
+		}
+		d.watched[dir] = true
+	}
+}
+
+// watch reindexes the projects root when the watched directories change.
+func (d *Daemon) watch(ctx context.Context) {
+	timer := time.NewTimer(0)
+	<-timer.C
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-d.watcher.Events:
+			if !ok {
+				return
+			}
+			// Writes don't add or remove entries.
+			if event.Has(fsnotify.Create) || event.Has(fsnotify.Remove) || event.Has(fsnotify.Rename) {
+				timer.Reset(reindexDelay)
+			}
+		case err, ok := <-d.watcher.Errors:
+			if !ok {
+				return
+			}
+			// Events might have been dropped.
+			d.s.warnf("error watching the projects root: %v", err)
+			timer.Reset(reindexDelay)
+		case <-timer.C:
+			if err := d.reindex(ctx); err != nil && ctx.Err() == nil {
+				d.s.warnf("error indexing the local projects: %v", err)
+			}
+		}
+	}
+}
+
+// schedule refreshes the expired patterns and reindexes the projects root
+// periodically, and refreshes on request.
+func (d *Daemon) schedule(ctx context.Context) {
+	ticker := time.NewTicker(daemonTick)
+	defer ticker.Stop()
+
+	d.refreshExpired(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-d.refresh:
+			d.refreshExpired(ctx)
+		case <-ticker.C:
+			if err := d.reindex(ctx); err != nil && ctx.Err() == nil {
+				d.s.warnf("error indexing the local projects: %v", err)
+			}
+			d.refreshExpired(ctx)
+		}
+	}
+}
+
+func (d *Daemon) requestRefresh() {
+	select {
+	case d.refresh <- struct{}{}:
+	default:
+		// A refresh is already requested.
+	}
+}
+
+func (d *Daemon) refreshExpired(ctx context.Context) {
+	if d.s.offline {
+		return
+	}
+
+	err := d.s.Refresh(ctx, &RefreshOptions{ExpiredOnly: true})
+	if err != nil && !errors.Is(err, ErrRefreshRunning) && ctx.Err() == nil {
+		d.s.warnf("error refreshing the remote projects: %v", err)
+	}
+}
@@ -0,0 +1,78 @@
+package project
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/zkhvan/z/pkg/jsonrpc"
+)
+
+// daemonDialTimeout bounds how long connecting to a daemon which isn't
+// running can take.
+const daemonDialTimeout = 100 * time.Millisecond
+
+// WithDaemon lists the projects through the daemon listening on the socket
+// when it's running, instead of scanning the projects root.
+func WithDaemon(socket string) ServiceOption {
+	return func(s *Service) {
+		s.daemonSocket = socket
+	}
+}
+
+// DialDaemon connects to the daemon listening on the socket. It fails if the
+// daemon isn't running.
+func DialDaemon(ctx context.Context, socket string) (*jsonrpc.Client, error) {
+	ctx, cancel := context.WithTimeout(ctx, daemonDialTimeout)
+	defer cancel()
+
+	return jsonrpc.Dial(ctx, "unix", socket)
+}
+
+// listFromDaemon lists the projects through the daemon. It returns false if
+// the daemon isn't running, or the projects must be listed directly, e.g. to
+// refresh the cache or because the daemon runs with another config.
+func (s *Service) listFromDaemon(ctx context.Context, opts *ListOptions) ([]Project, bool) {
+	if s.daemonSocket == "" || s.refreshCache || s.rescan || s.offline {
+		return nil, false
+	}
+
+	client, err := DialDaemon(ctx, s.daemonSocket)
+	if err != nil {
+		return nil, false
+	}
+	defer client.Close()
+
+	var status DaemonStatus
+	if err := client.Call(ctx, "status", nil, &status); err != nil {
+		s.warnf("error checking the status of the daemon, listing the projects directly: %v", err)
+		return nil, false
+	}
+	if status.Profile != s.cfg.profile {
+		s.warnf(
+			"the daemon runs with %s instead of %s, listing the projects directly",
+			describeProfile(status.Profile), describeProfile(s.cfg.profile),
+		)
+		return nil, false
+	}
+	if status.ConfigHash != s.cfg.hash() {
+		s.warnf("the daemon runs with another config, restart it to use it; listing the projects directly")
+		return nil, false
+	}
+
+	var projects []Project
+	if err := client.Call(ctx, "list", opts, &projects); err != nil {
+		s.warnf("error listing the projects with the daemon, listing them directly: %v", err)
+		return nil, false
+	}
+
+	return projects, true
+}
+
+// describeProfile names the profile in messages.
+func describeProfile(profile string) string {
+	if profile == "" {
+		return "no profile"
+	}
+	return fmt.Sprintf("the profile %q", profile)
+}
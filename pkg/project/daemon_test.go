@@ -0,0 +1,156 @@
+package project_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MakeNowJust/heredoc/v2"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/zkhvan/z/pkg/assert"
+	"github.com/zkhvan/z/pkg/config"
+	"github.com/zkhvan/z/pkg/jsonrpc"
+	"github.com/zkhvan/z/pkg/project"
+)
+
+func TestDaemon(t *testing.T) {
+	td := setupTestDir(t)
+	cfg := setupConfig(t, td, heredoc.Doc(`
+		projects:
+		  root: $PROJECTSDIR
+	`))
+	assert.NoError(t, os.MkdirAll(filepath.Join(td.projects, "owner", "a", ".git"), 0o700))
+
+	service, err := project.NewService(cfg, project.WithCacheDir(td.cache))
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- project.NewDaemon(service).Run(ctx)
+	}()
+
+	socket := project.DaemonSocket(td.cache)
+	client := waitForDaemon(t, socket)
+	defer client.Close()
+
+	// A second daemon doesn't start.
+	other, err := project.NewService(cfg, project.WithCacheDir(td.cache))
+	assert.NoError(t, err)
+	err = project.NewDaemon(other).Run(ctx)
+	if !errors.Is(err, project.ErrDaemonRunning) {
+		t.Fatalf("expected ErrDaemonRunning, got %v", err)
+	}
+
+	// A project added while the daemon runs is listed through it.
+	assert.NoError(t, os.MkdirAll(filepath.Join(td.projects, "owner", "b", ".git"), 0o700))
+
+	listed := project.WithDaemon(socket)
+	expected := []string{"owner/a", "owner/b"}
+	eventually(t, func() bool {
+		service, err := project.NewService(cfg, project.WithCacheDir(td.cache), listed)
+		assert.NoError(t, err)
+
+		projects, err := service.ListProjects(context.Background(), &project.ListOptions{Local: true})
+		assert.NoError(t, err)
+
+		var ids []string
+		for _, p := range projects {
+			ids = append(ids, filepath.ToSlash(p.LocalID))
+		}
+		return cmp.Equal(expected, ids)
+	})
+
+	// A client with another config lists the projects directly.
+	changed := setupConfig(t, td, heredoc.Doc(`
+		projects:
+		  root: $PROJECTSDIR
+		  max_depth: 2
+	`))
+	var warnings strings.Builder
+	service, err = project.NewService(changed, project.WithCacheDir(td.cache), listed, project.WithWarnings(&warnings))
+	assert.NoError(t, err)
+	_, err = service.ListProjects(context.Background(), &project.ListOptions{Local: true})
+	assert.NoError(t, err)
+	if !strings.Contains(warnings.String(), "the daemon runs with another config") {
+		t.Errorf("expected a warning about the config, got %q", warnings.String())
+	}
+
+	// So does a client with another profile, even if it changes nothing.
+	profiled := setupConfig(t, td, heredoc.Doc(`
+		projects:
+		  root: $PROJECTSDIR
+		profiles:
+		  work:
+		    directories: []
+	`))
+	assert.NoError(t, config.UseProfile(profiled, "work"))
+	warnings.Reset()
+	service, err = project.NewService(profiled, project.WithCacheDir(td.cache), listed, project.WithWarnings(&warnings))
+	assert.NoError(t, err)
+	_, err = service.ListProjects(context.Background(), &project.ListOptions{Local: true})
+	assert.NoError(t, err)
+	if !strings.Contains(warnings.String(), `the daemon runs with no profile instead of the profile "work"`) {
+		t.Errorf("expected a warning about the profile, got %q", warnings.String())
+	}
+
+	var status project.DaemonStatus
+	assert.NoError(t, client.Call(ctx, "status", nil, &status))
+	if status.PID != os.Getpid() || status.LocalProjects != 2 {
+		t.Errorf("unexpected status: %+v", status)
+	}
+
+	var p project.Project
+	assert.NoError(t, client.Call(ctx, "resolve", project.ResolveParams{Input: "owner/b"}, &p))
+	assert.EqualString(t, filepath.Join(td.projects, "owner", "b"), p.AbsolutePath)
+
+	var rpcErr *jsonrpc.Error
+	err = client.Call(ctx, "unknown", nil, nil)
+	if !errors.As(err, &rpcErr) || rpcErr.Code != jsonrpc.CodeMethodNotFound {
+		t.Errorf("expected a method not found error, got %v", err)
+	}
+
+	assert.NoError(t, client.Call(ctx, "shutdown", nil, nil))
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the daemon didn't stop")
+	}
+
+	if _, err := os.Stat(socket); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected the socket to be removed, got %v", err)
+	}
+}
+
+func waitForDaemon(t *testing.T, socket string) *jsonrpc.Client {
+	t.Helper()
+
+	var client *jsonrpc.Client
+	eventually(t, func() bool {
+		var err error
+		client, err = project.DialDaemon(context.Background(), socket)
+		return err == nil
+	})
+
+	return client
+}
+
+func eventually(t *testing.T, condition func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met in time")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
@@ -0,0 +1,94 @@
+package project
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/zkhvan/z/pkg/fcache"
+)
+
+// detailsCacheNamespace is the cache namespace of the repositories' details.
+const detailsCacheNamespace = "projects.details"
+
+// detailsCache returns the cache of the details of each repository.
+func (s *Service) detailsCache() *fcache.Namespace[RepoDetails] {
+	return fcache.NewNamespace[RepoDetails](s.cacheDir, detailsCacheNamespace)
+}
+
+// RepoDetails returns the details of the project's remote repository. They're
+// served from the cached repositories of the remote patterns when listed with
+// them, and otherwise requested and cached like the remote projects, the
+// expired details being served when offline or if the provider fails.
+func (s *Service) RepoDetails(ctx context.Context, project Project) (RepoDetails, error) {
+	repo := project.Repo()
+	id := repo.Host + "/" + repo.ID()
+
+	if info, ok := s.cachedRepoInfo(repo); ok {
+		return RepoDetails{Repo: repo, RepoInfo: info}, nil
+	}
+
+	sum := sha256.Sum256([]byte(id))
+	key := hex.EncodeToString(sum[:8])
+
+	details, err := s.detailsCache().Load(key)
+	if err == nil {
+		return details, nil
+	}
+	if !errors.Is(err, fcache.ErrNotFound) {
+		return RepoDetails{}, fmt.Errorf("error loading cached details: %w", err)
+	}
+
+	if s.offline {
+		details, _, err := s.detailsCache().LoadLatest(key)
+		if errors.Is(err, fcache.ErrNotFound) {
+			return RepoDetails{}, fmt.Errorf("no cached details for %s while offline", id)
+		}
+		return details, err
+	}
+
+	provider, err := s.provider(project.Host)
+	if err != nil {
+		return RepoDetails{}, err
+	}
+
+	details, err = provider.ViewRepo(ctx, repo)
+	if err != nil {
+		if latest, _, cacheErr := s.detailsCache().LoadLatest(key); cacheErr == nil {
+			s.warnf("error loading the details of %s, using the cached ones: %v", id, err)
+			return latest, nil
+		}
+		return RepoDetails{}, err
+	}
+
+	expiry := time.Now().Add(s.patternTTL(remotePattern{Host: project.Host}))
+	if err := s.detailsCache().Save(key, details, expiry); err != nil {
+		return RepoDetails{}, fmt.Errorf("error saving details to cache: %w", err)
+	}
+
+	return details, nil
+}
+
+// cachedRepoInfo returns the info of the repository listed by the remote
+// patterns, from their last cached repositories even if expired.
+func (s *Service) cachedRepoInfo(repo Repo) (RepoInfo, bool) {
+	for _, pattern := range s.cfg.remotePatterns {
+		if projectHost(pattern.Host) != projectHost(repo.Host) {
+			continue
+		}
+		// Only the source patterns and the owner patterns list the
+		// repositories with their info.
+		if pattern.Source.Kind == "" && (pattern.Repo != "*" || pattern.Owner != repo.Owner) {
+			continue
+		}
+
+		if r, ok := s.cachedRepo(pattern, repo.Host, repo.ID()); ok && r.Info != nil {
+			return *r.Info, true
+		}
+	}
+
+	return RepoInfo{}, false
+}
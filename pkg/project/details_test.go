@@ -0,0 +1,136 @@
+package project_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/MakeNowJust/heredoc/v2"
+
+	"github.com/zkhvan/z/pkg/assert"
+	"github.com/zkhvan/z/pkg/project"
+)
+
+// detailsProvider serves the details of any repository, counting the calls.
+type detailsProvider struct {
+	calls int
+	err   error
+
+	// repos are listed for any owner.
+	repos []project.Repo
+}
+
+func (p *detailsProvider) Host() string { return "git.example.com" }
+
+func (p *detailsProvider) ListRepos(context.Context, string) ([]project.Repo, error) {
+	return p.repos, nil
+}
+
+func (p *detailsProvider) ViewRepo(_ context.Context, repo project.Repo) (project.RepoDetails, error) {
+	p.calls++
+	if p.err != nil {
+		return project.RepoDetails{}, p.err
+	}
+
+	return project.RepoDetails{
+		Repo: repo,
+		RepoInfo: project.RepoInfo{
+			Description: "the description",
+			Stars:       p.calls,
+			PushedAt:    time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		},
+	}, nil
+}
+
+func (p *detailsProvider) CloneURL(project.Repo) string { return "" }
+
+func (p *detailsProvider) WebURL(project.Repo) string { return "" }
+
+func TestService_RepoDetails(t *testing.T) {
+	td := setupTestDir(t)
+	cfg := setupConfig(t, td, "")
+	provider := &detailsProvider{}
+
+	details := func(opts ...project.ServiceOption) (project.RepoDetails, error) {
+		t.Helper()
+
+		opts = append(opts, project.WithCacheDir(td.cache), project.WithProvider(provider))
+		service, err := project.NewService(cfg, opts...)
+		assert.NoError(t, err)
+
+		return service.RepoDetails(context.Background(), project.Project{
+			Host:     "git.example.com",
+			RemoteID: "owner/repo",
+		})
+	}
+
+	// Nothing is cached while offline.
+	_, err := details(project.WithOffline(true))
+	assert.Error(t, err, errors.New("no cached details for git.example.com/owner/repo while offline"))
+
+	got, err := details()
+	assert.NoError(t, err)
+	assert.EqualString(t, got.Description, "the description")
+	assert.EqualString(t, got.ID(), "owner/repo")
+
+	// The cached details are served without calling the provider.
+	provider.err = errors.New("unreachable")
+	got, err = details()
+	assert.NoError(t, err)
+	if got.Stars != 1 || provider.calls != 1 {
+		t.Fatalf("expected the cached details, got %d stars after %d calls", got.Stars, provider.calls)
+	}
+}
+
+func TestService_RepoDetails_Listed(t *testing.T) {
+	td := setupTestDir(t)
+	cfg := setupConfig(t, td, heredoc.Doc(`
+		projects:
+		  root: $PROJECTSDIR
+		  remote_patterns:
+		    - git.example.com/owner/*
+	`))
+	provider := &detailsProvider{repos: []project.Repo{
+		{
+			Host:  "git.example.com",
+			Owner: "owner",
+			Name:  "listed",
+			Info:  &project.RepoInfo{Description: "listed with its info", Language: "Go", Stars: 42},
+		},
+		{Host: "git.example.com", Owner: "owner", Name: "bare"},
+	}}
+
+	service, err := project.NewService(cfg, project.WithCacheDir(td.cache), project.WithProvider(provider))
+	assert.NoError(t, err)
+
+	_, err = service.ListProjects(context.Background(), &project.ListOptions{Remote: true})
+	assert.NoError(t, err)
+
+	details := func(name string) project.RepoDetails {
+		t.Helper()
+
+		got, err := service.RepoDetails(context.Background(), project.Project{
+			Host:     "git.example.com",
+			RemoteID: "owner/" + name,
+		})
+		assert.NoError(t, err)
+		return got
+	}
+
+	// The info listed with the repository is served without calling the
+	// provider.
+	got := details("listed")
+	assert.EqualString(t, got.Description, "listed with its info")
+	assert.EqualString(t, got.Language, "Go")
+	if got.Stars != 42 || provider.calls != 0 {
+		t.Fatalf("expected the listed info, got %d stars after %d calls", got.Stars, provider.calls)
+	}
+
+	// The details of a repository listed without its info are requested.
+	got = details("bare")
+	assert.EqualString(t, got.Description, "the description")
+	if provider.calls != 1 {
+		t.Fatalf("expected the details to be requested, got %d calls", provider.calls)
+	}
+}
@@ -0,0 +1,202 @@
+package project
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// inferMaxDepth is the maximum depth walked to infer a layout. It's deeper
+// than the default max_depth, to find the repositories of deeper layouts.
+const inferMaxDepth = 6
+
+// Layout is the config inferred from the repositories checked out under a
+// directory, see InferLayout.
+type Layout struct {
+	Root           string
+	MaxDepth       int
+	RemotePatterns []string
+
+	// Repos is the number of repositories reproduced by the patterns.
+	Repos int
+
+	// Skipped lists the repositories which the patterns don't reproduce.
+	Skipped []SkippedRepo
+
+	// UnknownHosts lists the hosts of the patterns without a provider.
+	UnknownHosts []string
+}
+
+// SkippedRepo is a repository left out of an inferred layout.
+type SkippedRepo struct {
+	// Dir is the directory of the repository, relative to the root.
+	Dir    string
+	Reason string
+}
+
+// checkout is a repository checked out under the root, with the alternate
+// path its remote ID is mapped into.
+type checkout struct {
+	dir   string
+	host  string
+	owner string
+	repo  string
+	alt   alternatePath
+}
+
+type alternatePath struct {
+	path     string
+	repoOnly bool
+}
+
+// InferLayout walks the directory for Git repositories and infers the root,
+// the maximum depth and the remote patterns which reproduce their directories
+// from the "origin" remotes.
+//
+// A repository is reproduced if its directory ends with the owner and name of
+// its remote, e.g. "work/my-org/repo", or with its name only, e.g.
+// "work/repo". The patterns list all the repositories of an owner when its
+// repositories share the same alternate path.
+func (s *Service) InferLayout(ctx context.Context, root string) (Layout, error) {
+	layout := Layout{Root: root}
+
+	dirs, err := newIndexer(root, inferMaxDepth, localIndex{}).walk(ctx)
+	if err != nil {
+		return layout, fmt.Errorf("error walking %q: %w", root, err)
+	}
+
+	var checkouts []checkout
+	for _, dir := range dirs {
+		dir = filepath.ToSlash(dir)
+		layout.MaxDepth = max(layout.MaxDepth, strings.Count(dir, "/")+1)
+
+		c, err := s.inferCheckout(ctx, root, dir)
+		if err != nil {
+			layout.Skipped = append(layout.Skipped, SkippedRepo{Dir: dir, Reason: err.Error()})
+			continue
+		}
+		checkouts = append(checkouts, c)
+	}
+
+	layout.Repos = len(checkouts)
+	layout.RemotePatterns = inferPatterns(checkouts)
+
+	for _, c := range checkouts {
+		host := cmp.Or(c.host, DefaultHost)
+		if _, ok := s.providers[host]; !ok && !slices.Contains(layout.UnknownHosts, host) {
+			layout.UnknownHosts = append(layout.UnknownHosts, host)
+		}
+	}
+	slices.Sort(layout.UnknownHosts)
+
+	return layout, nil
+}
+
+func (s *Service) inferCheckout(ctx context.Context, root, dir string) (checkout, error) {
+	remoteURL, err := s.git.RemoteURL(ctx, filepath.Join(root, filepath.FromSlash(dir)), "")
+	if err != nil {
+		return checkout{}, fmt.Errorf("no origin remote")
+	}
+
+	host, repoPath, err := parseRemoteURL(remoteURL)
+	if err != nil {
+		return checkout{}, fmt.Errorf("unsupported remote %q", remoteURL)
+	}
+
+	c := checkout{
+		dir:   dir,
+		host:  projectHost(host),
+		owner: path.Dir(repoPath),
+		repo:  path.Base(repoPath),
+	}
+
+	switch {
+	case dir == repoPath:
+	case strings.HasSuffix(dir, "/"+repoPath):
+		c.alt.path = strings.TrimSuffix(dir, "/"+repoPath)
+	case path.Base(dir) == c.repo:
+		c.alt = alternatePath{path: path.Dir(dir), repoOnly: true}
+	default:
+		return checkout{}, fmt.Errorf("the directory doesn't match the remote %s", repoPath)
+	}
+
+	return c, nil
+}
+
+// inferPatterns returns the patterns mapping the remote IDs of the checkouts
+// into their directories. Since every matching pattern maps the local ID, an
+// owner is only listed with "*" if it has no nested groups, and if all its
+// repositories share the same alternate path.
+func inferPatterns(checkouts []checkout) []string {
+	type ownerKey struct{ host, owner string }
+
+	owners := make(map[ownerKey][]checkout)
+	for _, c := range checkouts {
+		key := ownerKey{c.host, c.owner}
+		owners[key] = append(owners[key], c)
+	}
+
+	keys := make([]ownerKey, 0, len(owners))
+	for key := range owners {
+		keys = append(keys, key)
+	}
+	slices.SortFunc(keys, func(a, b ownerKey) int {
+		return cmp.Or(cmp.Compare(a.host, b.host), cmp.Compare(a.owner, b.owner))
+	})
+
+	hasNested := func(key ownerKey) bool {
+		for other := range owners {
+			if other.host == key.host && strings.HasPrefix(other.owner, key.owner+"/") {
+				return true
+			}
+		}
+		return false
+	}
+
+	var patterns []string
+	for _, key := range keys {
+		repos := owners[key]
+
+		sameAlt := !slices.ContainsFunc(repos, func(c checkout) bool { return c.alt != repos[0].alt })
+		if len(repos) > 1 && sameAlt && !hasNested(key) {
+			patterns = append(patterns, repos[0].pattern("*"))
+			continue
+		}
+
+		slices.SortFunc(repos, func(a, b checkout) int { return cmp.Compare(a.repo, b.repo) })
+		for _, c := range repos {
+			patterns = append(patterns, c.pattern(c.repo))
+		}
+	}
+
+	return patterns
+}
+
+// pattern returns the remote pattern of the checkout, for the repo or "*".
+func (c checkout) pattern(repo string) string {
+	pattern := c.owner + "/" + repo
+	if c.host != "" {
+		pattern = c.host + "/" + pattern
+	}
+
+	if c.alt == (alternatePath{}) {
+		return pattern
+	}
+
+	alt := "./"
+	if c.alt.path != "." {
+		alt += c.alt.path
+	}
+	if c.alt.repoOnly {
+		if !strings.HasSuffix(alt, "/") {
+			alt += "/"
+		}
+		alt += "*"
+	}
+
+	return pattern + " -> " + alt
+}
@@ -0,0 +1,144 @@
+package project_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/zkhvan/z/pkg/assert"
+	"github.com/zkhvan/z/pkg/exec"
+	testingexec "github.com/zkhvan/z/pkg/exec/testing"
+	"github.com/zkhvan/z/pkg/project"
+)
+
+func TestService_InferLayout(t *testing.T) {
+	tests := map[string]struct {
+		// repos maps the directories of the repositories to their origin
+		// remote, empty for none.
+		repos map[string]string
+		want  project.Layout
+	}{
+		"owner/repo directories should need no alternate path": {
+			repos: map[string]string{
+				"zkhvan/z":        "git@github.com:zkhvan/z.git",
+				"zkhvan/dotfiles": "https://github.com/zkhvan/dotfiles",
+				"other/tool":      "https://github.com/other/tool.git",
+			},
+			want: project.Layout{
+				MaxDepth:       2,
+				RemotePatterns: []string{"other/tool", "zkhvan/*"},
+				Repos:          3,
+			},
+		},
+		"directories under a prefix should use an alternate path": {
+			repos: map[string]string{
+				"work/my-org/api": "git@github.com:my-org/api.git",
+				"work/my-org/web": "git@github.com:my-org/web.git",
+				"personal/site":   "git@github.com:zkhvan/site.git",
+			},
+			want: project.Layout{
+				MaxDepth: 3,
+				RemotePatterns: []string{
+					"my-org/* -> ./work",
+					"zkhvan/site -> ./personal/*",
+				},
+				Repos: 3,
+			},
+		},
+		"mixed alternate paths should use a pattern per repo": {
+			repos: map[string]string{
+				"my-org/api": "git@github.com:my-org/api.git",
+				"web":        "git@github.com:my-org/web.git",
+			},
+			want: project.Layout{
+				MaxDepth:       2,
+				RemotePatterns: []string{"my-org/api", "my-org/web -> ./*"},
+				Repos:          2,
+			},
+		},
+		"other hosts should be prefixed": {
+			repos: map[string]string{
+				"gitlab/group/sub/a": "git@gitlab.com:group/sub/a.git",
+				"gitlab/group/sub/b": "git@gitlab.com:group/sub/b.git",
+				"gitlab/group/c":     "git@gitlab.com:group/c.git",
+				"gitlab/group/d":     "git@gitlab.com:group/d.git",
+				"corp/team/svc":      "git@git.corp.com:team/svc.git",
+			},
+			want: project.Layout{
+				MaxDepth: 4,
+				RemotePatterns: []string{
+					"git.corp.com/team/svc -> ./corp",
+					"gitlab.com/group/c -> ./gitlab",
+					"gitlab.com/group/d -> ./gitlab",
+					"gitlab.com/group/sub/* -> ./gitlab",
+				},
+				Repos:        5,
+				UnknownHosts: []string{"git.corp.com"},
+			},
+		},
+		"unmatched repositories should be skipped": {
+			repos: map[string]string{
+				"zkhvan/z":  "git@github.com:zkhvan/z.git",
+				"scratch":   "",
+				"old/thing": "git@github.com:zkhvan/renamed.git",
+			},
+			want: project.Layout{
+				MaxDepth:       2,
+				RemotePatterns: []string{"zkhvan/z"},
+				Repos:          1,
+				Skipped: []project.SkippedRepo{
+					{Dir: "old/thing", Reason: "the directory doesn't match the remote zkhvan/renamed"},
+					{Dir: "scratch", Reason: "no origin remote"},
+				},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			td := setupTestDir(t)
+			cfg := setupConfig(t, td, "")
+
+			dirs := make([]string, 0, len(test.repos))
+			for dir := range test.repos {
+				assert.NoError(t, os.MkdirAll(filepath.Join(td.projects, dir, ".git"), 0o700))
+				dirs = append(dirs, dir)
+			}
+			slices.Sort(dirs)
+
+			fakeexec := &testingexec.FakeExec{}
+			for _, dir := range dirs {
+				remote := test.repos[dir]
+				fakeexec.CommandScript = append(fakeexec.CommandScript, func(_ string, _ ...string) exec.Cmd {
+					path := filepath.Join(td.projects, dir)
+					fakeCmd := testingexec.NewFakeCmd("git", "-C", path, "remote", "get-url", "origin")
+					fakeCmd.OutputScripts = []testingexec.FakeAction{
+						func() ([]byte, []byte, error) {
+							if remote == "" {
+								return nil, nil, errors.New("exit status 2")
+							}
+							return []byte(remote + "\n"), nil, nil
+						},
+					}
+					return fakeCmd
+				})
+			}
+
+			service, err := project.NewService(cfg, project.WithExecutor(fakeexec))
+			assert.NoError(t, err)
+
+			got, err := service.InferLayout(context.Background(), td.projects)
+			assert.NoError(t, err)
+
+			test.want.Root = td.projects
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Fatalf("layout mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -9,19 +9,25 @@ import (
 )
 
 type ListOptions struct {
-	Local  bool
-	Remote bool
+	Local  bool `json:"local"`
+	Remote bool `json:"remote"`
 }
 
 // ListProjects will search for repositories using the given config and options.
 //
 // By default, it will only search for local repositories. To search for remote
 // repositories, set opts.Remote to true.
+//
+// The projects are listed by the daemon when it's running, see WithDaemon.
 func (s *Service) ListProjects(ctx context.Context, opts *ListOptions) ([]Project, error) {
 	if opts == nil {
 		opts = &ListOptions{}
 	}
 
+	if projects, ok := s.listFromDaemon(ctx, opts); ok {
+		return projects, nil
+	}
+
 	remoteProjects, err := s.listRemoteProjects(ctx, opts)
 	if err != nil {
 		return nil, fmt.Errorf("error listing remote projects: %w", err)
@@ -32,35 +38,24 @@ func (s *Service) ListProjects(ctx context.Context, opts *ListOptions) ([]Projec
 		return nil, fmt.Errorf("error listing local projects: %w", err)
 	}
 
-	projects, err := combineProjects(remoteProjects, localProjects)
-	if err != nil {
-		return nil, fmt.Errorf("error combining projects: %w", err)
-	}
-
-	return projects, nil
+	return s.combineProjects(remoteProjects, localProjects), nil
 }
 
-func combineProjects(remote, local []Project) ([]Project, error) {
+// combineProjects merges the remote and local projects found at the same
+// path. A project which can't be merged, e.g. the same owner/repo on two hosts
+// mapped to the same path, is left out with a warning instead of failing the
+// whole listing.
+func (s *Service) combineProjects(remote, local []Project) []Project {
 	projects := make(map[string]Project, 0)
 
-	var err error
-	for _, p := range remote {
-		if _, ok := projects[p.AbsolutePath]; ok {
-			p, err = combineProject(projects[p.AbsolutePath], p)
-			if err != nil {
-				return nil, fmt.Errorf("error combining projects: %w", err)
-			}
-		}
-
-		projects[p.AbsolutePath] = p
-	}
-
-	for _, p := range local {
-		if _, ok := projects[p.AbsolutePath]; ok {
-			p, err = combineProject(projects[p.AbsolutePath], p)
+	for _, p := range slices.Concat(remote, local) {
+		if existing, ok := projects[p.AbsolutePath]; ok {
+			combined, err := combineProject(existing, p)
 			if err != nil {
-				return nil, fmt.Errorf("error combining projects: %w", err)
+				s.warnf("skipping %s: %v", p.AbsolutePath, err)
+				continue
 			}
+			p = combined
 		}
 
 		projects[p.AbsolutePath] = p
@@ -70,7 +65,7 @@ func combineProjects(remote, local []Project) ([]Project, error) {
 	slices.SortFunc(result, func(a, b Project) int {
 		return a.Compare(b)
 	})
-	return result, nil
+	return result
 }
 
 func combineProject(a, b Project) (Project, error) {
@@ -78,10 +73,6 @@ func combineProject(a, b Project) (Project, error) {
 		return Project{}, fmt.Errorf("local id mismatch: %s != %s", a.LocalID, b.LocalID)
 	}
 
-	if a.RemoteID != b.RemoteID {
-		return Project{}, fmt.Errorf("remote id mismatch: %s != %s", a.RemoteID, b.RemoteID)
-	}
-
 	if a.AbsolutePath != b.AbsolutePath {
 		return Project{}, fmt.Errorf("absolute path mismatch: %s != %s", a.AbsolutePath, b.AbsolutePath)
 	}
@@ -91,15 +82,30 @@ func combineProject(a, b Project) (Project, error) {
 	}
 
 	if a.Source == b.Source {
+		if a.RemoteID != b.RemoteID || a.Host != b.Host {
+			return Project{}, fmt.Errorf(
+				"%s/%s and %s/%s are mapped to the same path, give one of their patterns an alternate path",
+				a.HostName(), a.RemoteID, b.HostName(), b.RemoteID,
+			)
+		}
 		return a, nil
 	}
 
+	// The remote ID of a local project is guessed from its path, while the
+	// remote ID of a remote project comes from its provider, e.g. with the
+	// full path of nested groups. The remote one is authoritative.
+	remote := a
+	if b.Source == SourceTypeRemote {
+		remote = b
+	}
+
 	p := newProject(
 		a.LocalID,
-		a.RemoteID,
+		remote.RemoteID,
 		a.AbsolutePath,
 	)
 
+	p.Host = remote.Host
 	p.Source = SourceTypeSynced
 
 	return p, nil
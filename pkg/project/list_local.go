@@ -2,10 +2,11 @@ package project
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"path/filepath"
 
-	"github.com/zkhvan/z/pkg/fd"
+	"github.com/zkhvan/z/pkg/fcache"
 )
 
 func (s *Service) listLocalProjects(ctx context.Context, opts *ListOptions) ([]Project, error) {
@@ -13,55 +14,54 @@ func (s *Service) listLocalProjects(ctx context.Context, opts *ListOptions) ([]P
 		return nil, nil
 	}
 
-	// Local projects aren't cached, so we always load from the filesystem.
 	return s.loadLocalProjects(ctx)
 }
 
+// loadLocalProjects finds the local projects, the directories containing a
+// ".git" entry, up to the maximum depth. The directories are indexed in the
+// cache, so only the ones which changed are read again, unless rescanning.
 func (s *Service) loadLocalProjects(ctx context.Context) ([]Project, error) {
-	var (
-		glob   = true
-		hidden = true
-		// maxDepth should be increased by 1, so that it finds the `.git`
-		// directory inside a project directory.
-		maxDepth    = s.cfg.MaxDepth + 1
-		noIgnoreVCS = true
-		follow      = true
-		root        = s.cfg.Root
-	)
+	var previous localIndex
+	if s.cacheDir != "" && !s.rescan {
+		index, err := fcache.Load[localIndex](s.cacheDir, localIndexKey)
+		if err != nil && !errors.Is(err, fcache.ErrNotFound) {
+			return nil, fmt.Errorf("error loading the local index: %w", err)
+		}
+		previous = index
+	}
+
+	_, projects, err := s.indexLocalProjects(ctx, previous)
+	return projects, err
+}
 
-	rr, err := fd.Run(
-		ctx,
-		".git",
-		&fd.Options{
-			Glob:        &glob,
-			Hidden:      &hidden,
-			MaxDepth:    &maxDepth,
-			NoIgnoreVCS: &noIgnoreVCS,
-			Path:        &root,
-			Follow:      &follow,
-		},
-	)
+// indexLocalProjects updates the previous index of the projects root and
+// saves it to the cache if it changed. It returns the updated index, with the
+// local projects.
+func (s *Service) indexLocalProjects(ctx context.Context, previous localIndex) (localIndex, []Project, error) {
+	root := s.cfg.Root
+
+	x := newIndexer(root, s.cfg.MaxDepth, previous)
+	dirs, err := x.walk(ctx)
 	if err != nil {
-		return nil, err
+		return previous, nil, fmt.Errorf("error walking %q: %w", root, err)
 	}
 
-	var projects []Project
-	for _, r := range rr {
-		abs := filepath.Dir(filepath.Clean(r))
-
-		id, err := filepath.Rel(root, abs)
-		if err != nil {
-			return nil, fmt.Errorf("error convert absolute path to relative path %q: %w", r, err)
+	if s.cacheDir != "" && x.changed {
+		if err := fcache.Save(s.cacheDir, localIndexKey, x.index); err != nil {
+			return previous, nil, fmt.Errorf("error saving the local index: %w", err)
 		}
+	}
 
+	projects := make([]Project, 0, len(dirs))
+	for _, id := range dirs {
 		project := newProject(
 			id,
 			s.toRemoteID(id),
-			abs,
+			filepath.Join(root, id),
 		)
 		project.Source = SourceTypeLocal
 		projects = append(projects, project)
 	}
 
-	return projects, nil
+	return x.index, projects, nil
 }
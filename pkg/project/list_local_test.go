@@ -0,0 +1,76 @@
+package project_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/MakeNowJust/heredoc/v2"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/zkhvan/z/pkg/assert"
+	"github.com/zkhvan/z/pkg/project"
+)
+
+func TestList_LocalIndex(t *testing.T) {
+	td := setupTestDir(t)
+	cfg := setupConfig(t, td, heredoc.Doc(`
+		projects:
+		  root: $PROJECTSDIR
+		  max_depth: 2
+	`))
+
+	mkRepo := func(dir string) {
+		t.Helper()
+		assert.NoError(t, os.MkdirAll(filepath.Join(td.projects, dir, ".git"), 0o700))
+	}
+
+	list := func(opts ...project.ServiceOption) []string {
+		t.Helper()
+
+		service, err := project.NewService(cfg, append(opts, project.WithCacheDir(td.cache))...)
+		assert.NoError(t, err)
+
+		projects, err := service.ListProjects(context.Background(), &project.ListOptions{Local: true})
+		assert.NoError(t, err)
+
+		var ids []string
+		for _, p := range projects {
+			ids = append(ids, filepath.ToSlash(p.LocalID))
+		}
+		return ids
+	}
+
+	expectIDs := func(expected, got []string) {
+		t.Helper()
+		if diff := cmp.Diff(expected, got); diff != "" {
+			t.Fatalf("projects mismatch (-want +got):\n%s", diff)
+		}
+	}
+
+	mkRepo("owner/a")
+	mkRepo("owner/b")
+	mkRepo("owner/a/nested/too-deep")
+	expectIDs([]string{"owner/a", "owner/b"}, list())
+
+	// Added and removed projects are found through the changed directories.
+	mkRepo("other/c")
+	assert.NoError(t, os.RemoveAll(filepath.Join(td.projects, "owner", "b")))
+	expectIDs([]string{"other/c", "owner/a"}, list())
+
+	// A directory with an unchanged modification time isn't read again, so a
+	// project hidden that way is only found by rescanning.
+	dir := filepath.Join(td.projects, "owner", "d")
+	assert.NoError(t, os.MkdirAll(dir, 0o700))
+	expectIDs([]string{"other/c", "owner/a"}, list())
+
+	info, err := os.Stat(dir)
+	assert.NoError(t, err)
+	mkRepo("owner/d")
+	assert.NoError(t, os.Chtimes(dir, time.Time{}, info.ModTime()))
+
+	expectIDs([]string{"other/c", "owner/a"}, list())
+	expectIDs([]string{"other/c", "owner/a", "owner/d"}, list(project.WithRescan(true)))
+}
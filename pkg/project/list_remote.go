@@ -2,102 +2,401 @@ package project
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"maps"
+	"os"
 	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/zkhvan/z/pkg/fcache"
-	"github.com/zkhvan/z/pkg/gh"
 )
 
-func (s *Service) listRemoteProjects(ctx context.Context, opts *ListOptions) ([]Project, error) {
-	var err error
-	var projects []Project
+// remoteCacheNamespace is the cache namespace of the patterns' repositories.
+const remoteCacheNamespace = "projects.remote"
+
+// cacheKey returns the key of the repositories listed by the pattern, in the
+// remote cache namespace.
+// It's a hash of what the pattern lists, so editing a pattern only reloads
+// that pattern, while changing its alternate path doesn't reload anything.
+func (p remotePattern) cacheKey() string {
+	sum := sha256.Sum256([]byte(strings.Join(
+		[]string{p.Host, p.Owner, p.Repo, p.Source.Kind, p.Source.Arg},
+		"\x00",
+	)))
+
+	return hex.EncodeToString(sum[:8])
+}
 
+// remoteCache returns the cache of the repositories of each pattern.
+func (s *Service) remoteCache() *fcache.Namespace[[]Repo] {
+	return fcache.NewNamespace[[]Repo](s.cacheDir, remoteCacheNamespace)
+}
+
+func (s *Service) listRemoteProjects(ctx context.Context, opts *ListOptions) ([]Project, error) {
 	if !opts.Remote {
 		return nil, nil
 	}
 
-	if !s.refreshCache {
-		projects, err = fcache.LoadMany[Project](s.cacheDir, "projects.remote")
-		if errors.Is(err, fcache.ErrNotFound) {
-			projects, err = s.loadRemoteProjects(ctx)
-			if err != nil {
-				return nil, fmt.Errorf("error loading remote projects: %w", err)
+	if s.offline {
+		return s.lastGoodRemoteProjects()
+	}
+
+	if s.refreshCache {
+		return s.lockedRefresh(ctx, s.cfg.remotePatterns)
+	}
+
+	var (
+		projects = make([]Project, 0)
+		missing  []remotePattern
+		stale    bool
+		status   *RefreshStatus
+	)
+
+	for _, pattern := range s.cfg.remotePatterns {
+		repos, err := s.remoteCache().Load(pattern.cacheKey())
+		if err == nil {
+			projects = append(projects, s.toRemoteProjects(pattern, repos, false)...)
+			continue
+		}
+		if !errors.Is(err, fcache.ErrNotFound) {
+			return nil, fmt.Errorf("error loading cached remote projects: %w", err)
+		}
+
+		// Serve the expired repositories while they're refreshed in the
+		// background.
+		if s.backgroundRefresh != nil {
+			if repos, _, err := s.remoteCache().LoadLatest(pattern.cacheKey()); err == nil {
+				projects = append(projects, s.toRemoteProjects(pattern, repos, true)...)
+				stale = true
+				continue
 			}
+		}
 
-			ttl := time.Now().Add(time.Duration(s.cfg.TTL) * time.Second)
-			if err = fcache.SaveMany(s.cacheDir, "projects.remote", projects, ttl); err != nil {
-				return nil, fmt.Errorf("error saving remote projects to cache: %w", err)
+		// A pattern which just failed isn't retried until the retry TTL
+		// passed.
+		if status == nil {
+			status = s.lastRefreshStatus()
+		}
+		if failure, ok := status.Errors[pattern.original]; ok && time.Since(failure.FailedAt) < retryTTL {
+			repos, stale, err := s.lastGoodRepos(pattern)
+			if err != nil {
+				s.warnf("error loading %q: %s", pattern.original, failure.Error)
+				continue
 			}
 
-			return projects, nil
+			s.warnf("error loading %q, using the cached projects: %s", pattern.original, failure.Error)
+			projects = append(projects, s.toRemoteProjects(pattern, repos, stale)...)
+			continue
+		}
+
+		missing = append(missing, pattern)
+	}
+
+	if len(missing) > 0 {
+		loaded, err := s.lockedRefresh(ctx, missing)
+		if err != nil {
+			return nil, err
+		}
+		projects = append(projects, loaded...)
+	}
+
+	if stale {
+		if err := s.backgroundRefresh(); err != nil {
+			s.warnf("error starting the background refresh: %v", err)
+		}
+	}
+
+	return projects, nil
+}
+
+// lastGoodRemoteProjects returns the remote projects of the last cache of
+// each pattern, even if expired.
+func (s *Service) lastGoodRemoteProjects() ([]Project, error) {
+	projects := make([]Project, 0)
+
+	for _, pattern := range s.cfg.remotePatterns {
+		repos, stale, err := s.lastGoodRepos(pattern)
+		if errors.Is(err, fcache.ErrNotFound) {
+			s.warnf("no cached projects for %q while offline", pattern.original)
+			continue
 		}
 		if err != nil {
 			return nil, fmt.Errorf("error loading cached remote projects: %w", err)
 		}
 
-		return projects, nil
+		projects = append(projects, s.toRemoteProjects(pattern, repos, stale)...)
 	}
 
-	projects, err = s.loadRemoteProjects(ctx)
+	return projects, nil
+}
+
+// lastGoodRepos returns the repositories of the last cache of the pattern,
+// even if expired, in which case stale is true.
+func (s *Service) lastGoodRepos(pattern remotePattern) ([]Repo, bool, error) {
+	repos, expiry, err := s.remoteCache().LoadLatest(pattern.cacheKey())
 	if err != nil {
-		return nil, fmt.Errorf("error loading remote projects: %w", err)
+		return nil, false, err
+	}
+
+	return repos, expiry.Before(time.Now()), nil
+}
+
+// lockedRefresh refreshes the patterns once the running refresh, if any, is
+// done, so concurrent refreshes don't write the cache at the same time.
+func (s *Service) lockedRefresh(ctx context.Context, patterns []remotePattern) ([]Project, error) {
+	unlock, err := fcache.Lock(s.cacheDir, refreshLockKey)
+	if err != nil {
+		return nil, fmt.Errorf("error locking the refresh: %w", err)
+	}
+	defer unlock()
+
+	return s.refreshRemoteProjects(ctx, patterns)
+}
+
+// patternResult is the outcome of loading the repositories of a pattern.
+type patternResult struct {
+	pattern remotePattern
+	repos   []Repo
+	err     error
+}
+
+// refreshRemoteProjects loads the repositories of the patterns concurrently
+// and saves each to the cache, along with the status of the refresh. The
+// patterns that failed to load keep their last cached repositories, with a
+// warning.
+func (s *Service) refreshRemoteProjects(ctx context.Context, patterns []remotePattern) ([]Project, error) {
+	status := s.lastRefreshStatus()
+	status.StartedAt = time.Now()
+
+	results := s.loadPatterns(ctx, patterns)
+
+	var failed []patternResult
+	for _, result := range results {
+		if result.err != nil {
+			failed = append(failed, result)
+			continue
+		}
+
+		expiry := time.Now().Add(s.patternTTL(result.pattern))
+		if err := s.remoteCache().Save(result.pattern.cacheKey(), result.repos, expiry); err != nil {
+			return nil, fmt.Errorf("error saving remote projects to cache: %w", err)
+		}
+		delete(status.Errors, result.pattern.original)
+	}
+
+	// If every pattern failed, e.g. because the network is down, a single
+	// warning is given.
+	allFailed := len(failed) > 1 && len(failed) == len(results)
+	if allFailed {
+		s.warnf("error loading the remote projects, using the cached ones: %v", failed[0].err)
 	}
 
-	ttl := time.Now().Add(time.Duration(s.cfg.TTL) * time.Second)
-	if err := fcache.SaveMany(s.cacheDir, "projects.remote", projects, ttl); err != nil {
-		return nil, fmt.Errorf("error saving remote projects to cache: %w", err)
+	projects := make([]Project, 0)
+	for _, result := range results {
+		if result.err == nil {
+			projects = append(projects, s.toRemoteProjects(result.pattern, result.repos, false)...)
+			continue
+		}
+
+		status.Errors[result.pattern.original] = PatternError{Error: result.err.Error(), FailedAt: time.Now()}
+
+		repos, stale, err := s.lastGoodRepos(result.pattern)
+		switch {
+		case err != nil && !allFailed:
+			s.warnf("error loading %q: %v", result.pattern.original, result.err)
+		case err == nil && !allFailed:
+			s.warnf("error loading %q, using the cached projects: %v", result.pattern.original, result.err)
+		}
+
+		if err == nil {
+			projects = append(projects, s.toRemoteProjects(result.pattern, repos, stale)...)
+		}
+	}
+
+	// The errors of the patterns removed from the config are dropped.
+	maps.DeleteFunc(status.Errors, func(pattern string, _ PatternError) bool {
+		return !slices.ContainsFunc(s.cfg.remotePatterns, func(p remotePattern) bool { return p.original == pattern })
+	})
+
+	status.FinishedAt = time.Now()
+	if err := fcache.Save(s.cacheDir, refreshStatusKey, status); err != nil {
+		return nil, fmt.Errorf("error saving the refresh status: %w", err)
 	}
 
+	s.removeLegacyRemoteCache()
+
 	return projects, nil
 }
 
-func (s *Service) loadRemoteProjects(ctx context.Context) ([]Project, error) {
+// lastRefreshStatus returns the status of the last refresh, or an empty one
+// if it can't be loaded.
+func (s *Service) lastRefreshStatus() *RefreshStatus {
+	status, err := fcache.Load[RefreshStatus](s.cacheDir, refreshStatusKey)
+	if err != nil {
+		status = RefreshStatus{}
+	}
+	if status.Errors == nil {
+		status.Errors = make(map[string]PatternError)
+	}
+
+	return &status
+}
+
+// removeLegacyRemoteCache removes the remote projects cached by previous
+// versions under a single key, once the patterns are cached separately.
+func (s *Service) removeLegacyRemoteCache() {
+	files, err := filepath.Glob(filepath.Join(s.cacheDir, remoteCacheNamespace+"-*.json"))
+	if err != nil {
+		return
+	}
+
+	for _, file := range files {
+		_ = os.Remove(file)
+	}
+}
+
+// patternTTL returns the time to live of the cached repositories of the
+// pattern, which can be set per provider.
+func (s *Service) patternTTL(pattern remotePattern) time.Duration {
+	ttl := s.cfg.TTL
+
+	host := pattern.Host
+	if host == "" {
+		host = DefaultHost
+	}
+	for _, pc := range s.cfg.Providers {
+		if normalizeHost(pc.Host) == host && pc.TTL > 0 {
+			ttl = pc.TTL
+		}
+	}
+
+	return time.Duration(ttl) * time.Second
+}
+
+// loadPatterns loads the repositories of the patterns concurrently.
+func (s *Service) loadPatterns(ctx context.Context, patterns []remotePattern) []patternResult {
 	var (
-		projects = make([]Project, 0)
-		root     = s.cfg.Root
+		results = make([]patternResult, len(patterns))
+		sem     = make(chan struct{}, max(s.cfg.Concurrency, 1))
+		wg      sync.WaitGroup
 	)
 
-	for _, pattern := range s.cfg.remotePatterns {
-		repos, err := s.loadRemoteRepos(ctx, pattern)
-		if err != nil {
-			return nil, fmt.Errorf("error loading remote repos: %w", err)
-		}
+	for i, pattern := range patterns {
+		// Acquiring before starting the goroutine loads the patterns in order
+		// when the concurrency is 1.
+		sem <- struct{}{}
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			repos, err := s.loadRemoteRepos(ctx, pattern)
+			results[i] = patternResult{pattern: pattern, repos: repos, err: err}
+		}()
+	}
 
-		for _, r := range repos {
-			localID := s.toLocalID(r.String())
+	wg.Wait()
 
-			project := newProject(
-				localID,
-				r.String(),
-				filepath.Join(root, localID),
-			)
-			project.Source = SourceTypeRemote
+	return results
+}
 
-			projects = append(projects, project)
+// toRemoteProjects returns the projects of the repositories listed by the
+// pattern. The repositories of a source pattern are mapped by it, unless an
+// owner pattern matches them.
+func (s *Service) toRemoteProjects(pattern remotePattern, repos []Repo, stale bool) []Project {
+	projects := make([]Project, 0, len(repos))
+	for _, r := range repos {
+		localID, matched := s.ownerLocalID(r.Host, r.ID())
+		if pattern.Source.Kind != "" && !matched {
+			localID = pattern.mapLocalID(r.ID())
 		}
+
+		project := newProject(
+			localID,
+			r.ID(),
+			filepath.Join(s.cfg.Root, localID),
+		)
+		project.Host = projectHost(r.Host)
+		project.Source = SourceTypeRemote
+		project.Stale = stale
+
+		projects = append(projects, project)
 	}
 
-	return projects, nil
+	return projects
 }
 
-func (s *Service) loadRemoteRepos(ctx context.Context, pattern remotePattern) ([]*gh.Repo, error) {
+func (s *Service) loadRemoteRepos(ctx context.Context, pattern remotePattern) ([]Repo, error) {
+	if pattern.Source.Kind != "" {
+		return s.loadSourceRepos(ctx, pattern)
+	}
+
 	if pattern.Repo != "*" {
 		// If the repo is specified, return a single repo.
-		return []*gh.Repo{
+		return []Repo{
 			{
+				Host:  pattern.Host,
 				Owner: pattern.Owner,
 				Name:  pattern.Repo,
 			},
 		}, nil
 	}
 
-	repos, err := s.gh.ListRepos(ctx, &gh.RepoListOptions{Owner: pattern.Owner})
+	provider, err := s.provider(pattern.Host)
+	if err != nil {
+		return nil, err
+	}
+
+	repos, err := provider.ListRepos(ctx, pattern.Owner)
 	if err != nil {
 		return nil, err
 	}
 
 	return repos, nil
 }
+
+// sourceLists reports whether the last cached repositories of the source
+// pattern include the repository.
+func (s *Service) sourceLists(pattern remotePattern, host, remoteID string) bool {
+	_, ok := s.cachedRepo(pattern, host, remoteID)
+	return ok
+}
+
+// cachedRepo returns the repository from the last cached repositories of
+// the pattern, even if expired.
+func (s *Service) cachedRepo(pattern remotePattern, host, remoteID string) (Repo, bool) {
+	repos, _, err := s.remoteCache().LoadLatest(pattern.cacheKey())
+	if err != nil {
+		return Repo{}, false
+	}
+
+	i := slices.IndexFunc(repos, func(r Repo) bool {
+		return projectHost(r.Host) == projectHost(host) && r.ID() == remoteID
+	})
+	if i < 0 {
+		return Repo{}, false
+	}
+
+	return repos[i], true
+}
+
+func (s *Service) loadSourceRepos(ctx context.Context, pattern remotePattern) ([]Repo, error) {
+	provider, err := s.provider(pattern.Host)
+	if err != nil {
+		return nil, err
+	}
+
+	lister, ok := provider.(SourceLister)
+	if !ok {
+		return nil, fmt.Errorf("the provider of %q doesn't support %q patterns", provider.Host(), pattern.Source)
+	}
+
+	return lister.ListSource(ctx, pattern.Source)
+}
@@ -1,11 +1,16 @@
 package project_test
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync/atomic"
 	"testing"
 
 	"github.com/MakeNowJust/heredoc/v2"
@@ -183,9 +188,15 @@ func TestList(t *testing.T) {
 
 			// Setup remote projects
 			fakeexec := &testingexec.FakeExec{}
+			if len(test.remote) > 0 {
+				fakeexec.CommandScript = append(fakeexec.CommandScript, fakeGHAuthToken(t, "github.com"))
+			}
 			for owner, ownerRepos := range test.remote {
 				fakeexec.CommandScript = append(fakeexec.CommandScript, func(_ string, _ ...string) exec.Cmd {
-					fakeCmd := testingexec.NewFakeCmd("gh", "repo", "list", "--limit", "9999", owner, "--json", "owner,name")
+					fakeCmd := testingexec.NewFakeCmd(
+						"gh", "repo", "list", "--limit", "9999", owner,
+						"--json", "owner,name,description,primaryLanguage,stargazerCount,pushedAt,url",
+					)
 					fakeCmd.OutputScripts = []testingexec.FakeAction{
 						func() ([]byte, []byte, error) {
 							var responses []string
@@ -222,6 +233,26 @@ func TestList(t *testing.T) {
 	}
 }
 
+// fakeGHAuthToken fakes "gh auth token" without a login for the host, so
+// the gh CLI is used instead of the API.
+func fakeGHAuthToken(t *testing.T, host string) testingexec.FakeCommandAction {
+	t.Helper()
+
+	for _, env := range []string{"GH_TOKEN", "GITHUB_TOKEN", "GH_ENTERPRISE_TOKEN", "GITHUB_ENTERPRISE_TOKEN"} {
+		t.Setenv(env, "")
+	}
+
+	return func(_ string, _ ...string) exec.Cmd {
+		fakeCmd := testingexec.NewFakeCmd("gh", "auth", "token", "--hostname", host)
+		fakeCmd.OutputScripts = []testingexec.FakeAction{
+			func() ([]byte, []byte, error) {
+				return nil, nil, errors.New("not logged in")
+			},
+		}
+		return fakeCmd
+	}
+}
+
 // testDir represents the test directory structure:
 //
 //	$TESTDIR
@@ -268,9 +299,7 @@ func setupTestDir(t *testing.T) testDir {
 func setupConfig(t *testing.T, td testDir, rawCfg string) cmdutil.Config {
 	cfgPath := filepath.Join(td.config, "config.yaml")
 
-	rawCfg = strings.ReplaceAll(rawCfg, "$PROJECTSDIR", td.projects)
-	rawCfg = strings.ReplaceAll(rawCfg, "$CONFIGDIR", td.config)
-	rawCfg = strings.ReplaceAll(rawCfg, "$CACHEDIR", td.cache)
+	rawCfg = replaceTestDirs(rawCfg, td)
 
 	if len(rawCfg) > 0 {
 		err := os.WriteFile(cfgPath, []byte(rawCfg), 0o600)
@@ -282,3 +311,140 @@ func setupConfig(t *testing.T, td testDir, rawCfg string) cmdutil.Config {
 
 	return cfg
 }
+
+func replaceTestDirs(s string, td testDir) string {
+	s = strings.ReplaceAll(s, "$PROJECTSDIR", td.projects)
+	s = strings.ReplaceAll(s, "$CONFIGDIR", td.config)
+	s = strings.ReplaceAll(s, "$CACHEDIR", td.cache)
+	return s
+}
+
+func TestList_FailedPatterns(t *testing.T) {
+	var lostAccess atomic.Bool
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /orgs/{org}/repos", func(w http.ResponseWriter, r *http.Request) {
+		org := r.PathValue("org")
+		if org == "gone" || (org == "lost" && lostAccess.Load()) {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		fmt.Fprintf(w, `[{"name":"repo","owner":{"login":%q}}]`, org)
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	td := setupTestDir(t)
+	cfg := setupConfig(t, td, heredoc.Docf(`
+		projects:
+		  root: $PROJECTSDIR
+		  remote_patterns:
+		    - lost/*
+		    - gone/*
+		    - kept/*
+		  providers:
+		    - host: github.com
+		      type: github
+		      api_url: %s
+		      token: secret
+	`, server.URL))
+
+	list := func() ([]string, string) {
+		t.Helper()
+
+		var warnings bytes.Buffer
+		service, err := project.NewService(
+			cfg,
+			project.WithCacheDir(td.cache),
+			project.WithRefreshCache(true),
+			project.WithWarnings(&warnings),
+		)
+		assert.NoError(t, err)
+
+		projects, err := service.ListProjects(context.Background(), &project.ListOptions{Remote: true})
+		assert.NoError(t, err)
+
+		var ids []string
+		for _, p := range projects {
+			ids = append(ids, p.RemoteID)
+		}
+
+		return ids, warnings.String()
+	}
+
+	ids, warnings := list()
+	if diff := cmp.Diff([]string{"kept/repo", "lost/repo"}, ids); diff != "" {
+		t.Fatalf("projects mismatch (-want +got):\n%s", diff)
+	}
+	if !strings.HasPrefix(warnings, `warning: error loading "gone/*": `) || strings.Count(warnings, "\n") != 1 {
+		t.Fatalf("expected a warning for gone/*, got %q", warnings)
+	}
+
+	// The last good projects of a failed pattern are kept.
+	lostAccess.Store(true)
+
+	ids, warnings = list()
+	if diff := cmp.Diff([]string{"kept/repo", "lost/repo"}, ids); diff != "" {
+		t.Fatalf("projects mismatch (-want +got):\n%s", diff)
+	}
+	if !strings.Contains(warnings, `warning: error loading "lost/*", using the cached projects: `) {
+		t.Fatalf("expected a warning for lost/*, got %q", warnings)
+	}
+}
+
+func TestList_Offline(t *testing.T) {
+	var requests atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests.Add(1)
+		fmt.Fprint(w, `[{"name":"repo","owner":{"login":"owner"}}]`)
+	}))
+	t.Cleanup(server.Close)
+
+	td := setupTestDir(t)
+	cfg := setupConfig(t, td, heredoc.Docf(`
+		projects:
+		  root: $PROJECTSDIR
+		  remote_patterns:
+		    - owner/*
+		  providers:
+		    - host: github.com
+		      type: github
+		      api_url: %s
+		      token: secret
+	`, server.URL))
+
+	service, err := project.NewService(cfg, project.WithCacheDir(td.cache))
+	assert.NoError(t, err)
+
+	_, err = service.ListProjects(context.Background(), &project.ListOptions{Remote: true})
+	assert.NoError(t, err)
+
+	expireCache(t, td)
+
+	service, err = project.NewService(cfg, project.WithCacheDir(td.cache), project.WithOffline(true))
+	assert.NoError(t, err)
+
+	projects, err := service.ListProjects(context.Background(), &project.ListOptions{Remote: true})
+	assert.NoError(t, err)
+
+	expected := []project.Project{
+		{
+			LocalID:      filepath.Join("owner", "repo"),
+			RemoteID:     "owner/repo",
+			AbsolutePath: filepath.Join(td.projects, "owner", "repo"),
+			Source:       project.SourceTypeRemote,
+			Stale:        true,
+		},
+	}
+
+	if diff := cmp.Diff(expected, projects); diff != "" {
+		t.Fatalf("projects mismatch (-want +got):\n%s", diff)
+	}
+
+	if n := requests.Load(); n != 1 {
+		t.Fatalf("expected no requests while offline, got %d", n-1)
+	}
+}
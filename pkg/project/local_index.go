@@ -0,0 +1,163 @@
+package project
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// localIndexKey is the cache key of the local index.
+const localIndexKey = "projects.local-index"
+
+// localIndex is the result of walking the projects root, kept between runs.
+//
+// The modification time of a directory changes when entries are added to,
+// removed from or renamed in it, including its ".git" entry. So an indexed
+// directory with an unchanged modification time has the same subdirectories
+// and is still a project or not: it's verified with a stat instead of being
+// read again.
+type localIndex struct {
+	Root string `json:"root"`
+	// Dirs maps the directories, relative to the root, to what they contain.
+	Dirs map[string]indexedDir `json:"dirs"`
+}
+
+type indexedDir struct {
+	// ModTime is the modification time of the directory, in nanoseconds.
+	ModTime int64 `json:"mod_time"`
+	// Repo is set if the directory contains a ".git" entry.
+	Repo    bool     `json:"repo,omitempty"`
+	Subdirs []string `json:"subdirs,omitempty"`
+}
+
+// indexer walks the projects root, only reading the directories which
+// changed since the previous index.
+type indexer struct {
+	root     string
+	maxDepth int
+	previous map[string]indexedDir
+
+	index localIndex
+	// changed is set if any directory was read, or removed since the
+	// previous index.
+	changed bool
+}
+
+func newIndexer(root string, maxDepth int, previous localIndex) *indexer {
+	if previous.Root != root {
+		previous.Dirs = nil
+	}
+
+	return &indexer{
+		root:     root,
+		maxDepth: maxDepth,
+		previous: previous.Dirs,
+		index: localIndex{
+			Root: root,
+			Dirs: make(map[string]indexedDir),
+		},
+	}
+}
+
+// walk indexes the root and returns the directories of the projects, relative
+// to the root, sorted.
+func (x *indexer) walk(ctx context.Context) ([]string, error) {
+	if err := x.walkDir(ctx, ".", 0); err != nil {
+		return nil, err
+	}
+
+	// A directory of the previous index which wasn't walked was removed, or is
+	// now deeper than the maximum depth.
+	for dir := range x.previous {
+		if _, ok := x.index.Dirs[dir]; !ok {
+			x.changed = true
+			break
+		}
+	}
+
+	var repos []string
+	for dir, d := range x.index.Dirs {
+		// The root isn't a project of itself.
+		if d.Repo && dir != "." {
+			repos = append(repos, dir)
+		}
+	}
+	slices.Sort(repos)
+
+	return repos, nil
+}
+
+func (x *indexer) walkDir(ctx context.Context, dir string, depth int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	abs := filepath.Join(x.root, dir)
+
+	// Stat follows symlinks, so symlinked directories are walked too. The
+	// maximum depth stops symlink loops.
+	info, err := os.Stat(abs)
+	if errors.Is(err, fs.ErrNotExist) || errors.Is(err, fs.ErrPermission) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	d, ok := x.previous[dir]
+	if !ok || d.ModTime != info.ModTime().UnixNano() {
+		d, err = readIndexedDir(abs, info)
+		if errors.Is(err, fs.ErrPermission) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		x.changed = true
+	}
+	x.index.Dirs[dir] = d
+
+	if depth >= x.maxDepth {
+		return nil
+	}
+
+	for _, name := range d.Subdirs {
+		if err := x.walkDir(ctx, filepath.Join(dir, name), depth+1); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func readIndexedDir(abs string, info fs.FileInfo) (indexedDir, error) {
+	entries, err := os.ReadDir(abs)
+	if err != nil {
+		return indexedDir{}, err
+	}
+
+	d := indexedDir{ModTime: info.ModTime().UnixNano()}
+	for _, e := range entries {
+		if e.Name() == ".git" {
+			// The ".git" entry is a directory, or a file in worktrees and
+			// submodules.
+			d.Repo = true
+			continue
+		}
+
+		isDir := e.IsDir()
+		if e.Type()&fs.ModeSymlink != 0 {
+			target, err := os.Stat(filepath.Join(abs, e.Name()))
+			isDir = err == nil && target.IsDir()
+		}
+
+		if isDir {
+			d.Subdirs = append(d.Subdirs, e.Name())
+		}
+	}
+
+	return d, nil
+}
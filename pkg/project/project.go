@@ -4,10 +4,12 @@ import (
 	"context"
 	"fmt"
 	"path"
-	"path/filepath"
 	"strings"
 )
 
+// DefaultHost is the host of projects that don't specify one.
+const DefaultHost = "github.com"
+
 type SourceType int
 
 const (
@@ -41,22 +43,49 @@ type Project struct {
 	// For now, only GitHub is supported and this is usually the owner/repo.
 	RemoteID string `json:"remote_id"`
 
+	// Host is the host of the remote service. Empty means DefaultHost.
+	Host string `json:"host,omitempty"`
+
 	// AbsolutePath is the absolute path to the project.
 	AbsolutePath string `json:"absolute_path"`
 
 	// Source indicates how the project was discovered.
 	Source SourceType `json:"source_type"`
+
+	// Stale is set for remote projects served from an expired cache, e.g.
+	// while offline.
+	Stale bool `json:"stale,omitempty"`
 }
 
 // URL returns the URL of the project.
 //
 // This is a quick way to determine the URL based on the fact that all there's
-// an assumption that all projects are GitHub repositories.
+// an assumption that all projects are hosted on a GitHub-like service.
 //
 // TODO: Detect the proper URL in a generic way, based on the project type.
 func (p Project) URL() string {
 	owner, repo := p.OwnerRepo()
-	return fmt.Sprintf("https://github.com/%s/%s", owner, repo)
+	return fmt.Sprintf("https://%s/%s/%s", p.HostName(), owner, repo)
+}
+
+// HostName returns the host of the remote service, defaulting to
+// DefaultHost.
+func (p Project) HostName() string {
+	if p.Host == "" {
+		return DefaultHost
+	}
+	return p.Host
+}
+
+// Repo returns the remote repository of the project.
+func (p Project) Repo() Repo {
+	owner, name := p.OwnerRepo()
+
+	return Repo{
+		Host:  p.HostName(),
+		Owner: owner,
+		Name:  name,
+	}
 }
 
 func (p Project) OwnerRepo() (string, string) {
@@ -78,25 +107,10 @@ func newProject(localID, remoteID, abs string) Project {
 	}
 }
 
-func (s *Service) Get(_ context.Context, id string) (Project, error) {
-	var project Project
-
-	parts := strings.Split(id, "/")
-
-	switch n := len(parts); {
-	case n < 2:
-		return project, fmt.Errorf("invalid ID")
-	case n == 2:
-		project.RemoteID = id
-		project.LocalID = s.toLocalID(id)
-	case 2 < n:
-		project.LocalID = id
-		project.RemoteID = s.toRemoteID(id)
-	}
-
-	project.AbsolutePath = filepath.Join(s.cfg.Root, project.LocalID)
-
-	return project, nil
+// Get returns the project identified by id. See Resolve for the accepted
+// formats.
+func (s *Service) Get(ctx context.Context, id string) (Project, error) {
+	return s.Resolve(ctx, id)
 }
 
 func (s *Service) toRemoteID(localID string) string {
@@ -111,31 +125,47 @@ func (s *Service) toRemoteID(localID string) string {
 	return fmt.Sprintf("%s/%s", owner, repo)
 }
 
+// toLocalID returns the local ID of a remote repository. The repositories
+// matched by an owner pattern are mapped by it, otherwise by the first source
+// pattern which listed them, like in the listing.
+func (s *Service) toLocalID(host, remoteID string) string {
+	localID, matched := s.ownerLocalID(host, remoteID)
+	if matched {
+		return localID
+	}
+
+	for _, pattern := range s.cfg.remotePatterns {
+		if pattern.Source.Kind != "" && s.sourceLists(pattern, host, remoteID) {
+			return pattern.mapLocalID(remoteID)
+		}
+	}
+
+	return localID
+}
+
+// ownerLocalID returns the local ID of a remote repository mapped by the owner
+// patterns, and whether any matched it.
+//
 // TODO: iterating over remote patterns isn't the most efficient, might want
 // to make it lookup-based instead.
-func (s *Service) toLocalID(remoteID string) string {
-	parts := strings.Split(remoteID, "/")
-	if len(parts) < 2 {
-		return ""
+func (s *Service) ownerLocalID(host, remoteID string) (string, bool) {
+	if !strings.Contains(remoteID, "/") {
+		return "", false
 	}
 
-	owner := parts[0]
-	repo := parts[1]
+	owner := path.Dir(remoteID)
+	repo := path.Base(remoteID)
 
 	localID := remoteID
+	matched := false
 	for _, pattern := range s.cfg.remotePatterns {
-		if pattern.Owner != owner {
+		if !pattern.matches(host, owner, repo) {
 			continue
 		}
 
-		if pattern.Repo != "*" && pattern.Repo != repo {
-			continue
-		}
-
-		// The alternate path might be empty, but filepath.Join will handle it
-		// gracefully.
-		localID = filepath.Join(pattern.AlternatePath, localID)
+		localID = pattern.mapLocalID(localID)
+		matched = true
 	}
 
-	return localID
+	return localID, matched
 }
@@ -0,0 +1,285 @@
+package project
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/zkhvan/z/pkg/browser"
+	"github.com/zkhvan/z/pkg/oslib"
+)
+
+const (
+	ProviderTypeGitHub  = "github"
+	ProviderTypeGitLab  = "gitlab"
+	ProviderTypeGitea   = "gitea"
+	ProviderTypeForgejo = "forgejo"
+	// ProviderTypeFilesystem serves bare repositories from a directory,
+	// locally or over ssh.
+	ProviderTypeFilesystem = "filesystem"
+)
+
+// Repo is a repository hosted by a Provider.
+type Repo struct {
+	Host string `json:"host,omitempty"`
+
+	// Owner is the user, organization or group owning the repository. For
+	// services with nested groups, it's the full path of the group, e.g.
+	// "group/subgroup".
+	Owner string `json:"owner"`
+
+	Name string `json:"name"`
+
+	// Info describes the repository when it's returned by the listing, so the
+	// details are cached with the repositories and not requested again.
+	Info *RepoInfo `json:"info,omitempty"`
+}
+
+// ID returns the owner/name of the repository.
+func (r Repo) ID() string {
+	return r.Owner + "/" + r.Name
+}
+
+// RepoInfo describes a repository.
+type RepoInfo struct {
+	Description string    `json:"description,omitempty"`
+	Language    string    `json:"language,omitempty"`
+	Stars       int       `json:"stars,omitempty"`
+	PushedAt    time.Time `json:"pushed_at,omitzero"`
+}
+
+// RepoDetails describes a repository, as returned by Provider.ViewRepo.
+type RepoDetails struct {
+	Repo
+	RepoInfo
+}
+
+// Provider discovers remote repositories on a hosting service.
+type Provider interface {
+	// Host returns the host served by the provider, e.g. "github.com".
+	Host() string
+
+	// ListRepos lists all the repositories of an owner, with their Info if
+	// the service returns it.
+	ListRepos(ctx context.Context, owner string) ([]Repo, error)
+
+	// ViewRepo returns the details of a repository.
+	ViewRepo(ctx context.Context, repo Repo) (RepoDetails, error)
+
+	// CloneURL returns the URL to clone the repository with.
+	CloneURL(repo Repo) string
+
+	// WebURL returns the URL of the repository's web page.
+	WebURL(repo Repo) string
+}
+
+// Cloner is implemented by providers that clone repositories with their own
+// tooling instead of "git clone".
+type Cloner interface {
+	Clone(ctx context.Context, repo Repo, path string) (string, error)
+}
+
+// WebOpener is implemented by providers that open the web page of a
+// repository with their own tooling instead of the default browser.
+type WebOpener interface {
+	OpenWeb(ctx context.Context, repo Repo) error
+}
+
+// SourceLister is implemented by providers that list the repositories of
+// other sources than an owner, see Source.
+type SourceLister interface {
+	ListSource(ctx context.Context, source Source) ([]Repo, error)
+}
+
+// The kinds of sources of repositories.
+const (
+	// SourceKindViewer lists the repositories of the authenticated user.
+	SourceKindViewer = "@me"
+	// SourceKindStarred lists the repositories starred by a user, the
+	// authenticated one if the argument is empty.
+	SourceKindStarred = "starred"
+	// SourceKindTeam lists the repositories of a team, e.g. "acme/platform".
+	SourceKindTeam = "team"
+	// SourceKindTopic lists the repositories with a topic.
+	SourceKindTopic = "topic"
+	// SourceKindSearch lists the repositories matching a search query.
+	SourceKindSearch = "search"
+)
+
+// Source is a list of repositories, other than all the repositories of an
+// owner.
+type Source struct {
+	Kind string
+	Arg  string
+}
+
+func (s Source) String() string {
+	if s.Kind == SourceKindViewer {
+		return s.Kind + "/*"
+	}
+	return s.Kind + ":" + s.Arg
+}
+
+// ProviderConfig configures the service hosting the repositories of a host.
+type ProviderConfig struct {
+	// Host is the host of the service, e.g. "gitlab.example.com".
+	Host string `json:"host"`
+
+	// Type is the kind of service, "github", "gitlab", "gitea", "forgejo" or
+	// "filesystem".
+	Type string `json:"type" enum:"github,gitlab,gitea,forgejo,filesystem"`
+
+	// Token is used to authenticate against the service's API. Environment
+	// variables are expanded, e.g. "$GITLAB_WORK_TOKEN".
+	Token string `json:"token"`
+
+	// APIURL overrides the URL of the service's API.
+	APIURL string `json:"api_url"`
+
+	// TTL is the time to live (in seconds) of the cached repositories of the
+	// host, defaults to the projects' TTL.
+	TTL int64 `json:"ttl"`
+
+	// URL is the directory containing the bare repositories of a filesystem
+	// provider, as a path, a file:// URL or an ssh://[user@]host/path URL.
+	URL string `json:"url"`
+
+	// CloneProtocol is the protocol of the clone URLs of a Gitea or Forgejo
+	// provider, "https" (the default) or "ssh".
+	CloneProtocol string `json:"clone_protocol" enum:"https,ssh"`
+}
+
+// defaultProviders are always available, unless configured otherwise.
+var defaultProviders = []ProviderConfig{
+	{Host: DefaultHost, Type: ProviderTypeGitHub},
+	{Host: "gitlab.com", Type: ProviderTypeGitLab},
+}
+
+// WithProvider registers a provider for its host, replacing the configured
+// one.
+func WithProvider(provider Provider) ServiceOption {
+	return func(s *Service) {
+		s.providers[normalizeHost(provider.Host())] = provider
+	}
+}
+
+func (s *Service) initProviders() error {
+	configs := append(append([]ProviderConfig{}, s.cfg.Providers...), defaultProviders...)
+
+	for _, pc := range configs {
+		host := normalizeHost(pc.Host)
+		if host == "" {
+			return fmt.Errorf("provider host is required")
+		}
+
+		if _, ok := s.providers[host]; ok {
+			continue
+		}
+
+		provider, err := s.newProvider(pc)
+		if err != nil {
+			return fmt.Errorf("error creating provider for %q: %w", pc.Host, err)
+		}
+		s.providers[host] = provider
+	}
+
+	// Patterns of hosts without a provider are assumed to be GitHub
+	// Enterprise Server instances.
+	for _, pattern := range s.cfg.remotePatterns {
+		host := normalizeHost(pattern.Host)
+		if host == "" {
+			continue
+		}
+
+		if _, ok := s.providers[host]; !ok {
+			s.providers[host] = NewGitHubProvider(s.gh.WithHost(host))
+		}
+	}
+
+	return nil
+}
+
+func (s *Service) newProvider(pc ProviderConfig) (Provider, error) {
+	switch pc.Type {
+	case ProviderTypeGitHub:
+		return newGitHubProviderFromConfig(pc, s.gh), nil
+	case ProviderTypeGitLab:
+		return newGitLabProviderFromConfig(pc, s.executor), nil
+	case ProviderTypeGitea, ProviderTypeForgejo:
+		return newGiteaProviderFromConfig(pc, s.git), nil
+	case ProviderTypeFilesystem:
+		return NewFilesystemProvider(normalizeHost(pc.Host), oslib.Expand(pc.URL), s.executor)
+	default:
+		return nil, fmt.Errorf("unsupported provider type %q", pc.Type)
+	}
+}
+
+// provider returns the provider of the host. An empty host is DefaultHost.
+func (s *Service) provider(host string) (Provider, error) {
+	if host == "" {
+		host = DefaultHost
+	}
+
+	provider, ok := s.providers[normalizeHost(host)]
+	if !ok {
+		return nil, fmt.Errorf("no provider configured for host %q", host)
+	}
+
+	return provider, nil
+}
+
+// OpenInBrowser opens the web page of the project's remote repository.
+func (s *Service) OpenInBrowser(ctx context.Context, project Project) error {
+	provider, err := s.provider(project.Host)
+	if err != nil {
+		return err
+	}
+
+	repo := project.Repo()
+	if opener, ok := provider.(WebOpener); ok {
+		return opener.OpenWeb(ctx, repo)
+	}
+
+	webURL := provider.WebURL(repo)
+	if webURL == "" {
+		return fmt.Errorf("no web page for %s", project.RemoteID)
+	}
+
+	return browser.Open(ctx, webURL)
+}
+
+// clone clones the project with the provider of its host, falling back to
+// "git clone" of the project's URL for unknown hosts.
+func (s *Service) clone(ctx context.Context, project Project) (string, error) {
+	provider, err := s.provider(project.Host)
+	if err != nil {
+		return s.git.Clone(ctx, project.URL(), project.AbsolutePath)
+	}
+
+	repo := project.Repo()
+	if cloner, ok := provider.(Cloner); ok {
+		return cloner.Clone(ctx, repo, project.AbsolutePath)
+	}
+
+	return s.git.Clone(ctx, provider.CloneURL(repo), project.AbsolutePath)
+}
+
+func normalizeHost(host string) string {
+	return strings.ToLower(strings.TrimSpace(host))
+}
+
+// projectHost returns the host as stored in Project.Host, where DefaultHost
+// is left empty.
+func projectHost(host string) string {
+	host = normalizeHost(host)
+	if host == DefaultHost {
+		return ""
+	}
+	return host
+}
+
+func expandToken(token string) string {
+	return os.ExpandEnv(token)
+}
@@ -0,0 +1,214 @@
+package project
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/zkhvan/z/pkg/cmdutil"
+	"github.com/zkhvan/z/pkg/exec"
+)
+
+// defaultGitDescription is the content of the description file of a new
+// repository, which isn't worth showing.
+const defaultGitDescription = "Unnamed repository;"
+
+type filesystemProvider struct {
+	host     string
+	executor exec.Interface
+
+	// root is the directory containing the owners' directories.
+	root string
+	// sshHost is the [user@]host to list the repositories on. If empty, the
+	// repositories are listed on the local filesystem.
+	sshHost string
+	sshPort string
+}
+
+var _ Provider = (*filesystemProvider)(nil)
+
+// NewFilesystemProvider returns a provider for bare repositories served from
+// a directory, laid out as <root>/<owner>/<repo>.git. The rawURL is either a
+// local path, a file:// URL or an ssh://[user@]host[:port]/path URL, in which
+// case the repositories are listed with "ssh ls".
+func NewFilesystemProvider(host, rawURL string, executor exec.Interface) (Provider, error) {
+	p := &filesystemProvider{
+		host:     host,
+		executor: executor,
+	}
+
+	switch {
+	case strings.HasPrefix(rawURL, "ssh://"):
+		u, err := url.Parse(rawURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid url %q: %w", rawURL, err)
+		}
+
+		p.sshHost = u.Hostname()
+		if u.User != nil {
+			p.sshHost = u.User.Username() + "@" + p.sshHost
+		}
+		p.sshPort = u.Port()
+		p.root = path.Clean(u.Path)
+	case strings.HasPrefix(rawURL, "file://"):
+		u, err := url.Parse(rawURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid url %q: %w", rawURL, err)
+		}
+		p.root = filepath.Clean(u.Path)
+	case filepath.IsAbs(rawURL):
+		p.root = filepath.Clean(rawURL)
+	default:
+		return nil, fmt.Errorf("url must be an absolute path, a file:// or an ssh:// URL: %q", rawURL)
+	}
+
+	return p, nil
+}
+
+func (p *filesystemProvider) Host() string {
+	return p.host
+}
+
+func (p *filesystemProvider) ListRepos(ctx context.Context, owner string) ([]Repo, error) {
+	var (
+		names []string
+		err   error
+	)
+
+	if p.sshHost != "" {
+		names, err = p.listRemote(ctx, owner)
+	} else {
+		names, err = p.listLocal(owner)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	repos := make([]Repo, 0, len(names))
+	for _, name := range names {
+		repos = append(repos, Repo{
+			Host:  p.host,
+			Owner: owner,
+			Name:  strings.TrimSuffix(name, ".git"),
+		})
+	}
+
+	return repos, nil
+}
+
+func (p *filesystemProvider) listLocal(owner string) ([]string, error) {
+	dir := filepath.Join(p.root, filepath.FromSlash(owner))
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("error listing repositories in %q: %w", dir, err)
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if isBareRepo(filepath.Join(dir, entry.Name())) {
+			names = append(names, entry.Name())
+		}
+	}
+
+	return names, nil
+}
+
+func (p *filesystemProvider) listRemote(ctx context.Context, owner string) ([]string, error) {
+	dir := path.Join(p.root, owner)
+
+	args := []string{}
+	if p.sshPort != "" {
+		args = append(args, "-p", p.sshPort)
+	}
+	args = append(args, p.sshHost, "ls", "-1", cmdutil.ShellQuote(dir))
+
+	cmd := p.executor.CommandContext(ctx, "ssh", args...)
+
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("error running command %q: %w", cmd.String(), err)
+	}
+
+	// Without access to the repository files, only the conventional ".git"
+	// suffix tells bare repositories apart.
+	var names []string
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		name := strings.TrimSpace(scanner.Text())
+		if strings.HasSuffix(name, ".git") {
+			names = append(names, name)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error scanning: %w", err)
+	}
+
+	return names, nil
+}
+
+func (p *filesystemProvider) ViewRepo(_ context.Context, repo Repo) (RepoDetails, error) {
+	details := RepoDetails{Repo: repo}
+	if p.sshHost != "" {
+		return details, nil
+	}
+
+	dir := p.repoPath(repo)
+	if !isBareRepo(dir) {
+		return details, fmt.Errorf("repository not found: %s", dir)
+	}
+
+	if b, err := os.ReadFile(filepath.Join(dir, "description")); err == nil {
+		description := strings.TrimSpace(string(b))
+		if !strings.HasPrefix(description, defaultGitDescription) {
+			details.Description = description
+		}
+	}
+
+	if info, err := os.Stat(filepath.Join(dir, "refs")); err == nil {
+		details.PushedAt = info.ModTime()
+	}
+
+	return details, nil
+}
+
+func (p *filesystemProvider) CloneURL(repo Repo) string {
+	if p.sshHost == "" {
+		return (&url.URL{Scheme: "file", Path: p.repoPath(repo)}).String()
+	}
+
+	host := p.sshHost
+	if p.sshPort != "" {
+		host += ":" + p.sshPort
+	}
+
+	return "ssh://" + host + path.Join(p.root, repo.Owner, repo.Name+".git")
+}
+
+// WebURL returns an empty string, since there's no web interface.
+func (p *filesystemProvider) WebURL(_ Repo) string {
+	return ""
+}
+
+func (p *filesystemProvider) repoPath(repo Repo) string {
+	return filepath.Join(p.root, filepath.FromSlash(repo.Owner), repo.Name+".git")
+}
+
+// isBareRepo reports whether dir looks like a bare Git repository.
+func isBareRepo(dir string) bool {
+	for _, name := range []string{"HEAD", "objects", "refs"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); errors.Is(err, os.ErrNotExist) {
+			return false
+		}
+	}
+
+	return true
+}
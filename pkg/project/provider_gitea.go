@@ -0,0 +1,116 @@
+package project
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/zkhvan/z/pkg/git"
+	"github.com/zkhvan/z/pkg/gitea"
+)
+
+type giteaProvider struct {
+	client *gitea.Client
+	git    *git.Client
+
+	// ssh clones with the SSH URLs instead of the HTTPS ones.
+	ssh bool
+}
+
+var (
+	_ Provider = (*giteaProvider)(nil)
+	_ Cloner   = (*giteaProvider)(nil)
+)
+
+// NewGiteaProvider returns a provider backed by the Gitea REST API, which is
+// also served by Forgejo.
+func NewGiteaProvider(client *gitea.Client) Provider {
+	return &giteaProvider{client: client, git: git.NewClient()}
+}
+
+func newGiteaProviderFromConfig(pc ProviderConfig, gitClient *git.Client) Provider {
+	client := gitea.NewClient(normalizeHost(pc.Host))
+
+	if pc.APIURL != "" {
+		client.SetBaseURL(pc.APIURL)
+	}
+
+	if token := expandToken(pc.Token); token != "" {
+		client.SetToken(token)
+	}
+
+	return &giteaProvider{
+		client: client,
+		git:    gitClient,
+		ssh:    pc.CloneProtocol == "ssh",
+	}
+}
+
+func (p *giteaProvider) Host() string {
+	return p.client.Host()
+}
+
+func (p *giteaProvider) ListRepos(ctx context.Context, owner string) ([]Repo, error) {
+	repos, err := p.client.ListRepos(ctx, &gitea.RepoListOptions{Owner: owner})
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]Repo, 0, len(repos))
+	for _, r := range repos {
+		out = append(out, Repo{
+			Host:  p.Host(),
+			Owner: r.Owner.Login,
+			Name:  r.Name,
+			Info:  toGiteaRepoInfo(r),
+		})
+	}
+
+	return out, nil
+}
+
+func (p *giteaProvider) ViewRepo(ctx context.Context, repo Repo) (RepoDetails, error) {
+	r, err := p.client.GetRepo(ctx, repo.Owner, repo.Name)
+	if err != nil {
+		return RepoDetails{}, err
+	}
+
+	return RepoDetails{Repo: repo, RepoInfo: *toGiteaRepoInfo(r)}, nil
+}
+
+func toGiteaRepoInfo(r *gitea.Repo) *RepoInfo {
+	return &RepoInfo{
+		Description: r.Description,
+		Language:    r.Language,
+		Stars:       r.Stars,
+		PushedAt:    r.UpdatedAt,
+	}
+}
+
+// Clone clones the repository with the clone URL returned by the API, which
+// accounts for a custom SSH port or a subpath.
+func (p *giteaProvider) Clone(ctx context.Context, repo Repo, path string) (string, error) {
+	r, err := p.client.GetRepo(ctx, repo.Owner, repo.Name)
+	if err != nil {
+		return "", err
+	}
+
+	cloneURL := r.CloneURL
+	if p.ssh {
+		cloneURL = r.SSHURL
+	}
+	if cloneURL == "" {
+		cloneURL = p.CloneURL(repo)
+	}
+
+	return p.git.Clone(ctx, cloneURL, path)
+}
+
+// CloneURL returns the HTTPS clone URL of the repository, without calling the
+// API. Clone uses the URL returned by the API instead.
+func (p *giteaProvider) CloneURL(repo Repo) string {
+	return p.WebURL(repo) + ".git"
+}
+
+func (p *giteaProvider) WebURL(repo Repo) string {
+	return fmt.Sprintf("%s/%s", p.client.WebURL(), repo.ID())
+}
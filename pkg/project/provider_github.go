@@ -0,0 +1,131 @@
+package project
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/zkhvan/z/pkg/gh"
+)
+
+type gitHubProvider struct {
+	client *gh.Client
+}
+
+var (
+	_ Provider     = (*gitHubProvider)(nil)
+	_ Cloner       = (*gitHubProvider)(nil)
+	_ WebOpener    = (*gitHubProvider)(nil)
+	_ SourceLister = (*gitHubProvider)(nil)
+)
+
+// NewGitHubProvider returns a provider for the host of the client, which is
+// github.com or a GitHub Enterprise Server, backed by the gh CLI.
+func NewGitHubProvider(client *gh.Client) Provider {
+	return &gitHubProvider{client: client}
+}
+
+func newGitHubProviderFromConfig(pc ProviderConfig, client *gh.Client) Provider {
+	client = client.WithHost(normalizeHost(pc.Host))
+	if pc.APIURL != "" {
+		client.SetBaseURL(pc.APIURL)
+	}
+	if token := expandToken(pc.Token); token != "" {
+		client.SetToken(token)
+	}
+
+	return NewGitHubProvider(client)
+}
+
+func (p *gitHubProvider) Host() string {
+	return p.client.Host()
+}
+
+func (p *gitHubProvider) ListRepos(ctx context.Context, owner string) ([]Repo, error) {
+	repos, err := p.client.ListRepos(ctx, &gh.RepoListOptions{Owner: owner})
+	if err != nil {
+		return nil, err
+	}
+
+	return p.toRepos(repos), nil
+}
+
+func (p *gitHubProvider) ListSource(ctx context.Context, source Source) ([]Repo, error) {
+	var (
+		repos []*gh.Repo
+		err   error
+	)
+
+	switch source.Kind {
+	case SourceKindViewer:
+		repos, err = p.client.ListViewerRepos(ctx)
+	case SourceKindStarred:
+		repos, err = p.client.ListStarredRepos(ctx, source.Arg)
+	case SourceKindTeam:
+		org, team, _ := strings.Cut(source.Arg, "/")
+		repos, err = p.client.ListTeamRepos(ctx, org, team)
+	case SourceKindTopic:
+		repos, err = p.client.SearchRepos(ctx, "topic:"+source.Arg)
+	case SourceKindSearch:
+		repos, err = p.client.SearchRepos(ctx, source.Arg)
+	default:
+		return nil, fmt.Errorf("unsupported source %q", source)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return p.toRepos(repos), nil
+}
+
+func (p *gitHubProvider) toRepos(repos []*gh.Repo) []Repo {
+	out := make([]Repo, 0, len(repos))
+	for _, r := range repos {
+		out = append(out, Repo{
+			Host:  p.Host(),
+			Owner: r.Owner,
+			Name:  r.Name,
+			Info:  toRepoInfo(r),
+		})
+	}
+
+	return out
+}
+
+func (p *gitHubProvider) ViewRepo(ctx context.Context, repo Repo) (RepoDetails, error) {
+	r, err := p.client.GetRepo(ctx, repo.ID())
+	if err != nil {
+		return RepoDetails{}, err
+	}
+
+	return RepoDetails{Repo: repo, RepoInfo: *toRepoInfo(r)}, nil
+}
+
+func toRepoInfo(r *gh.Repo) *RepoInfo {
+	return &RepoInfo{
+		Description: r.Description,
+		Language:    r.Language,
+		Stars:       r.Stars,
+		PushedAt:    r.PushedAt,
+	}
+}
+
+func (p *gitHubProvider) CloneURL(repo Repo) string {
+	return p.WebURL(repo)
+}
+
+func (p *gitHubProvider) WebURL(repo Repo) string {
+	return fmt.Sprintf("https://%s/%s", p.Host(), repo.ID())
+}
+
+func (p *gitHubProvider) Clone(ctx context.Context, repo Repo, path string) (string, error) {
+	return p.client.Clone(ctx, p.CloneURL(repo), path)
+}
+
+func (p *gitHubProvider) OpenWeb(ctx context.Context, repo Repo) error {
+	_, err := p.client.RepoView(ctx, &gh.RepoViewOptions{
+		RepositoryID: repo.ID(),
+		Web:          true,
+	})
+	return err
+}
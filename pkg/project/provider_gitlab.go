@@ -0,0 +1,82 @@
+package project
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/zkhvan/z/pkg/exec"
+	"github.com/zkhvan/z/pkg/gitlab"
+)
+
+type gitLabProvider struct {
+	client *gitlab.Client
+}
+
+var _ Provider = (*gitLabProvider)(nil)
+
+// NewGitLabProvider returns a provider backed by the GitLab REST API.
+func NewGitLabProvider(client *gitlab.Client) Provider {
+	return &gitLabProvider{client: client}
+}
+
+func newGitLabProviderFromConfig(pc ProviderConfig, executor exec.Interface) Provider {
+	client := gitlab.NewClient(normalizeHost(pc.Host)).SetExecutor(executor)
+
+	if pc.APIURL != "" {
+		client.SetBaseURL(pc.APIURL)
+	}
+
+	if token := expandToken(pc.Token); token != "" {
+		client.SetToken(token)
+	}
+
+	return NewGitLabProvider(client)
+}
+
+func (p *gitLabProvider) Host() string {
+	return p.client.Host()
+}
+
+func (p *gitLabProvider) ListRepos(ctx context.Context, owner string) ([]Repo, error) {
+	projects, err := p.client.ListProjects(ctx, &gitlab.ProjectListOptions{Owner: owner})
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]Repo, 0, len(projects))
+	for _, gp := range projects {
+		out = append(out, Repo{
+			Host:  p.Host(),
+			Owner: gp.Owner(),
+			Name:  gp.Name,
+			Info:  toGitLabRepoInfo(gp),
+		})
+	}
+
+	return out, nil
+}
+
+func (p *gitLabProvider) ViewRepo(ctx context.Context, repo Repo) (RepoDetails, error) {
+	gp, err := p.client.GetProject(ctx, repo.ID())
+	if err != nil {
+		return RepoDetails{}, err
+	}
+
+	return RepoDetails{Repo: repo, RepoInfo: *toGitLabRepoInfo(gp)}, nil
+}
+
+func toGitLabRepoInfo(gp *gitlab.Project) *RepoInfo {
+	return &RepoInfo{
+		Description: gp.Description,
+		Stars:       gp.StarCount,
+		PushedAt:    gp.LastActivityAt,
+	}
+}
+
+func (p *gitLabProvider) CloneURL(repo Repo) string {
+	return p.WebURL(repo) + ".git"
+}
+
+func (p *gitLabProvider) WebURL(repo Repo) string {
+	return fmt.Sprintf("https://%s/%s", p.Host(), repo.ID())
+}
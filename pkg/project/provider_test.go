@@ -0,0 +1,531 @@
+package project_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	osexec "os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/MakeNowJust/heredoc/v2"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/zkhvan/z/pkg/assert"
+	"github.com/zkhvan/z/pkg/exec"
+	testingexec "github.com/zkhvan/z/pkg/exec/testing"
+	"github.com/zkhvan/z/pkg/project"
+)
+
+func TestList_GitLabProvider(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v4/groups/group/projects", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("PRIVATE-TOKEN") != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		fmt.Fprint(w, `[
+			{"path":"one","namespace":{"full_path":"group"}},
+			{"path":"two","namespace":{"full_path":"group/subgroup"}}
+		]`)
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	td := setupTestDir(t)
+	cfg := setupConfig(t, td, heredoc.Docf(`
+		projects:
+		  root: $PROJECTSDIR
+		  max_depth: 4
+		  remote_patterns:
+		    - gitlab.example.com/group/* -> ./gl
+		  providers:
+		    - host: gitlab.example.com
+		      type: gitlab
+		      api_url: %s/api/v4
+		      token: secret
+	`, server.URL))
+
+	// A local clone of a repository in a nested group.
+	err := os.MkdirAll(filepath.Join(td.projects, "gl", "group", "subgroup", "two", ".git"), 0o700)
+	assert.NoError(t, err)
+
+	service, err := project.NewService(cfg, project.WithCacheDir(td.cache))
+	assert.NoError(t, err)
+
+	projects, err := service.ListProjects(context.Background(), &project.ListOptions{Local: true, Remote: true})
+	assert.NoError(t, err)
+
+	expected := []project.Project{
+		{
+			LocalID:      filepath.Join("gl", "group", "one"),
+			RemoteID:     "group/one",
+			Host:         "gitlab.example.com",
+			AbsolutePath: filepath.Join(td.projects, "gl", "group", "one"),
+			Source:       project.SourceTypeRemote,
+		},
+		{
+			LocalID:      filepath.Join("gl", "group", "subgroup", "two"),
+			RemoteID:     "group/subgroup/two",
+			Host:         "gitlab.example.com",
+			AbsolutePath: filepath.Join(td.projects, "gl", "group", "subgroup", "two"),
+			Source:       project.SourceTypeSynced,
+		},
+	}
+
+	if diff := cmp.Diff(expected, projects); diff != "" {
+		t.Fatalf("projects mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestList_GiteaProvider(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/orgs/acme/repos", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "token secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		fmt.Fprint(w, `[
+			{"name":"api","owner":{"login":"acme"}},
+			{"name":"web","owner":{"login":"acme"}}
+		]`)
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	t.Setenv("FORGEJO_TEST_TOKEN", "secret")
+
+	td := setupTestDir(t)
+	cfg := setupConfig(t, td, heredoc.Docf(`
+		projects:
+		  root: $PROJECTSDIR
+		  remote_patterns:
+		    - git.internal/acme/*
+		  providers:
+		    - host: git.internal
+		      type: forgejo
+		      api_url: %s/api/v1
+		      token: $FORGEJO_TEST_TOKEN
+	`, server.URL))
+
+	err := os.MkdirAll(filepath.Join(td.projects, "acme", "api", ".git"), 0o700)
+	assert.NoError(t, err)
+
+	service, err := project.NewService(cfg, project.WithCacheDir(td.cache))
+	assert.NoError(t, err)
+
+	projects, err := service.ListProjects(context.Background(), &project.ListOptions{Local: true, Remote: true})
+	assert.NoError(t, err)
+
+	expected := []project.Project{
+		{
+			LocalID:      filepath.Join("acme", "api"),
+			RemoteID:     "acme/api",
+			Host:         "git.internal",
+			AbsolutePath: filepath.Join(td.projects, "acme", "api"),
+			Source:       project.SourceTypeSynced,
+		},
+		{
+			LocalID:      filepath.Join("acme", "web"),
+			RemoteID:     "acme/web",
+			Host:         "git.internal",
+			AbsolutePath: filepath.Join(td.projects, "acme", "web"),
+			Source:       project.SourceTypeRemote,
+		},
+	}
+
+	if diff := cmp.Diff(expected, projects); diff != "" {
+		t.Fatalf("projects mismatch (-want +got):\n%s", diff)
+	}
+
+	proj, err := service.Resolve(context.Background(), "git.internal/acme/web")
+	assert.NoError(t, err)
+	assert.EqualString(t, proj.URL(), "https://git.internal/acme/web")
+}
+
+func TestClone_GiteaProvider(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /gitea/api/v1/repos/acme/api", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, `{
+			"name": "api",
+			"owner": {"login": "acme"},
+			"clone_url": "https://git.internal/gitea/acme/api.git",
+			"ssh_url": "ssh://git@git.internal:2222/acme/api.git"
+		}`)
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	td := setupTestDir(t)
+	cfg := setupConfig(t, td, heredoc.Docf(`
+		projects:
+		  root: $PROJECTSDIR
+		  providers:
+		    - host: git.internal
+		      type: gitea
+		      api_url: %s/gitea/api/v1
+		      clone_protocol: ssh
+	`, server.URL))
+
+	dest := filepath.Join(td.projects, "acme", "api")
+	fakeexec := &testingexec.FakeExec{
+		CommandScript: []testingexec.FakeCommandAction{
+			func(_ string, _ ...string) exec.Cmd {
+				fakeCmd := testingexec.NewFakeCmd("git", "clone", "ssh://git@git.internal:2222/acme/api.git", dest)
+				fakeCmd.CombinedOutputScripts = []testingexec.FakeAction{
+					func() ([]byte, []byte, error) { return nil, nil, nil },
+				}
+				return fakeCmd
+			},
+		},
+	}
+
+	service, err := project.NewService(cfg, project.WithCacheDir(td.cache), project.WithExecutor(fakeexec))
+	assert.NoError(t, err)
+
+	proj, err := service.Resolve(context.Background(), "git.internal/acme/api")
+	assert.NoError(t, err)
+	assert.EqualString(t, proj.URL(), "https://git.internal/acme/api")
+
+	_, _, err = service.CloneProject(context.Background(), proj, nil)
+	assert.NoError(t, err)
+	if fakeexec.CommandCalls != 1 {
+		t.Fatalf("expected git clone to be called, got %d commands", fakeexec.CommandCalls)
+	}
+}
+
+func TestList_FilesystemProvider(t *testing.T) {
+	if _, err := osexec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	td := setupTestDir(t)
+	mirror := filepath.Join(td.root, "mirror")
+
+	for _, name := range []string{"api.git", "web.git"} {
+		out, err := osexec.Command("git", "init", "--bare", filepath.Join(mirror, "acme", name)).CombinedOutput()
+		if err != nil {
+			t.Fatalf("git init failed: %s: %s", err, out)
+		}
+	}
+
+	// Plain directories aren't repositories.
+	err := os.MkdirAll(filepath.Join(mirror, "acme", "notes"), 0o700)
+	assert.NoError(t, err)
+
+	cfg := setupConfig(t, td, heredoc.Docf(`
+		projects:
+		  root: $PROJECTSDIR
+		  remote_patterns:
+		    - mirror/acme/*
+		  providers:
+		    - host: mirror
+		      type: filesystem
+		      url: %s
+	`, mirror))
+
+	service, err := project.NewService(cfg, project.WithCacheDir(td.cache))
+	assert.NoError(t, err)
+
+	projects, err := service.ListProjects(context.Background(), &project.ListOptions{Remote: true})
+	assert.NoError(t, err)
+
+	expected := []project.Project{
+		{
+			LocalID:      filepath.Join("acme", "api"),
+			RemoteID:     "acme/api",
+			Host:         "mirror",
+			AbsolutePath: filepath.Join(td.projects, "acme", "api"),
+			Source:       project.SourceTypeRemote,
+		},
+		{
+			LocalID:      filepath.Join("acme", "web"),
+			RemoteID:     "acme/web",
+			Host:         "mirror",
+			AbsolutePath: filepath.Join(td.projects, "acme", "web"),
+			Source:       project.SourceTypeRemote,
+		},
+	}
+
+	if diff := cmp.Diff(expected, projects); diff != "" {
+		t.Fatalf("projects mismatch (-want +got):\n%s", diff)
+	}
+
+	proj, err := service.Resolve(context.Background(), "mirror/acme/web")
+	assert.NoError(t, err)
+
+	proj, _, err = service.CloneProject(context.Background(), proj, &project.CloneOptions{})
+	assert.NoError(t, err)
+	if proj.Source != project.SourceTypeSynced {
+		t.Fatalf("expected a synced project, got %v", proj.Source)
+	}
+
+	origin, err := osexec.Command("git", "-C", proj.AbsolutePath, "remote", "get-url", "origin").Output()
+	assert.NoError(t, err)
+	assert.EqualString(t, strings.TrimSpace(string(origin)), "file://"+filepath.Join(mirror, "acme", "web.git"))
+
+	// Cloning again is detected as the same remote.
+	_, output, err := service.CloneProject(context.Background(), proj, &project.CloneOptions{
+		OnConflict: project.ConflictPolicyFail,
+	})
+	assert.NoError(t, err)
+	assert.EqualString(t, output, "Project already cloned: "+proj.AbsolutePath)
+}
+
+func TestFilesystemProvider_SSH(t *testing.T) {
+	fakeexec := &testingexec.FakeExec{
+		CommandScript: []testingexec.FakeCommandAction{
+			func(_ string, _ ...string) exec.Cmd {
+				fakeCmd := testingexec.NewFakeCmd("ssh", "-p", "2222", "git@backup.internal", "ls", "-1", "'/srv/git/acme'")
+				fakeCmd.OutputScripts = []testingexec.FakeAction{
+					func() ([]byte, []byte, error) {
+						return []byte("api.git\nREADME\nweb.git\n"), nil, nil
+					},
+				}
+				return fakeCmd
+			},
+		},
+	}
+
+	provider, err := project.NewFilesystemProvider("backup.internal", "ssh://git@backup.internal:2222/srv/git", fakeexec)
+	assert.NoError(t, err)
+
+	repos, err := provider.ListRepos(context.Background(), "acme")
+	assert.NoError(t, err)
+
+	expected := []project.Repo{
+		{Host: "backup.internal", Owner: "acme", Name: "api"},
+		{Host: "backup.internal", Owner: "acme", Name: "web"},
+	}
+
+	if diff := cmp.Diff(expected, repos); diff != "" {
+		t.Fatalf("repos mismatch (-want +got):\n%s", diff)
+	}
+
+	assert.EqualString(t, provider.CloneURL(repos[0]), "ssh://git@backup.internal:2222/srv/git/acme/api.git")
+}
+
+func TestList_GitHubEnterprise(t *testing.T) {
+	td := setupTestDir(t)
+	cfg := setupConfig(t, td, heredoc.Doc(`
+		projects:
+		  root: $PROJECTSDIR
+		  # The fake commands are run in order.
+		  concurrency: 1
+		  remote_patterns:
+		    - ghe.corp.com/platform/* -> ./corp
+		    - platform/*
+	`))
+
+	var calls []*testingexec.FakeCmd
+	fakeexec := &testingexec.FakeExec{}
+	for _, host := range []string{"ghe.corp.com", "github.com"} {
+		listRepos := func(_ string, _ ...string) exec.Cmd {
+			fakeCmd := testingexec.NewFakeCmd(
+				"gh", "repo", "list", "--limit", "9999", "platform",
+				"--json", "owner,name,description,primaryLanguage,stargazerCount,pushedAt,url",
+			)
+			fakeCmd.OutputScripts = []testingexec.FakeAction{
+				func() ([]byte, []byte, error) {
+					return []byte(fmt.Sprintf(`[{"owner":{"login":"platform"},"name":"%s"}]`, host)), nil, nil
+				},
+			}
+			calls = append(calls, fakeCmd)
+			return fakeCmd
+		}
+		fakeexec.CommandScript = append(fakeexec.CommandScript, fakeGHAuthToken(t, host), listRepos)
+	}
+
+	service, err := project.NewService(cfg, project.WithCacheDir(td.cache), project.WithExecutor(fakeexec))
+	assert.NoError(t, err)
+
+	projects, err := service.ListProjects(context.Background(), &project.ListOptions{Remote: true})
+	assert.NoError(t, err)
+
+	expected := []project.Project{
+		{
+			LocalID:      filepath.Join("corp", "platform", "ghe.corp.com"),
+			RemoteID:     "platform/ghe.corp.com",
+			Host:         "ghe.corp.com",
+			AbsolutePath: filepath.Join(td.projects, "corp", "platform", "ghe.corp.com"),
+			Source:       project.SourceTypeRemote,
+		},
+		{
+			LocalID:      filepath.Join("platform", "github.com"),
+			RemoteID:     "platform/github.com",
+			AbsolutePath: filepath.Join(td.projects, "platform", "github.com"),
+			Source:       project.SourceTypeRemote,
+		},
+	}
+
+	if diff := cmp.Diff(expected, projects); diff != "" {
+		t.Fatalf("projects mismatch (-want +got):\n%s", diff)
+	}
+
+	// Only the enterprise host is routed with GH_HOST.
+	if !slices.Contains(calls[0].Env, "GH_HOST=ghe.corp.com") {
+		t.Fatalf("expected GH_HOST=ghe.corp.com in the environment of %v", calls[0].Argv)
+	}
+	if calls[1].Env != nil {
+		t.Fatalf("expected the default environment for %v, got %v", calls[1].Argv, calls[1].Env)
+	}
+
+	assert.EqualString(t, projects[0].URL(), "https://ghe.corp.com/platform/ghe.corp.com")
+
+	// The cache keeps the host.
+	projects, err = service.ListProjects(context.Background(), &project.ListOptions{Remote: true})
+	assert.NoError(t, err)
+
+	if diff := cmp.Diff(expected, projects); diff != "" {
+		t.Fatalf("cached projects mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestList_SameRepoOnTwoHosts(t *testing.T) {
+	td := setupTestDir(t)
+	cfg := setupConfig(t, td, heredoc.Doc(`
+		projects:
+		  root: $PROJECTSDIR
+		  # The fake commands are run in order.
+		  concurrency: 1
+		  remote_patterns:
+		    - ghe.corp.com/acme/*
+		    - acme/*
+	`))
+
+	fakeexec := &testingexec.FakeExec{}
+	for _, host := range []string{"ghe.corp.com", "github.com"} {
+		listRepos := func(_ string, _ ...string) exec.Cmd {
+			fakeCmd := testingexec.NewFakeCmd(
+				"gh", "repo", "list", "--limit", "9999", "acme",
+				"--json", "owner,name,description,primaryLanguage,stargazerCount,pushedAt,url",
+			)
+			fakeCmd.OutputScripts = []testingexec.FakeAction{
+				func() ([]byte, []byte, error) {
+					return []byte(`[{"owner":{"login":"acme"},"name":"x"},{"owner":{"login":"acme"},"name":"` +
+						host + `"}]`), nil, nil
+				},
+			}
+			return fakeCmd
+		}
+		fakeexec.CommandScript = append(fakeexec.CommandScript, fakeGHAuthToken(t, host), listRepos)
+	}
+
+	var warnings strings.Builder
+	service, err := project.NewService(
+		cfg,
+		project.WithCacheDir(td.cache),
+		project.WithExecutor(fakeexec),
+		project.WithWarnings(&warnings),
+	)
+	assert.NoError(t, err)
+
+	projects, err := service.ListProjects(context.Background(), &project.ListOptions{Remote: true})
+	assert.NoError(t, err)
+
+	// The colliding project is left out, the others are still listed.
+	var ids []string
+	for _, p := range projects {
+		ids = append(ids, p.HostName()+"/"+p.RemoteID)
+	}
+	expected := []string{"ghe.corp.com/acme/ghe.corp.com", "github.com/acme/github.com", "ghe.corp.com/acme/x"}
+	if diff := cmp.Diff(expected, ids); diff != "" {
+		t.Fatalf("projects mismatch (-want +got):\n%s", diff)
+	}
+
+	assert.EqualString(t, warnings.String(), fmt.Sprintf(
+		"warning: skipping %s: ghe.corp.com/acme/x and github.com/acme/x are mapped to the same path, "+
+			"give one of their patterns an alternate path\n",
+		filepath.Join(td.projects, "acme", "x"),
+	))
+}
+
+func TestList_GitHubSources(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /user/repos", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, `[{"name":"dotfiles","owner":{"login":"me"}}]`)
+	})
+	mux.HandleFunc("GET /user/starred", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, `[{"name":"cli","owner":{"login":"cli"}}]`)
+	})
+	mux.HandleFunc("GET /orgs/acme/teams/platform/repos", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, `[{"name":"infra","owner":{"login":"acme"}}]`)
+	})
+	mux.HandleFunc("GET /search/repositories", func(w http.ResponseWriter, r *http.Request) {
+		switch q := r.URL.Query().Get("q"); q {
+		case "topic:acme-service":
+			fmt.Fprint(w, `{"items":[{"name":"billing","owner":{"login":"acme"}}]}`)
+		case "org:acme language:go":
+			fmt.Fprint(w, `{"items":[{"name":"api","owner":{"login":"acme"}}]}`)
+		default:
+			t.Errorf("unexpected query %q", q)
+		}
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	td := setupTestDir(t)
+	cfg := setupConfig(t, td, heredoc.Docf(`
+		projects:
+		  root: $PROJECTSDIR
+		  remote_patterns:
+		    - "@me/*"
+		    - "starred: -> ./starred/*"
+		    - team:acme/platform -> ./platform
+		    - topic:acme-service -> ./services/*
+		    - "search: org:acme language:go"
+		  providers:
+		    - host: github.com
+		      type: github
+		      api_url: %s
+		      token: secret
+	`, server.URL))
+
+	// A local clone of a starred repository.
+	err := os.MkdirAll(filepath.Join(td.projects, "starred", "cli", ".git"), 0o700)
+	assert.NoError(t, err)
+
+	service, err := project.NewService(cfg, project.WithCacheDir(td.cache))
+	assert.NoError(t, err)
+
+	projects, err := service.ListProjects(context.Background(), &project.ListOptions{Local: true, Remote: true})
+	assert.NoError(t, err)
+
+	var got []string
+	for _, p := range projects {
+		got = append(got, fmt.Sprintf("%s %s %s", p.LocalID, p.RemoteID, p.Source))
+	}
+
+	expected := []string{
+		"acme/api acme/api [R]",
+		"me/dotfiles me/dotfiles [R]",
+		"platform/acme/infra acme/infra [R]",
+		"services/billing acme/billing [R]",
+		"starred/cli cli/cli [S]",
+	}
+
+	if diff := cmp.Diff(expected, got); diff != "" {
+		t.Fatalf("projects mismatch (-want +got):\n%s", diff)
+	}
+
+	// The listed repositories of the sources resolve to the same paths.
+	for input, want := range map[string]string{
+		"cli/cli":      filepath.Join("starred", "cli"),
+		"acme/billing": filepath.Join("services", "billing"),
+		"acme/other":   filepath.Join("acme", "other"),
+	} {
+		p, err := service.Resolve(context.Background(), input)
+		assert.NoError(t, err)
+		assert.EqualString(t, p.LocalID, want)
+	}
+}
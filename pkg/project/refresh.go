@@ -0,0 +1,143 @@
+package project
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/zkhvan/z/pkg/fcache"
+)
+
+const (
+	refreshLockKey   = "projects.refresh"
+	refreshStatusKey = "projects.refresh-status"
+
+	// retryTTL is how long a pattern which failed to load isn't loaded again
+	// when listing, so a failing provider doesn't slow down every list.
+	retryTTL = time.Minute
+)
+
+// ErrRefreshRunning is returned by Refresh when another refresh is running.
+var ErrRefreshRunning = errors.New("a refresh is already running")
+
+// RefreshStatus is the outcome of the refreshes of the remote projects.
+type RefreshStatus struct {
+	// StartedAt and FinishedAt are the times of the last refresh.
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+	// Errors are the errors of the patterns which failed in their last
+	// refresh, by pattern. Refreshing some patterns keeps the errors of the
+	// others.
+	Errors map[string]PatternError `json:"errors,omitempty"`
+
+	// Running is set if a refresh is running.
+	Running bool `json:"-"`
+}
+
+// PatternError is the error of a pattern which failed to load.
+type PatternError struct {
+	Error    string    `json:"error"`
+	FailedAt time.Time `json:"failed_at"`
+}
+
+// WithBackgroundRefresh sets how to start a refresh in the background. When
+// set, an expired cache is returned right away while it's refreshed in the
+// background, instead of waiting for the providers.
+func WithBackgroundRefresh(start func() error) ServiceOption {
+	return func(s *Service) {
+		s.backgroundRefresh = start
+	}
+}
+
+// RefreshOptions selects the patterns to refresh.
+type RefreshOptions struct {
+	// Patterns are the patterns to refresh, e.g. "my-org/*", or their owners,
+	// e.g. "my-org". All the patterns are refreshed if empty.
+	Patterns []string
+
+	// ExpiredOnly only refreshes the patterns whose cache expired.
+	ExpiredOnly bool
+}
+
+// Refresh loads the remote projects into the cache. Only one refresh runs at a
+// time, across processes.
+func (s *Service) Refresh(ctx context.Context, opts *RefreshOptions) error {
+	if opts == nil {
+		opts = &RefreshOptions{}
+	}
+
+	if s.offline {
+		return errors.New("can't refresh the cache while offline")
+	}
+
+	patterns, err := s.selectPatterns(opts.Patterns)
+	if err != nil {
+		return err
+	}
+
+	if opts.ExpiredOnly {
+		patterns = slices.DeleteFunc(patterns, func(p remotePattern) bool {
+			_, err := s.remoteCache().Load(p.cacheKey())
+			return err == nil
+		})
+	}
+
+	if len(patterns) == 0 {
+		return nil
+	}
+
+	unlock, err := fcache.TryLock(s.cacheDir, refreshLockKey)
+	if errors.Is(err, fcache.ErrLocked) {
+		return ErrRefreshRunning
+	}
+	if err != nil {
+		return fmt.Errorf("error locking the refresh: %w", err)
+	}
+	defer unlock()
+
+	_, err = s.refreshRemoteProjects(ctx, patterns)
+	return err
+}
+
+func (s *Service) selectPatterns(filters []string) ([]remotePattern, error) {
+	if len(filters) == 0 {
+		return slices.Clone(s.cfg.remotePatterns), nil
+	}
+
+	var patterns []remotePattern
+	for _, filter := range filters {
+		i := len(patterns)
+		for _, pattern := range s.cfg.remotePatterns {
+			if pattern.selectedBy(filter) && !slices.ContainsFunc(patterns, func(p remotePattern) bool {
+				return p.original == pattern.original
+			}) {
+				patterns = append(patterns, pattern)
+			}
+		}
+
+		if len(patterns) == i {
+			return nil, fmt.Errorf("no remote pattern matches %q", filter)
+		}
+	}
+
+	return patterns, nil
+}
+
+// RefreshStatus returns the status of the last refresh. It returns
+// fcache.ErrNotFound if the remote projects were never loaded.
+func (s *Service) RefreshStatus() (RefreshStatus, error) {
+	status, err := fcache.Load[RefreshStatus](s.cacheDir, refreshStatusKey)
+	if err != nil && !errors.Is(err, fcache.ErrNotFound) {
+		return status, err
+	}
+
+	unlock, lockErr := fcache.TryLock(s.cacheDir, refreshLockKey)
+	if lockErr == nil {
+		_ = unlock()
+	}
+	status.Running = errors.Is(lockErr, fcache.ErrLocked)
+
+	return status, err
+}
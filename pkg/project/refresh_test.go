@@ -0,0 +1,324 @@
+package project_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/MakeNowJust/heredoc/v2"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/zkhvan/z/pkg/assert"
+	"github.com/zkhvan/z/pkg/fcache"
+	"github.com/zkhvan/z/pkg/project"
+)
+
+func TestList_StaleWhileRevalidate(t *testing.T) {
+	var requests atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests.Add(1)
+		fmt.Fprint(w, `[{"name":"repo","owner":{"login":"owner"}}]`)
+	}))
+	t.Cleanup(server.Close)
+
+	td := setupTestDir(t)
+	cfg := setupConfig(t, td, heredoc.Docf(`
+		projects:
+		  root: $PROJECTSDIR
+		  remote_patterns:
+		    - owner/*
+		  providers:
+		    - host: github.com
+		      type: github
+		      api_url: %s
+		      token: secret
+	`, server.URL))
+
+	var refreshes int
+	newService := func() *project.Service {
+		service, err := project.NewService(
+			cfg,
+			project.WithCacheDir(td.cache),
+			project.WithBackgroundRefresh(func() error {
+				refreshes++
+				return nil
+			}),
+		)
+		assert.NoError(t, err)
+		return service
+	}
+
+	// Without a cache, the projects are loaded right away.
+	projects, err := newService().ListProjects(context.Background(), &project.ListOptions{Remote: true})
+	assert.NoError(t, err)
+	if len(projects) != 1 || projects[0].Stale || refreshes != 0 {
+		t.Fatalf("expected a fresh project without a background refresh, got %+v", projects)
+	}
+
+	expireCache(t, td)
+
+	projects, err = newService().ListProjects(context.Background(), &project.ListOptions{Remote: true})
+	assert.NoError(t, err)
+	if len(projects) != 1 || !projects[0].Stale {
+		t.Fatalf("expected a stale project, got %+v", projects)
+	}
+	if refreshes != 1 || requests.Load() != 1 {
+		t.Fatalf("expected a background refresh instead of a request, got %d and %d", refreshes, requests.Load()-1)
+	}
+
+	// The background refresh updates the cache and its status.
+	assert.NoError(t, newService().Refresh(context.Background(), nil))
+
+	projects, err = newService().ListProjects(context.Background(), &project.ListOptions{Remote: true})
+	assert.NoError(t, err)
+	if len(projects) != 1 || projects[0].Stale {
+		t.Fatalf("expected a fresh project, got %+v", projects)
+	}
+
+	status, err := newService().RefreshStatus()
+	assert.NoError(t, err)
+	if status.FinishedAt.IsZero() || len(status.Errors) != 0 || status.Running {
+		t.Fatalf("unexpected refresh status: %+v", status)
+	}
+}
+
+func TestRefresh_Lock(t *testing.T) {
+	td := setupTestDir(t)
+	cfg := setupConfig(t, td, heredoc.Doc(`
+		projects:
+		  root: $PROJECTSDIR
+		  remote_patterns:
+		    - owner/repo
+	`))
+
+	service, err := project.NewService(cfg, project.WithCacheDir(td.cache))
+	assert.NoError(t, err)
+
+	unlock, err := fcache.TryLock(td.cache, "projects.refresh")
+	assert.NoError(t, err)
+
+	err = service.Refresh(context.Background(), nil)
+	if !errors.Is(err, project.ErrRefreshRunning) {
+		t.Fatalf("expected ErrRefreshRunning, got %v", err)
+	}
+
+	status, err := service.RefreshStatus()
+	if !errors.Is(err, fcache.ErrNotFound) || !status.Running {
+		t.Fatalf("expected a running refresh without a status, got %+v, %v", status, err)
+	}
+
+	assert.NoError(t, unlock())
+	assert.NoError(t, service.Refresh(context.Background(), nil))
+}
+
+// expireCache makes the cached remote projects expired.
+func expireCache(t *testing.T, td testDir) {
+	t.Helper()
+
+	files, err := filepath.Glob(filepath.Join(td.cache, "projects.remote.*-*.json"))
+	assert.NoError(t, err)
+	if len(files) == 0 {
+		t.Fatalf("expected cache files")
+	}
+
+	for _, file := range files {
+		key := file[:strings.LastIndex(file, "-")]
+		expired := fmt.Sprintf("%s-%d.json", key, time.Now().Add(-time.Hour).Unix())
+		assert.NoError(t, os.Rename(file, expired))
+	}
+}
+
+func TestList_PerPatternCache(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		requests []string
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		org := strings.Split(r.URL.Path, "/")[2]
+
+		mu.Lock()
+		requests = append(requests, org)
+		mu.Unlock()
+
+		fmt.Fprintf(w, `[{"name":"repo","owner":{"login":%q}}]`, org)
+	}))
+	t.Cleanup(server.Close)
+
+	td := setupTestDir(t)
+	newService := func(patterns string) *project.Service {
+		cfg := setupConfig(t, td, heredoc.Docf(`
+			projects:
+			  root: $PROJECTSDIR
+			  concurrency: 1
+			  remote_patterns:
+			%s
+			  providers:
+			    - host: github.com
+			      type: github
+			      api_url: %s
+			      token: secret
+		`, patterns, server.URL))
+
+		service, err := project.NewService(cfg, project.WithCacheDir(td.cache))
+		assert.NoError(t, err)
+		return service
+	}
+
+	tests := []struct {
+		name     string
+		patterns string
+		refresh  []string
+		requests []string
+	}{
+		{
+			name:     "every pattern is loaded without a cache",
+			patterns: "    - a/*\n    - b/*",
+			requests: []string{"a", "b"},
+		},
+		{
+			name:     "only an added pattern is loaded",
+			patterns: "    - a/*\n    - b/*\n    - c/*",
+			requests: []string{"c"},
+		},
+		{
+			name:     "an alternate path doesn't reload the pattern",
+			patterns: "    - a/* -> ./work\n    - b/*\n    - c/*",
+			requests: nil,
+		},
+		{
+			name:     "only the selected patterns are refreshed",
+			patterns: "    - a/* -> ./work\n    - b/*\n    - c/*",
+			refresh:  []string{"a/*", "c"},
+			requests: []string{"a", "c"},
+		},
+	}
+
+	for _, test := range tests {
+		requests = nil
+
+		service := newService(test.patterns)
+		if test.refresh != nil {
+			err := service.Refresh(context.Background(), &project.RefreshOptions{Patterns: test.refresh})
+			assert.NoError(t, err)
+		} else {
+			_, err := service.ListProjects(context.Background(), &project.ListOptions{Remote: true})
+			assert.NoError(t, err)
+		}
+
+		if diff := cmp.Diff(test.requests, requests); diff != "" {
+			t.Fatalf("%s: requests mismatch (-want +got):\n%s", test.name, diff)
+		}
+	}
+
+	err := newService("    - a/*").Refresh(context.Background(), &project.RefreshOptions{Patterns: []string{"z"}})
+	if err == nil || err.Error() != `no remote pattern matches "z"` {
+		t.Fatalf("expected an error for an unknown pattern, got %v", err)
+	}
+}
+
+func TestList_RetryFailedPatterns(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		requests []string
+		failing  = map[string]bool{"b": true}
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		org := strings.Split(r.URL.Path, "/")[2]
+
+		mu.Lock()
+		defer mu.Unlock()
+		requests = append(requests, r.URL.Path)
+
+		if failing[org] || r.URL.Path == "/user" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		fmt.Fprintf(w, `[{"name":"repo","owner":{"login":%q}}]`, org)
+	}))
+	t.Cleanup(server.Close)
+
+	td := setupTestDir(t)
+	cfg := setupConfig(t, td, heredoc.Docf(`
+		projects:
+		  root: $PROJECTSDIR
+		  concurrency: 1
+		  remote_patterns:
+		    - a/*
+		    - b/*
+		  providers:
+		    - host: github.com
+		      type: github
+		      api_url: %s
+		      token: secret
+	`, server.URL))
+
+	// The cache of previous versions, under a single key.
+	legacy := filepath.Join(td.cache, fmt.Sprintf("projects.remote-%d.json", time.Now().Add(time.Hour).Unix()))
+	assert.NoError(t, os.WriteFile(legacy, []byte(`{"version":1,"data":[]}`), 0o600))
+
+	list := func() (string, []string) {
+		t.Helper()
+
+		var warnings strings.Builder
+		service, err := project.NewService(cfg, project.WithCacheDir(td.cache), project.WithWarnings(&warnings))
+		assert.NoError(t, err)
+
+		mu.Lock()
+		requests = nil
+		mu.Unlock()
+
+		_, err = service.ListProjects(context.Background(), &project.ListOptions{Remote: true})
+		assert.NoError(t, err)
+
+		return warnings.String(), requests
+	}
+
+	warnings, got := list()
+	if len(got) == 0 || !strings.Contains(warnings, `error loading "b/*"`) {
+		t.Fatalf("expected b/* to be requested and fail, got %v and %q", got, warnings)
+	}
+	if _, err := os.Stat(legacy); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected the legacy cache to be removed, got %v", err)
+	}
+
+	// The failed pattern isn't requested again until the retry TTL passed.
+	warnings, got = list()
+	if len(got) != 0 || !strings.Contains(warnings, `error loading "b/*"`) {
+		t.Fatalf("expected no requests and a warning, got %v and %q", got, warnings)
+	}
+
+	// Refreshing a pattern keeps the errors of the others.
+	service, err := project.NewService(cfg, project.WithCacheDir(td.cache))
+	assert.NoError(t, err)
+	assert.NoError(t, service.Refresh(context.Background(), &project.RefreshOptions{Patterns: []string{"a"}}))
+
+	status, err := service.RefreshStatus()
+	assert.NoError(t, err)
+	if _, ok := status.Errors["b/*"]; !ok || len(status.Errors) != 1 {
+		t.Fatalf("expected the error of b/* to be kept, got %+v", status.Errors)
+	}
+
+	// A successful refresh clears the error.
+	mu.Lock()
+	failing["b"] = false
+	mu.Unlock()
+	assert.NoError(t, service.Refresh(context.Background(), &project.RefreshOptions{Patterns: []string{"b"}}))
+
+	status, err = service.RefreshStatus()
+	assert.NoError(t, err)
+	if len(status.Errors) != 0 {
+		t.Fatalf("expected no errors, got %+v", status.Errors)
+	}
+}
@@ -0,0 +1,92 @@
+package project
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// parseRemoteURL splits a git remote URL into its host and repository path.
+// The following formats are supported:
+//
+//	https://host/owner/repo(.git)
+//	ssh://[user@]host[:port]/owner/repo(.git)
+//	[user@]host:owner/repo(.git)
+func parseRemoteURL(raw string) (string, string, error) {
+	raw = strings.TrimSpace(raw)
+
+	var host, repoPath string
+	switch {
+	case strings.Contains(raw, "://"):
+		u, err := url.Parse(raw)
+		if err != nil {
+			return "", "", fmt.Errorf("invalid remote URL %q: %w", raw, err)
+		}
+		host = u.Hostname()
+		repoPath = u.Path
+	case isSCPLike(raw):
+		i := strings.Index(raw, ":")
+		host = raw[:i]
+		if at := strings.LastIndex(host, "@"); at >= 0 {
+			host = host[at+1:]
+		}
+		repoPath = raw[i+1:]
+	default:
+		return "", "", fmt.Errorf("invalid remote URL: %q", raw)
+	}
+
+	repoPath = strings.TrimSuffix(strings.Trim(repoPath, "/"), ".git")
+	if host == "" || !strings.Contains(repoPath, "/") {
+		return "", "", fmt.Errorf("invalid remote URL: %q", raw)
+	}
+
+	return strings.ToLower(host), repoPath, nil
+}
+
+// isSCPLike reports whether the URL is in the scp-like syntax used by ssh
+// remotes, e.g. git@github.com:owner/repo.git.
+func isSCPLike(raw string) bool {
+	i := strings.Index(raw, ":")
+	if i <= 0 {
+		return false
+	}
+
+	// A slash before the colon means it's a local path, not a host.
+	return !strings.Contains(raw[:i], "/")
+}
+
+// sameRemote reports whether both URLs point to the same repository,
+// regardless of the protocol. Local paths and file:// URLs are compared by
+// path.
+func sameRemote(a, b string) bool {
+	aHost, aPath, aErr := parseRemoteURL(a)
+	bHost, bPath, bErr := parseRemoteURL(b)
+	if aErr == nil && bErr == nil {
+		return aHost == bHost && strings.EqualFold(aPath, bPath)
+	}
+
+	aPath, aOK := localRemotePath(a)
+	bPath, bOK := localRemotePath(b)
+
+	return aOK && bOK && aPath == bPath
+}
+
+// localRemotePath returns the cleaned path of a remote on the local
+// filesystem, given as a path or a file:// URL.
+func localRemotePath(raw string) (string, bool) {
+	raw = strings.TrimSpace(raw)
+	if strings.HasPrefix(raw, "file://") {
+		u, err := url.Parse(raw)
+		if err != nil {
+			return "", false
+		}
+		raw = u.Path
+	}
+
+	if !path.IsAbs(raw) {
+		return "", false
+	}
+
+	return strings.TrimSuffix(path.Clean(raw), ".git"), true
+}
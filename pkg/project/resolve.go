@@ -0,0 +1,257 @@
+package project
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/zkhvan/z/pkg/oslib"
+)
+
+var (
+	ErrInvalidID   = errors.New("invalid project ID")
+	ErrAmbiguousID = errors.New("ambiguous project ID")
+)
+
+type IDKind int
+
+const (
+	IDKindUnknown IDKind = iota
+	// IDKindRemote is a remote repository, e.g. "owner/repo",
+	// "host/owner/repo" or a URL.
+	IDKindRemote
+	// IDKindLocal is a path relative to the projects root, e.g.
+	// "personal/owner/repo".
+	IDKindLocal
+	// IDKindPath is an absolute or relative filesystem path, e.g.
+	// "./repo" or "~/Projects/owner/repo".
+	IDKindPath
+)
+
+func (k IDKind) String() string {
+	switch k {
+	case IDKindRemote:
+		return "remote"
+	case IDKindLocal:
+		return "local"
+	case IDKindPath:
+		return "path"
+	default:
+		return "unknown"
+	}
+}
+
+// ID is a parsed project identifier.
+type ID struct {
+	Kind IDKind
+
+	// Host is the host of a remote ID, if one was given.
+	Host string
+
+	// RemoteID is the owner/repo of a remote ID. The owner can contain
+	// slashes for services with nested groups.
+	RemoteID string
+
+	// Path is the local ID of a local ID, or the path of a path ID.
+	Path string
+}
+
+// ParseID parses a project identifier without looking at the configuration
+// or the filesystem. The following formats are supported:
+//
+//	owner/repo
+//	host/owner/repo
+//	https://host/owner/repo(.git)
+//	ssh://[user@]host[:port]/owner/repo(.git)
+//	[user@]host:owner/repo(.git)
+//	file:///path/to/repo
+//	/path/to/repo, ./repo, ../repo, ~/repo
+//	dir/owner/repo (relative to the projects root)
+//
+// A path relative to the projects root is only recognized if it has at least
+// three segments and the first one doesn't look like a host, otherwise it's
+// parsed as a remote ID.
+func ParseID(input string) (ID, error) {
+	input = strings.TrimSpace(input)
+	if input == "" {
+		return ID{}, fmt.Errorf("%w: empty", ErrInvalidID)
+	}
+
+	if isPath(input) {
+		return ID{Kind: IDKindPath, Path: input}, nil
+	}
+
+	if strings.HasPrefix(input, "file://") {
+		u, err := url.Parse(input)
+		if err != nil {
+			return ID{}, fmt.Errorf("%w %q: %w", ErrInvalidID, input, err)
+		}
+		return ID{Kind: IDKindPath, Path: u.Path}, nil
+	}
+
+	if strings.Contains(input, "://") || isSCPLike(input) {
+		host, repoPath, err := parseRemoteURL(input)
+		if err != nil {
+			return ID{}, fmt.Errorf("%w %q: expected a URL like https://host/owner/repo", ErrInvalidID, input)
+		}
+		return ID{Kind: IDKindRemote, Host: host, RemoteID: repoPath}, nil
+	}
+
+	trimmed := strings.TrimSuffix(strings.Trim(input, "/"), ".git")
+	parts := strings.Split(trimmed, "/")
+	for _, part := range parts {
+		if part == "" || part == "." || part == ".." {
+			return ID{}, fmt.Errorf("%w %q: empty or relative path segment", ErrInvalidID, input)
+		}
+	}
+
+	switch n := len(parts); {
+	case n < 2:
+		return ID{}, fmt.Errorf(
+			"%w %q: expected owner/repo, host/owner/repo, a URL, a path or an alias",
+			ErrInvalidID, input,
+		)
+	case n == 2:
+		return ID{Kind: IDKindRemote, RemoteID: trimmed}, nil
+	case looksLikeHost(parts[0]):
+		return ID{
+			Kind:     IDKindRemote,
+			Host:     strings.ToLower(parts[0]),
+			RemoteID: strings.Join(parts[1:], "/"),
+		}, nil
+	default:
+		return ID{Kind: IDKindLocal, Path: filepath.FromSlash(trimmed)}, nil
+	}
+}
+
+// Resolve returns the project identified by input. Besides the formats
+// supported by ParseID, input can be one of the aliases defined in the
+// config.
+//
+// Local paths must be inside the projects root. An input like
+// "host.com/owner/repo" that is both a remote ID and an existing local
+// project is rejected with ErrAmbiguousID.
+func (s *Service) Resolve(ctx context.Context, input string) (Project, error) {
+	if target, ok := s.cfg.Aliases[strings.TrimSpace(input)]; ok {
+		p, err := s.resolve(ctx, target)
+		if err != nil {
+			return p, fmt.Errorf("error resolving alias %q: %w", input, err)
+		}
+		return p, nil
+	}
+
+	return s.resolve(ctx, input)
+}
+
+func (s *Service) resolve(ctx context.Context, input string) (Project, error) {
+	id, err := ParseID(input)
+	if err != nil {
+		return Project{}, err
+	}
+
+	// Providers can be named after hosts that don't look like one.
+	if id.Kind == IDKindLocal {
+		host, remoteID, _ := strings.Cut(filepath.ToSlash(id.Path), "/")
+		if s.cfg.isHost(host) {
+			id = ID{Kind: IDKindRemote, Host: normalizeHost(host), RemoteID: remoteID}
+		}
+	}
+
+	switch id.Kind {
+	case IDKindRemote:
+		p := s.remoteProject(id.Host, id.RemoteID)
+
+		if id.Host != "" && !strings.Contains(input, ":") {
+			// host/owner/repo could also be a local ID.
+			localID := filepath.FromSlash(strings.Trim(input, "/"))
+			if isDir(filepath.Join(s.cfg.Root, localID)) && localID != p.LocalID {
+				return Project{}, fmt.Errorf(
+					"%w %q: it matches the remote %s/%s and the local project %s, use a URL or ./%s",
+					ErrAmbiguousID, input, id.Host, id.RemoteID, localID, localID,
+				)
+			}
+		}
+
+		return p, nil
+	case IDKindLocal:
+		return s.localProject(ctx, id.Path), nil
+	case IDKindPath:
+		abs, err := filepath.Abs(oslib.Expand(id.Path))
+		if err != nil {
+			return Project{}, fmt.Errorf("%w %q: %w", ErrInvalidID, input, err)
+		}
+
+		rel, err := filepath.Rel(s.cfg.Root, abs)
+		if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+			return Project{}, fmt.Errorf("%w %q: path is not inside the projects root %s", ErrInvalidID, input, s.cfg.Root)
+		}
+
+		return s.localProject(ctx, rel), nil
+	default:
+		return Project{}, fmt.Errorf("%w: %q", ErrInvalidID, input)
+	}
+}
+
+func (s *Service) remoteProject(host, remoteID string) Project {
+	p := newProject(
+		s.toLocalID(host, remoteID),
+		remoteID,
+		"",
+	)
+	p.Host = projectHost(host)
+	p.AbsolutePath = filepath.Join(s.cfg.Root, p.LocalID)
+
+	return p
+}
+
+// localProject returns the project for a local ID. If the project is a Git
+// repository, its remote is taken from "origin".
+func (s *Service) localProject(ctx context.Context, localID string) Project {
+	p := newProject(
+		localID,
+		s.toRemoteID(localID),
+		filepath.Join(s.cfg.Root, localID),
+	)
+
+	if !isDir(filepath.Join(p.AbsolutePath, ".git")) {
+		return p
+	}
+
+	remoteURL, err := s.git.RemoteURL(ctx, p.AbsolutePath, "")
+	if err != nil {
+		return p
+	}
+
+	host, repoPath, err := parseRemoteURL(remoteURL)
+	if err != nil {
+		return p
+	}
+
+	p.RemoteID = repoPath
+	p.Host = projectHost(host)
+
+	return p
+}
+
+// isPath reports whether the input is unambiguously a filesystem path.
+func isPath(input string) bool {
+	return input == "." || input == ".." || input == "~" ||
+		strings.HasPrefix(input, "/") ||
+		strings.HasPrefix(input, "./") ||
+		strings.HasPrefix(input, "../") ||
+		strings.HasPrefix(input, "~/")
+}
+
+func looksLikeHost(segment string) bool {
+	return segment == "localhost" || strings.Contains(path.Base(segment), ".")
+}
+
+func isDir(p string) bool {
+	info, err := os.Stat(p)
+	return err == nil && info.IsDir()
+}
@@ -0,0 +1,218 @@
+package project_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/MakeNowJust/heredoc/v2"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/zkhvan/z/pkg/assert"
+	"github.com/zkhvan/z/pkg/project"
+)
+
+func TestParseID(t *testing.T) {
+	tests := map[string]struct {
+		input string
+		want  project.ID
+		err   error
+	}{
+		"owner/repo": {
+			input: "cli/cli",
+			want:  project.ID{Kind: project.IDKindRemote, RemoteID: "cli/cli"},
+		},
+		"host/owner/repo": {
+			input: "ghe.corp.com/platform/api",
+			want:  project.ID{Kind: project.IDKindRemote, Host: "ghe.corp.com", RemoteID: "platform/api"},
+		},
+		"host with nested groups": {
+			input: "gitlab.com/group/subgroup/repo",
+			want:  project.ID{Kind: project.IDKindRemote, Host: "gitlab.com", RemoteID: "group/subgroup/repo"},
+		},
+		"https URL": {
+			input: "https://github.com/cli/cli",
+			want:  project.ID{Kind: project.IDKindRemote, Host: "github.com", RemoteID: "cli/cli"},
+		},
+		"https URL with .git suffix": {
+			input: "https://github.com/cli/cli.git",
+			want:  project.ID{Kind: project.IDKindRemote, Host: "github.com", RemoteID: "cli/cli"},
+		},
+		"ssh URL": {
+			input: "ssh://git@github.com:22/cli/cli.git",
+			want:  project.ID{Kind: project.IDKindRemote, Host: "github.com", RemoteID: "cli/cli"},
+		},
+		"scp URL": {
+			input: "git@github.com:cli/cli.git",
+			want:  project.ID{Kind: project.IDKindRemote, Host: "github.com", RemoteID: "cli/cli"},
+		},
+		"local ID": {
+			input: "oss/cli/cli",
+			want:  project.ID{Kind: project.IDKindLocal, Path: filepath.Join("oss", "cli", "cli")},
+		},
+		"absolute path": {
+			input: "/tmp/repo",
+			want:  project.ID{Kind: project.IDKindPath, Path: "/tmp/repo"},
+		},
+		"relative path": {
+			input: "./repo",
+			want:  project.ID{Kind: project.IDKindPath, Path: "./repo"},
+		},
+		"home path": {
+			input: "~/Projects/cli/cli",
+			want:  project.ID{Kind: project.IDKindPath, Path: "~/Projects/cli/cli"},
+		},
+		"file URL": {
+			input: "file:///srv/git/repo.git",
+			want:  project.ID{Kind: project.IDKindPath, Path: "/srv/git/repo.git"},
+		},
+		"empty": {
+			input: " ",
+			err:   project.ErrInvalidID,
+		},
+		"single segment": {
+			input: "cli",
+			err:   project.ErrInvalidID,
+		},
+		"empty segment": {
+			input: "cli//cli",
+			err:   project.ErrInvalidID,
+		},
+		"URL without repo": {
+			input: "https://github.com/cli",
+			err:   project.ErrInvalidID,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := project.ParseID(test.input)
+			if test.err != nil {
+				if !errors.Is(err, test.err) {
+					t.Fatalf("expected error %v, got %v", test.err, err)
+				}
+				return
+			}
+			assert.NoError(t, err)
+
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Fatalf("ID mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestResolve(t *testing.T) {
+	tests := map[string]struct {
+		input string
+		local []string
+		want  project.Project
+		err   error
+	}{
+		"owner/repo should use the remote patterns": {
+			input: "my-org/repo",
+			want: project.Project{
+				LocalID:      filepath.Join("work", "my-org", "repo"),
+				RemoteID:     "my-org/repo",
+				AbsolutePath: filepath.Join("$PROJECTSDIR", "work", "my-org", "repo"),
+			},
+		},
+		"URL should preserve the host": {
+			input: "git@ghe.corp.com:platform/api.git",
+			want: project.Project{
+				LocalID:      filepath.Join("platform", "api"),
+				RemoteID:     "platform/api",
+				Host:         "ghe.corp.com",
+				AbsolutePath: filepath.Join("$PROJECTSDIR", "platform", "api"),
+			},
+		},
+		"default host should be omitted": {
+			input: "https://github.com/cli/cli",
+			want: project.Project{
+				LocalID:      filepath.Join("cli", "cli"),
+				RemoteID:     "cli/cli",
+				AbsolutePath: filepath.Join("$PROJECTSDIR", "cli", "cli"),
+			},
+		},
+		"alternate path with the deprecated trailing slash should leave the owner out": {
+			input: "other/repo",
+			want: project.Project{
+				LocalID:      filepath.Join("oss", "repo"),
+				RemoteID:     "other/repo",
+				AbsolutePath: filepath.Join("$PROJECTSDIR", "oss", "repo"),
+			},
+		},
+		"alternate path ending with /* should leave the owner out": {
+			input: "acme/tool",
+			want: project.Project{
+				LocalID:      filepath.Join("tools", "tool"),
+				RemoteID:     "acme/tool",
+				AbsolutePath: filepath.Join("$PROJECTSDIR", "tools", "tool"),
+			},
+		},
+		"alias should resolve to its target": {
+			input: "z",
+			want: project.Project{
+				LocalID:      filepath.Join("zkhvan", "z"),
+				RemoteID:     "zkhvan/z",
+				AbsolutePath: filepath.Join("$PROJECTSDIR", "zkhvan", "z"),
+			},
+		},
+		"path inside the root should be a local project": {
+			input: "$PROJECTSDIR/oss/cli/cli",
+			want: project.Project{
+				LocalID:      filepath.Join("oss", "cli", "cli"),
+				RemoteID:     "cli/cli",
+				AbsolutePath: filepath.Join("$PROJECTSDIR", "oss", "cli", "cli"),
+			},
+		},
+		"path outside the root should fail": {
+			input: "/somewhere/else",
+			err:   project.ErrInvalidID,
+		},
+		"host/owner/repo that exists locally should be ambiguous": {
+			input: "example.com/owner/repo",
+			local: []string{"example.com/owner/repo"},
+			err:   project.ErrAmbiguousID,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			td := setupTestDir(t)
+			cfg := setupConfig(t, td, heredoc.Doc(`
+				projects:
+				  root: $PROJECTSDIR
+				  remote_patterns:
+				    - my-org/* -> ./work
+				    - other/repo -> ./oss/
+				    - acme/tool -> ./tools/*
+				  aliases:
+				    z: zkhvan/z
+			`))
+
+			for _, dir := range test.local {
+				assert.NoError(t, os.MkdirAll(filepath.Join(td.projects, dir), 0o700))
+			}
+
+			service, err := project.NewService(cfg, project.WithCacheDir(td.cache))
+			assert.NoError(t, err)
+
+			got, err := service.Resolve(context.Background(), replaceTestDirs(test.input, td))
+			if test.err != nil {
+				if !errors.Is(err, test.err) {
+					t.Fatalf("expected error %v, got %v", test.err, err)
+				}
+				return
+			}
+			assert.NoError(t, err)
+
+			test.want.AbsolutePath = replaceTestDirs(test.want.AbsolutePath, td)
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Fatalf("project mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -2,11 +2,14 @@ package project
 
 import (
 	"fmt"
+	"io"
+	"path/filepath"
 
 	"github.com/zkhvan/z/pkg/cmdutil"
 	"github.com/zkhvan/z/pkg/exec"
 	"github.com/zkhvan/z/pkg/fcache"
 	"github.com/zkhvan/z/pkg/gh"
+	"github.com/zkhvan/z/pkg/git"
 )
 
 var defaultExecutor exec.Interface = exec.New()
@@ -15,9 +18,26 @@ type Service struct {
 	cfg      Config
 	executor exec.Interface
 	gh       *gh.Client
+	git      *git.Client
+
+	// providers maps hosts to the provider serving them.
+	providers map[string]Provider
 
 	refreshCache bool
+	rescan       bool
 	cacheDir     string
+	offline      bool
+
+	// daemonSocket is the socket of the daemon to list the projects with,
+	// when it's running.
+	daemonSocket string
+
+	// backgroundRefresh starts a refresh of the cache in the background.
+	backgroundRefresh func() error
+
+	// warnings receives the warnings, e.g. about patterns that failed to
+	// load.
+	warnings io.Writer
 }
 
 type ServiceOption func(*Service)
@@ -26,6 +46,7 @@ func WithExecutor(executor exec.Interface) ServiceOption {
 	return func(s *Service) {
 		s.executor = executor
 		s.gh.SetExecutor(executor)
+		s.git.SetExecutor(executor)
 	}
 }
 
@@ -35,12 +56,36 @@ func WithGHClient(client *gh.Client) ServiceOption {
 	}
 }
 
+// WithWarnings sets where the warnings are written, which are discarded by
+// default.
+func WithWarnings(w io.Writer) ServiceOption {
+	return func(s *Service) {
+		s.warnings = w
+	}
+}
+
 func WithRefreshCache(refreshCache bool) ServiceOption {
 	return func(s *Service) {
 		s.refreshCache = refreshCache
 	}
 }
 
+// WithRescan walks the whole projects root to find the local projects,
+// instead of only the directories which changed since the last walk.
+func WithRescan(rescan bool) ServiceOption {
+	return func(s *Service) {
+		s.rescan = rescan
+	}
+}
+
+// WithOffline serves the remote projects from the cache, even if expired,
+// without calling the providers. The offline config enables it too.
+func WithOffline(offline bool) ServiceOption {
+	return func(s *Service) {
+		s.offline = offline
+	}
+}
+
 func WithCacheDir(cacheDir string) ServiceOption {
 	return func(s *Service) {
 		s.cacheDir = fcache.NormalizeCacheDir(cacheDir)
@@ -57,11 +102,40 @@ func NewService(config cmdutil.Config, opts ...ServiceOption) (*Service, error)
 		cfg:      cfg,
 		executor: defaultExecutor,
 		gh:       gh.NewClient(),
+		git:      git.NewClient(),
+		warnings: io.Discard,
+
+		providers: make(map[string]Provider),
 	}
 
 	for _, opt := range opts {
 		opt(s)
 	}
 
+	s.offline = s.offline || cfg.Offline
+
+	if s.cacheDir != "" {
+		s.gh.SetResponseCache(gh.NewFileCache(filepath.Join(s.cacheDir, "gh")))
+	}
+
+	if err := s.initProviders(); err != nil {
+		return nil, err
+	}
+
 	return s, nil
 }
+
+// Offline reports whether the providers are never called.
+func (s *Service) Offline() bool {
+	return s.offline
+}
+
+// PreviewWindow returns the layout of the project preview in the fuzzy
+// finder.
+func (s *Service) PreviewWindow() string {
+	return s.cfg.PreviewWindow
+}
+
+func (s *Service) warnf(format string, args ...any) {
+	fmt.Fprintf(s.warnings, "warning: "+format+"\n", args...)
+}
@@ -0,0 +1,124 @@
+package prompt
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/zkhvan/z/pkg/iolib"
+)
+
+var ErrNoInput = errors.New("no input")
+
+// Prompter asks the user simple questions. Questions are written to ErrOut,
+// so that they don't interfere with output captured by the shell integration.
+type Prompter struct {
+	in  *bufio.Reader
+	out io.Writer
+}
+
+func New(streams *iolib.IOStreams) *Prompter {
+	return &Prompter{
+		in:  bufio.NewReader(streams.In),
+		out: streams.ErrOut,
+	}
+}
+
+// Input asks for a free-form answer. An empty answer returns def.
+func (p *Prompter) Input(message, def string) (string, error) {
+	if def != "" {
+		fmt.Fprintf(p.out, "? %s (%s) ", message, def)
+	} else {
+		fmt.Fprintf(p.out, "? %s ", message)
+	}
+
+	answer, err := p.readLine()
+	if err != nil {
+		return "", err
+	}
+
+	if answer == "" {
+		return def, nil
+	}
+
+	return answer, nil
+}
+
+// Confirm asks a yes/no question. An empty answer returns def.
+func (p *Prompter) Confirm(message string, def bool) (bool, error) {
+	hint := "y/N"
+	if def {
+		hint = "Y/n"
+	}
+
+	for {
+		fmt.Fprintf(p.out, "? %s [%s] ", message, hint)
+
+		answer, err := p.readLine()
+		if err != nil {
+			return false, err
+		}
+
+		switch strings.ToLower(answer) {
+		case "":
+			return def, nil
+		case "y", "yes":
+			return true, nil
+		case "n", "no":
+			return false, nil
+		}
+
+		fmt.Fprintln(p.out, "Please answer yes or no.")
+	}
+}
+
+// Select asks the user to pick one of the options and returns its index. An
+// empty answer returns def.
+func (p *Prompter) Select(message string, options []string, def int) (int, error) {
+	if len(options) == 0 {
+		return 0, errors.New("no options to select from")
+	}
+
+	fmt.Fprintf(p.out, "? %s\n", message)
+	for i, option := range options {
+		fmt.Fprintf(p.out, "  %d) %s\n", i+1, option)
+	}
+
+	for {
+		fmt.Fprintf(p.out, "Choose an option (%d) ", def+1)
+
+		answer, err := p.readLine()
+		if err != nil {
+			return 0, err
+		}
+
+		if answer == "" {
+			return def, nil
+		}
+
+		n, err := strconv.Atoi(answer)
+		if err == nil && 1 <= n && n <= len(options) {
+			return n - 1, nil
+		}
+
+		fmt.Fprintf(p.out, "Please enter a number between 1 and %d.\n", len(options))
+	}
+}
+
+func (p *Prompter) readLine() (string, error) {
+	line, err := p.in.ReadString('\n')
+	if err != nil {
+		if errors.Is(err, io.EOF) && line != "" {
+			return strings.TrimSpace(line), nil
+		}
+		if errors.Is(err, io.EOF) {
+			return "", ErrNoInput
+		}
+		return "", err
+	}
+
+	return strings.TrimSpace(line), nil
+}